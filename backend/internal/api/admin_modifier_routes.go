@@ -0,0 +1,580 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetupAdminModifierRoutes configures modifier group management for admin/manager roles
+func SetupAdminModifierRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/modifier-groups", getModifierGroups(db))
+		admin.POST("/modifier-groups", createModifierGroup(db))
+		admin.PUT("/modifier-groups/:id", updateModifierGroup(db))
+		admin.DELETE("/modifier-groups/:id", deleteModifierGroup(db))
+		admin.POST("/modifier-groups/:id/modifiers", createModifier(db))
+		admin.PUT("/modifiers/:id", updateModifier(db))
+		admin.DELETE("/modifiers/:id", deleteModifier(db))
+		admin.PUT("/products/:id/modifier-groups", setModifierGroupLinks(db, "product_modifier_groups", "product_id"))
+		admin.PUT("/categories/:id/modifier-groups", setModifierGroupLinks(db, "category_modifier_groups", "category_id"))
+	}
+}
+
+// Admin handler - List modifier groups with their modifiers
+func getModifierGroups(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := db.Query(`
+			SELECT id, name, description, min_select, max_select, sort_order, is_active, created_at, updated_at
+			FROM modifier_groups
+			ORDER BY sort_order ASC, name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch modifier groups",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		groups := []models.ModifierGroup{}
+		index := map[string]int{}
+		for rows.Next() {
+			var group models.ModifierGroup
+			if err := rows.Scan(&group.ID, &group.Name, &group.Description, &group.MinSelect, &group.MaxSelect,
+				&group.SortOrder, &group.IsActive, &group.CreatedAt, &group.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan modifier group",
+					"error":   err.Error(),
+				})
+				return
+			}
+			group.Modifiers = []models.Modifier{}
+			index[group.ID.String()] = len(groups)
+			groups = append(groups, group)
+		}
+
+		modifierRows, err := db.Query(`
+			SELECT id, modifier_group_id, name, price_delta, is_available, sort_order, created_at, updated_at
+			FROM modifiers
+			ORDER BY sort_order ASC, name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch modifiers",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer modifierRows.Close()
+
+		for modifierRows.Next() {
+			var modifier models.Modifier
+			if err := modifierRows.Scan(&modifier.ID, &modifier.ModifierGroupID, &modifier.Name, &modifier.PriceDelta,
+				&modifier.IsAvailable, &modifier.SortOrder, &modifier.CreatedAt, &modifier.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan modifier",
+					"error":   err.Error(),
+				})
+				return
+			}
+			if i, ok := index[modifier.ModifierGroupID.String()]; ok {
+				groups[i].Modifiers = append(groups[i].Modifiers, modifier)
+			}
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier groups retrieved successfully",
+			"data":    groups,
+		})
+	}
+}
+
+// Admin handler - Create modifier group
+func createModifierGroup(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name        string  `json:"name" binding:"required"`
+			Description *string `json:"description"`
+			MinSelect   int     `json:"min_select"`
+			MaxSelect   *int    `json:"max_select"` // omit for no limit
+			SortOrder   int     `json:"sort_order"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if req.MinSelect < 0 || (req.MaxSelect != nil && *req.MaxSelect < req.MinSelect) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "min_select must be zero or more and max_select cannot be below min_select",
+				"error":   "invalid_selection_limits",
+			})
+			return
+		}
+
+		var groupID string
+		err := db.QueryRow(`
+			INSERT INTO modifier_groups (name, description, min_select, max_select, sort_order)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, req.Name, req.Description, req.MinSelect, req.MaxSelect, req.SortOrder).Scan(&groupID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create modifier group",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Modifier group created successfully",
+			"data":    map[string]interface{}{"id": groupID},
+		})
+	}
+}
+
+// Admin handler - Update modifier group
+func updateModifierGroup(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("id")
+
+		var req struct {
+			Name        *string `json:"name"`
+			Description *string `json:"description"`
+			MinSelect   *int    `json:"min_select"`
+			MaxSelect   *int    `json:"max_select"` // 0 removes the limit
+			SortOrder   *int    `json:"sort_order"`
+			IsActive    *bool   `json:"is_active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.Description != nil {
+			updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+			args = append(args, req.Description)
+			argCount++
+		}
+		if req.MinSelect != nil {
+			updates = append(updates, fmt.Sprintf("min_select = $%d", argCount))
+			args = append(args, *req.MinSelect)
+			argCount++
+		}
+		if req.MaxSelect != nil {
+			if *req.MaxSelect == 0 {
+				updates = append(updates, "max_select = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("max_select = $%d", argCount))
+				args = append(args, *req.MaxSelect)
+				argCount++
+			}
+		}
+		if req.SortOrder != nil {
+			updates = append(updates, fmt.Sprintf("sort_order = $%d", argCount))
+			args = append(args, *req.SortOrder)
+			argCount++
+		}
+		if req.IsActive != nil {
+			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+			args = append(args, *req.IsActive)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, groupID)
+
+		query := fmt.Sprintf(`
+			UPDATE modifier_groups
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			// check_violation: min_select/max_select no longer consistent
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23514" {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "min_select must be zero or more and max_select cannot be below min_select",
+					"error":   "invalid_selection_limits",
+				})
+				return
+			}
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update modifier group",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Modifier group not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier group updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete modifier group
+func deleteModifierGroup(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("id")
+
+		// Order items keep their modifier snapshot through ON DELETE SET NULL
+		result, err := db.Exec("DELETE FROM modifier_groups WHERE id = $1", groupID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete modifier group",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Modifier group not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier group deleted successfully",
+		})
+	}
+}
+
+// Admin handler - Create modifier in a group
+func createModifier(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("id")
+
+		var req struct {
+			Name       string  `json:"name" binding:"required"`
+			PriceDelta float64 `json:"price_delta"`
+			SortOrder  int     `json:"sort_order"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		var exists bool
+		db.QueryRow("SELECT EXISTS(SELECT 1 FROM modifier_groups WHERE id = $1)", groupID).Scan(&exists)
+		if !exists {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Modifier group not found",
+			})
+			return
+		}
+
+		var modifierID string
+		err := db.QueryRow(`
+			INSERT INTO modifiers (modifier_group_id, name, price_delta, sort_order)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, groupID, req.Name, req.PriceDelta, req.SortOrder).Scan(&modifierID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create modifier",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Modifier created successfully",
+			"data":    map[string]interface{}{"id": modifierID},
+		})
+	}
+}
+
+// Admin handler - Update modifier
+func updateModifier(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modifierID := c.Param("id")
+
+		var req struct {
+			Name        *string  `json:"name"`
+			PriceDelta  *float64 `json:"price_delta"`
+			IsAvailable *bool    `json:"is_available"`
+			SortOrder   *int     `json:"sort_order"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.PriceDelta != nil {
+			updates = append(updates, fmt.Sprintf("price_delta = $%d", argCount))
+			args = append(args, *req.PriceDelta)
+			argCount++
+		}
+		if req.IsAvailable != nil {
+			updates = append(updates, fmt.Sprintf("is_available = $%d", argCount))
+			args = append(args, *req.IsAvailable)
+			argCount++
+		}
+		if req.SortOrder != nil {
+			updates = append(updates, fmt.Sprintf("sort_order = $%d", argCount))
+			args = append(args, *req.SortOrder)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, modifierID)
+
+		query := fmt.Sprintf(`
+			UPDATE modifiers
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update modifier",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Modifier not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete modifier
+func deleteModifier(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modifierID := c.Param("id")
+
+		result, err := db.Exec("DELETE FROM modifiers WHERE id = $1", modifierID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete modifier",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Modifier not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier deleted successfully",
+		})
+	}
+}
+
+// Admin handler - Replace the modifier groups linked to a product or category.
+// The order of modifier_group_ids becomes the display order.
+func setModifierGroupLinks(db *sql.DB, table, ownerColumn string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ownerID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid ID",
+				"error":   "invalid_uuid",
+			})
+			return
+		}
+
+		var req struct {
+			ModifierGroupIDs []uuid.UUID `json:"modifier_group_ids"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		ownerTable := "products"
+		if ownerColumn == "category_id" {
+			ownerTable = "categories"
+		}
+		var exists bool
+		db.QueryRow("SELECT EXISTS(SELECT 1 FROM "+ownerTable+" WHERE id = $1)", ownerID).Scan(&exists)
+		if !exists {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Not found",
+			})
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to start transaction",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE "+ownerColumn+" = $1", ownerID); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update modifier groups",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		for i, groupID := range req.ModifierGroupIDs {
+			if _, err := tx.Exec(
+				"INSERT INTO "+table+" ("+ownerColumn+", modifier_group_id, sort_order) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+				ownerID, groupID, i+1,
+			); err != nil {
+				// foreign_key_violation: unknown modifier group
+				if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+					c.JSON(400, gin.H{
+						"success": false,
+						"message": "Modifier group not found: " + groupID.String(),
+						"error":   "modifier_group_not_found",
+					})
+					return
+				}
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to update modifier groups",
+					"error":   err.Error(),
+				})
+				return
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update modifier groups",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Modifier groups updated successfully",
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/printing"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminPrinterRoutes configures printer management, the print spool and order reprints
+func SetupAdminPrinterRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	printJobRepo := repository.NewPostgresPrintJobRepository(db)
+	printQueue := printing.NewQueue(printJobRepo, repository.NewPostgresOrderRepository(db))
+	printHandler := handlers.NewPrintHandler(printJobRepo, printQueue)
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/printers", getPrinters(db))
+		admin.POST("/printers", createPrinter(db))
+		admin.PUT("/printers/:id", updatePrinter(db))
+		admin.DELETE("/printers/:id", deletePrinter(db))
+		admin.POST("/printers/:id/test", printHandler.PrintTestPage)
+
+		admin.GET("/print-jobs", printHandler.GetPrintJobs)
+		admin.POST("/print-jobs/:id/retry", printHandler.RetryPrintJob)
+	}
+
+	// Reprints from the floor
+	protected := router.Group("/")
+	protected.Use(authMiddleware)
+	protected.Use(middleware.RequireRoles([]string{"admin", "manager", "server", "counter"}))
+	{
+		protected.POST("/orders/:id/print/kitchen", printHandler.PrintKitchenTickets)
+		protected.POST("/orders/:id/print/receipt", printHandler.PrintReceipt)
+	}
+}
+
+func validPrinterType(printerType string) bool {
+	return printerType == printing.PrinterKitchen || printerType == printing.PrinterReceipt
+}
+
+func validConnectionType(connectionType string) bool {
+	switch connectionType {
+	case printing.ConnectionNetwork, printing.ConnectionFile, printing.ConnectionLoopback:
+		return true
+	}
+	return false
+}
+
+// getPrinters lists configured printers with their station
+func getPrinters(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := db.Query(`
+			SELECT pr.id, pr.name, pr.printer_type, pr.station_id, s.name, pr.connection_type, pr.address,
+			       pr.chars_per_line, pr.is_active, pr.created_at, pr.updated_at
+			FROM printers pr
+			LEFT JOIN kitchen_stations s ON pr.station_id = s.id
+			ORDER BY pr.printer_type ASC, pr.name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch printers",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		printers := []models.Printer{}
+		for rows.Next() {
+			var printer models.Printer
+			if err := rows.Scan(&printer.ID, &printer.Name, &printer.PrinterType, &printer.StationID, &printer.StationName,
+				&printer.ConnectionType, &printer.Address, &printer.CharsPerLine, &printer.IsActive,
+				&printer.CreatedAt, &printer.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan printer",
+					"error":   err.Error(),
+				})
+				return
+			}
+			printers = append(printers, printer)
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Printers retrieved successfully",
+			"data":    printers,
+		})
+	}
+}
+
+// Admin handler - Create printer
+func createPrinter(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name           string  `json:"name" binding:"required"`
+			PrinterType    string  `json:"printer_type" binding:"required"`
+			StationID      *string `json:"station_id"`
+			ConnectionType string  `json:"connection_type"`
+			Address        string  `json:"address"`
+			CharsPerLine   int     `json:"chars_per_line"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if req.ConnectionType == "" {
+			req.ConnectionType = printing.ConnectionNetwork
+		}
+		if req.CharsPerLine == 0 {
+			req.CharsPerLine = 42
+		}
+		if !validPrinterType(req.PrinterType) || !validConnectionType(req.ConnectionType) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid printer type or connection type",
+				"error":   "invalid_printer",
+			})
+			return
+		}
+		if req.ConnectionType != printing.ConnectionLoopback && req.Address == "" {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Network and file printers need an address",
+				"error":   "address_required",
+			})
+			return
+		}
+		if req.ConnectionType == printing.ConnectionFile {
+			if _, err := printing.FilePath(req.Address); err != nil {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "File printers need a path inside the spool directory",
+					"error":   "invalid_address",
+				})
+				return
+			}
+		}
+		if req.StationID != nil && req.PrinterType != printing.PrinterKitchen {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Only kitchen printers can be assigned to a station",
+				"error":   "invalid_printer",
+			})
+			return
+		}
+
+		var printerID string
+		err := db.QueryRow(`
+			INSERT INTO printers (name, printer_type, station_id, connection_type, address, chars_per_line)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, req.Name, req.PrinterType, req.StationID, req.ConnectionType, req.Address, req.CharsPerLine).Scan(&printerID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create printer",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Printer created successfully",
+			"data":    map[string]interface{}{"id": printerID},
+		})
+	}
+}
+
+// Admin handler - Update printer
+func updatePrinter(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		printerID := c.Param("id")
+
+		var req struct {
+			Name           *string `json:"name"`
+			StationID      *string `json:"station_id"` // empty string makes it print every station
+			ConnectionType *string `json:"connection_type"`
+			Address        *string `json:"address"`
+			CharsPerLine   *int    `json:"chars_per_line"`
+			IsActive       *bool   `json:"is_active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if req.ConnectionType != nil && !validConnectionType(*req.ConnectionType) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid connection type",
+				"error":   "invalid_printer",
+			})
+			return
+		}
+
+		// A file printer's path must stay inside the spool directory, whichever of the two changes
+		if req.ConnectionType != nil || req.Address != nil {
+			var connectionType, address string
+			err := db.QueryRow("SELECT connection_type, address FROM printers WHERE id = $1", printerID).Scan(&connectionType, &address)
+			if err == sql.ErrNoRows {
+				c.JSON(404, gin.H{
+					"success": false,
+					"message": "Printer not found",
+				})
+				return
+			}
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to update printer",
+					"error":   err.Error(),
+				})
+				return
+			}
+			if req.ConnectionType != nil {
+				connectionType = *req.ConnectionType
+			}
+			if req.Address != nil {
+				address = *req.Address
+			}
+			if connectionType == printing.ConnectionFile {
+				if _, err := printing.FilePath(address); err != nil {
+					c.JSON(400, gin.H{
+						"success": false,
+						"message": "File printers need a path inside the spool directory",
+						"error":   "invalid_address",
+					})
+					return
+				}
+			}
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.StationID != nil {
+			if *req.StationID == "" {
+				updates = append(updates, "station_id = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("station_id = $%d", argCount))
+				args = append(args, *req.StationID)
+				argCount++
+			}
+		}
+		if req.ConnectionType != nil {
+			updates = append(updates, fmt.Sprintf("connection_type = $%d", argCount))
+			args = append(args, *req.ConnectionType)
+			argCount++
+		}
+		if req.Address != nil {
+			updates = append(updates, fmt.Sprintf("address = $%d", argCount))
+			args = append(args, *req.Address)
+			argCount++
+		}
+		if req.CharsPerLine != nil {
+			updates = append(updates, fmt.Sprintf("chars_per_line = $%d", argCount))
+			args = append(args, *req.CharsPerLine)
+			argCount++
+		}
+		if req.IsActive != nil {
+			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+			args = append(args, *req.IsActive)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, printerID)
+
+		query := fmt.Sprintf(`
+			UPDATE printers
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update printer",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Printer not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Printer updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete printer
+func deletePrinter(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		printerID := c.Param("id")
+
+		// Printers with spooled jobs are kept for the job history; deactivate them instead
+		var jobCount int
+		db.QueryRow("SELECT COUNT(*) FROM print_jobs WHERE printer_id = $1", printerID).Scan(&jobCount)
+
+		if jobCount > 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Cannot delete a printer with print jobs; deactivate it instead",
+				"error":   "printer_in_use",
+			})
+			return
+		}
+
+		result, err := db.Exec("DELETE FROM printers WHERE id = $1", printerID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete printer",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Printer not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Printer deleted successfully",
+		})
+	}
+}
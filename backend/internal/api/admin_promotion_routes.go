@@ -0,0 +1,426 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetupAdminPromotionRoutes configures promotion management for admin/manager roles
+func SetupAdminPromotionRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/promotions", getPromotions(db))
+		admin.POST("/promotions", createPromotion(db))
+		admin.PUT("/promotions/:id", updatePromotion(db))
+		admin.DELETE("/promotions/:id", deletePromotion(db))
+	}
+}
+
+// promotionRequest carries the fields accepted when creating or updating a promotion.
+// Empty strings clear optional fields on update.
+type promotionRequest struct {
+	Name          *string  `json:"name"`
+	Description   *string  `json:"description"`
+	PromotionType *string  `json:"promotion_type"`
+	Scope         *string  `json:"scope"`
+	Value         *float64 `json:"value"`
+	ProductID     *string  `json:"product_id"`
+	CategoryID    *string  `json:"category_id"`
+	BuyQuantity   *int     `json:"buy_quantity"`
+	GetQuantity   *int     `json:"get_quantity"`
+	MinSpend      *float64 `json:"min_spend"`
+	IsStackable   *bool    `json:"is_stackable"`
+	StartsAt      *string  `json:"starts_at"`
+	EndsAt        *string  `json:"ends_at"`
+	DaysOfWeek    *[]int64 `json:"days_of_week"`
+	StartTime     *string  `json:"start_time"`
+	EndTime       *string  `json:"end_time"`
+	IsActive      *bool    `json:"is_active"`
+}
+
+const promotionSelect = `
+	SELECT id, name, description, promotion_type, scope, value, product_id, category_id,
+	       buy_quantity, get_quantity, min_spend, is_stackable, starts_at, ends_at, days_of_week,
+	       to_char(start_time, 'HH24:MI:SS'), to_char(end_time, 'HH24:MI:SS'),
+	       is_active, created_at, updated_at
+	FROM promotions
+`
+
+func scanPromotionRow(scan func(dest ...interface{}) error, p *models.Promotion) error {
+	return scan(&p.ID, &p.Name, &p.Description, &p.PromotionType, &p.Scope, &p.Value, &p.ProductID, &p.CategoryID,
+		&p.BuyQuantity, &p.GetQuantity, &p.MinSpend, &p.IsStackable, &p.StartsAt, &p.EndsAt, pq.Array(&p.DaysOfWeek),
+		&p.StartTime, &p.EndTime, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// apply merges the request into p and validates the result
+func (req promotionRequest) apply(p *models.Promotion) error {
+	if req.Name != nil {
+		p.Name = *req.Name
+	}
+	if req.Description != nil {
+		p.Description = req.Description
+	}
+	if req.PromotionType != nil {
+		p.PromotionType = *req.PromotionType
+	}
+	if req.Scope != nil {
+		p.Scope = *req.Scope
+	}
+	if req.Value != nil {
+		p.Value = *req.Value
+	}
+	if req.ProductID != nil {
+		id, err := parseOptionalUUID(*req.ProductID)
+		if err != nil {
+			return fmt.Errorf("invalid product_id")
+		}
+		p.ProductID = id
+	}
+	if req.CategoryID != nil {
+		id, err := parseOptionalUUID(*req.CategoryID)
+		if err != nil {
+			return fmt.Errorf("invalid category_id")
+		}
+		p.CategoryID = id
+	}
+	if req.BuyQuantity != nil {
+		p.BuyQuantity = req.BuyQuantity
+	}
+	if req.GetQuantity != nil {
+		p.GetQuantity = req.GetQuantity
+	}
+	if req.MinSpend != nil {
+		p.MinSpend = *req.MinSpend
+	}
+	if req.IsStackable != nil {
+		p.IsStackable = *req.IsStackable
+	}
+	if req.StartsAt != nil {
+		t, err := parseOptionalTime(*req.StartsAt)
+		if err != nil {
+			return fmt.Errorf("starts_at must be an RFC3339 timestamp")
+		}
+		p.StartsAt = t
+	}
+	if req.EndsAt != nil {
+		t, err := parseOptionalTime(*req.EndsAt)
+		if err != nil {
+			return fmt.Errorf("ends_at must be an RFC3339 timestamp")
+		}
+		p.EndsAt = t
+	}
+	if req.DaysOfWeek != nil {
+		p.DaysOfWeek = *req.DaysOfWeek
+	}
+	if req.StartTime != nil {
+		t, err := parseOptionalClock(*req.StartTime)
+		if err != nil {
+			return fmt.Errorf("start_time must be HH:MM or HH:MM:SS")
+		}
+		p.StartTime = t
+	}
+	if req.EndTime != nil {
+		t, err := parseOptionalClock(*req.EndTime)
+		if err != nil {
+			return fmt.Errorf("end_time must be HH:MM or HH:MM:SS")
+		}
+		p.EndTime = t
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch p.PromotionType {
+	case "percent", "buy_x_get_y":
+		if p.Value <= 0 || p.Value > 100 {
+			return fmt.Errorf("value must be a percentage between 0 and 100")
+		}
+	case "fixed":
+		if p.Value <= 0 {
+			return fmt.Errorf("value must be greater than zero")
+		}
+	default:
+		return fmt.Errorf("promotion_type must be one of percent, fixed, buy_x_get_y")
+	}
+	switch p.Scope {
+	case "order":
+		if p.PromotionType == "buy_x_get_y" {
+			return fmt.Errorf("buy_x_get_y promotions must target an item or category")
+		}
+	case "item":
+		if p.ProductID == nil {
+			return fmt.Errorf("product_id is required for item promotions")
+		}
+	case "category":
+		if p.CategoryID == nil {
+			return fmt.Errorf("category_id is required for category promotions")
+		}
+	default:
+		return fmt.Errorf("scope must be one of order, item, category")
+	}
+	if p.PromotionType == "buy_x_get_y" {
+		if p.BuyQuantity == nil || p.GetQuantity == nil || *p.BuyQuantity <= 0 || *p.GetQuantity <= 0 {
+			return fmt.Errorf("buy_quantity and get_quantity are required for buy_x_get_y promotions")
+		}
+	}
+	if p.MinSpend < 0 {
+		return fmt.Errorf("min_spend cannot be negative")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	for _, day := range p.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("days_of_week values must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if (p.StartTime == nil) != (p.EndTime == nil) {
+		return fmt.Errorf("start_time and end_time must be set together")
+	}
+
+	return nil
+}
+
+func parseOptionalUUID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseOptionalClock normalises a time of day to HH:MM:SS
+func parseOptionalClock(value string) (*string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			clock := t.Format("15:04:05")
+			return &clock, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time of day")
+}
+
+// Admin handler - List promotions
+func getPromotions(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := promotionSelect
+		if c.Query("active") == "true" {
+			query += " WHERE is_active = true"
+		}
+		query += " ORDER BY is_active DESC, name ASC"
+
+		rows, err := db.Query(query)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch promotions",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		promotions := []models.Promotion{}
+		for rows.Next() {
+			var promotion models.Promotion
+			if err := scanPromotionRow(rows.Scan, &promotion); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan promotion",
+					"error":   err.Error(),
+				})
+				return
+			}
+			promotions = append(promotions, promotion)
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Promotions retrieved successfully",
+			"data":    promotions,
+		})
+	}
+}
+
+// Admin handler - Create promotion
+func createPromotion(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req promotionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		promotion := models.Promotion{Scope: "order", IsActive: true}
+		if err := req.apply(&promotion); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": err.Error(),
+				"error":   "invalid_promotion",
+			})
+			return
+		}
+
+		var promotionID string
+		err := db.QueryRow(`
+			INSERT INTO promotions (name, description, promotion_type, scope, value, product_id, category_id,
+			                        buy_quantity, get_quantity, min_spend, is_stackable, starts_at, ends_at,
+			                        days_of_week, start_time, end_time, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			RETURNING id
+		`, promotion.Name, promotion.Description, promotion.PromotionType, promotion.Scope, promotion.Value,
+			promotion.ProductID, promotion.CategoryID, promotion.BuyQuantity, promotion.GetQuantity, promotion.MinSpend,
+			promotion.IsStackable, promotion.StartsAt, promotion.EndsAt, pq.Array(promotion.DaysOfWeek),
+			promotion.StartTime, promotion.EndTime, promotion.IsActive).Scan(&promotionID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create promotion",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Promotion created successfully",
+			"data":    map[string]interface{}{"id": promotionID},
+		})
+	}
+}
+
+// Admin handler - Update promotion
+func updatePromotion(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		promotionID := c.Param("id")
+
+		var req promotionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		var promotion models.Promotion
+		err := scanPromotionRow(db.QueryRow(promotionSelect+" WHERE id = $1", promotionID).Scan, &promotion)
+		if err == sql.ErrNoRows {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Promotion not found",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch promotion",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if err := req.apply(&promotion); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": err.Error(),
+				"error":   "invalid_promotion",
+			})
+			return
+		}
+
+		// Orders keep the discount already applied; changes affect later recalculations
+		_, err = db.Exec(`
+			UPDATE promotions
+			SET name = $1, description = $2, promotion_type = $3, scope = $4, value = $5, product_id = $6,
+			    category_id = $7, buy_quantity = $8, get_quantity = $9, min_spend = $10, is_stackable = $11,
+			    starts_at = $12, ends_at = $13, days_of_week = $14, start_time = $15, end_time = $16,
+			    is_active = $17, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $18
+		`, promotion.Name, promotion.Description, promotion.PromotionType, promotion.Scope, promotion.Value,
+			promotion.ProductID, promotion.CategoryID, promotion.BuyQuantity, promotion.GetQuantity, promotion.MinSpend,
+			promotion.IsStackable, promotion.StartsAt, promotion.EndsAt, pq.Array(promotion.DaysOfWeek),
+			promotion.StartTime, promotion.EndTime, promotion.IsActive, promotionID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update promotion",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Promotion updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete promotion
+func deletePromotion(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		promotionID := c.Param("id")
+
+		// Applied discounts keep their name and amount through ON DELETE SET NULL
+		result, err := db.Exec("DELETE FROM promotions WHERE id = $1", promotionID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete promotion",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Promotion not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Promotion deleted successfully",
+		})
+	}
+}
@@ -2,8 +2,17 @@ package api
 
 import (
 	"database/sql"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pos-backend/internal/export"
+	"pos-backend/internal/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // Dashboard stats handler
@@ -12,21 +21,32 @@ func getDashboardStats(db *sql.DB) gin.HandlerFunc {
 		// Get basic stats for dashboard
 		stats := make(map[string]interface{})
 
+		// Today is the current business day in the store's time zone
+		today, err := newReportPeriod(c.Request.Context(), db, "today", "", "", "")
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch dashboard stats",
+				"error":   err.Error(),
+			})
+			return
+		}
+
 		// Today's orders
 		var todayOrders int
 		db.QueryRow(`
 			SELECT COUNT(*) 
 			FROM orders 
-			WHERE DATE(created_at) = CURRENT_DATE
-		`).Scan(&todayOrders)
+			WHERE `+today.within("created_at")+`
+		`, today.Start, today.End).Scan(&todayOrders)
 
 		// Today's revenue
 		var todayRevenue float64
 		db.QueryRow(`
 			SELECT COALESCE(SUM(total_amount), 0) 
 			FROM orders 
-			WHERE DATE(created_at) = CURRENT_DATE AND status = 'completed'
-		`).Scan(&todayRevenue)
+			WHERE `+today.within("created_at")+` AND status = 'completed'
+		`, today.Start, today.End).Scan(&todayRevenue)
 
 		// Active orders
 		var activeOrders int
@@ -57,40 +77,51 @@ func getDashboardStats(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
+// orderRefundsJoin adds each order's refunded total as rf.amount to a query over orders o
+const orderRefundsJoin = `
+				LEFT JOIN (SELECT order_id, SUM(amount) AS amount FROM refunds WHERE status = 'completed' GROUP BY order_id) rf ON rf.order_id = o.id`
+
+// keptShare is the fraction of an order's total that was not refunded; tax and net
+// income are reduced in the same proportion as the refund
+const keptShare = `(1 - CASE WHEN o.total_amount > 0 THEN COALESCE(rf.amount, 0) / o.total_amount ELSE 0 END)`
+
+// orderTipsJoin adds each order's tips as tp.amount to a query over orders o. Tips are
+// paid on top of the order total, so they never count towards gross income.
+const orderTipsJoin = `
+				LEFT JOIN (SELECT order_id, SUM(tip_amount) AS amount FROM payments WHERE status IN ('completed', 'refunded') GROUP BY order_id) tp ON tp.order_id = o.id`
+
+// salesColumns are the rows of the sales report, one per hour, business day or month
+var salesColumns = export.Columns{
+	{Key: "date", Title: "Period"},
+	{Key: "order_count", Title: "Orders"},
+	{Key: "revenue", Title: "Revenue"},
+	{Key: "refunds", Title: "Refunds"},
+}
+
 // Sales report handler
 func getSalesReport(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		period := c.DefaultQuery("period", "today") // today, week, month
-
-		var query string
-		switch period {
-		case "week":
-			query = `
-				SELECT DATE(created_at) as date, COUNT(*) as order_count, SUM(total_amount) as revenue
-				FROM orders 
-				WHERE created_at >= CURRENT_DATE - INTERVAL '7 days' AND status = 'completed'
-				GROUP BY DATE(created_at)
-				ORDER BY date DESC
-			`
-		case "month":
-			query = `
-				SELECT DATE(created_at) as date, COUNT(*) as order_count, SUM(total_amount) as revenue
-				FROM orders 
-				WHERE created_at >= CURRENT_DATE - INTERVAL '30 days' AND status = 'completed'
-				GROUP BY DATE(created_at)
-				ORDER BY date DESC
-			`
-		default: // today
-			query = `
-				SELECT DATE_TRUNC('hour', created_at) as hour, COUNT(*) as order_count, SUM(total_amount) as revenue
-				FROM orders 
-				WHERE DATE(created_at) = CURRENT_DATE AND status = 'completed'
-				GROUP BY DATE_TRUNC('hour', created_at)
-				ORDER BY hour DESC
-			`
-		}
-
-		rows, err := db.Query(query)
+		format, ok := reportFormat(c)
+		if !ok {
+			return
+		}
+
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch sales report")
+			return
+		}
+
+		query := `
+			SELECT ` + period.bucket("o.created_at") + ` as date, COUNT(*) as order_count,
+				SUM(o.total_amount - COALESCE(rf.amount, 0)) as revenue, COALESCE(SUM(rf.amount), 0) as refunds
+			FROM orders o` + orderRefundsJoin + `
+			WHERE ` + period.within("o.created_at") + ` AND o.status = 'completed'
+			GROUP BY 1
+			ORDER BY date DESC
+		`
+
+		rows, err := db.Query(query, period.Start, period.End)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"success": false,
@@ -103,11 +134,11 @@ func getSalesReport(db *sql.DB) gin.HandlerFunc {
 
 		var report []map[string]interface{}
 		for rows.Next() {
-			var date interface{}
+			var date string
 			var orderCount int
-			var revenue float64
+			var revenue, refunds float64
 
-			err := rows.Scan(&date, &orderCount, &revenue)
+			err := rows.Scan(&date, &orderCount, &revenue, &refunds)
 			if err != nil {
 				c.JSON(500, gin.H{
 					"success": false,
@@ -117,24 +148,82 @@ func getSalesReport(db *sql.DB) gin.HandlerFunc {
 				return
 			}
 
-			report = append(report, map[string]interface{}{
-				"date":        date,
-				"order_count": orderCount,
-				"revenue":     revenue,
-			})
+			row, err := salesColumns.Row(date, orderCount, revenue, refunds)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to build sales report",
+					"error":   err.Error(),
+				})
+				return
+			}
+			report = append(report, row)
 		}
 
-		c.JSON(200, gin.H{
+		if format != "json" {
+			writeReportFile(c, format, "sales", "Sales report", period, salesColumns, report)
+			return
+		}
+
+		comparison, err := reportComparison(c, db, period, salesSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare sales report")
+			return
+		}
+
+		response := gin.H{
 			"success": true,
 			"message": "Sales report retrieved successfully",
 			"data":    report,
-		})
+			"range":   period.describe(),
+		}
+		if comparison != nil {
+			response["comparison"] = comparison
+		}
+		c.JSON(200, response)
 	}
 }
 
+// salesSummary totals completed orders and their refunds over a period
+func salesSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var orderCount int
+	var revenue, refunds float64
+	err := db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(o.total_amount - COALESCE(rf.amount, 0)), 0), COALESCE(SUM(rf.amount), 0)
+		FROM orders o`+orderRefundsJoin+`
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+	`, period.Start, period.End).Scan(&orderCount, &revenue, &refunds)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"order_count": orderCount,
+		"revenue":     revenue,
+		"refunds":     refunds,
+	}, nil
+}
+
+// orderColumns are the rows of the orders report, one per order status
+var orderColumns = export.Columns{
+	{Key: "status", Title: "Status"},
+	{Key: "count", Title: "Orders"},
+	{Key: "avg_amount", Title: "Average amount"},
+}
+
 // Orders report handler
 func getOrdersReport(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		format, ok := reportFormat(c)
+		if !ok {
+			return
+		}
+
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch orders report")
+			return
+		}
+
 		// Get order statistics
 		query := `
 			SELECT 
@@ -142,11 +231,11 @@ func getOrdersReport(db *sql.DB) gin.HandlerFunc {
 				COUNT(*) as count,
 				AVG(total_amount) as avg_amount
 			FROM orders 
-			WHERE DATE(created_at) = CURRENT_DATE
+			WHERE ` + period.within("created_at") + `
 			GROUP BY status
 		`
 
-		rows, err := db.Query(query)
+		rows, err := db.Query(query, period.Start, period.End)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"success": false,
@@ -173,87 +262,77 @@ func getOrdersReport(db *sql.DB) gin.HandlerFunc {
 				return
 			}
 
-			report = append(report, map[string]interface{}{
-				"status":     status,
-				"count":      count,
-				"avg_amount": avgAmount,
-			})
+			row, err := orderColumns.Row(status, count, avgAmount)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to build orders report",
+					"error":   err.Error(),
+				})
+				return
+			}
+			report = append(report, row)
+		}
+
+		if format != "json" {
+			writeReportFile(c, format, "orders", "Orders report", period, orderColumns, report)
+			return
 		}
 
 		c.JSON(200, gin.H{
 			"success": true,
 			"message": "Orders report retrieved successfully",
 			"data":    report,
+			"range":   period.describe(),
 		})
 	}
 }
 
+// incomeColumns are the rows of the income report breakdown, one per hour, business day or
+// month
+var incomeColumns = export.Columns{
+	{Key: "period", Title: "Period"},
+	{Key: "orders", Title: "Orders"},
+	{Key: "gross", Title: "Gross"},
+	{Key: "refunds", Title: "Refunds"},
+	{Key: "tax", Title: "Tax"},
+	{Key: "net", Title: "Net"},
+	{Key: "service_charges", Title: "Service charges"},
+	{Key: "tips", Title: "Tips"},
+}
+
 // Income report handler
 func getIncomeReport(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		period := c.DefaultQuery("period", "today") // today, week, month, year
-
-		var query string
-		switch period {
-		case "week":
-			query = `
-				SELECT 
-					DATE_TRUNC('day', created_at) as period,
-					COUNT(*) as total_orders,
-					SUM(total_amount) as gross_income,
-					SUM(tax_amount) as tax_collected,
-					SUM(total_amount - tax_amount) as net_income
-				FROM orders 
-				WHERE created_at >= CURRENT_DATE - INTERVAL '7 days' 
-					AND status = 'completed'
-				GROUP BY DATE_TRUNC('day', created_at)
-				ORDER BY period DESC
-			`
-		case "month":
-			query = `
-				SELECT 
-					DATE_TRUNC('day', created_at) as period,
-					COUNT(*) as total_orders,
-					SUM(total_amount) as gross_income,
-					SUM(tax_amount) as tax_collected,
-					SUM(total_amount - tax_amount) as net_income
-				FROM orders 
-				WHERE created_at >= CURRENT_DATE - INTERVAL '30 days' 
-					AND status = 'completed'
-				GROUP BY DATE_TRUNC('day', created_at)
-				ORDER BY period DESC
-			`
-		case "year":
-			query = `
-				SELECT 
-					DATE_TRUNC('month', created_at) as period,
-					COUNT(*) as total_orders,
-					SUM(total_amount) as gross_income,
-					SUM(tax_amount) as tax_collected,
-					SUM(total_amount - tax_amount) as net_income
-				FROM orders 
-				WHERE created_at >= CURRENT_DATE - INTERVAL '1 year' 
-					AND status = 'completed'
-				GROUP BY DATE_TRUNC('month', created_at)
-				ORDER BY period DESC
-			`
-		default: // today
-			query = `
-				SELECT 
-					DATE_TRUNC('hour', created_at) as period,
-					COUNT(*) as total_orders,
-					SUM(total_amount) as gross_income,
-					SUM(tax_amount) as tax_collected,
-					SUM(total_amount - tax_amount) as net_income
-				FROM orders 
-				WHERE DATE(created_at) = CURRENT_DATE 
-					AND status = 'completed'
-				GROUP BY DATE_TRUNC('hour', created_at)
-				ORDER BY period DESC
-			`
-		}
-
-		rows, err := db.Query(query)
+		format, ok := reportFormat(c)
+		if !ok {
+			return
+		}
+
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch income report")
+			return
+		}
+
+		query := `
+			SELECT
+				` + period.bucket("o.created_at") + ` as period,
+				COUNT(*) as total_orders,
+				SUM(o.total_amount - COALESCE(rf.amount, 0)) as gross_income,
+				COALESCE(SUM(rf.amount), 0) as refunds,
+				SUM(o.tax_amount * ` + keptShare + `) as tax_collected,
+				SUM((o.total_amount - o.tax_amount) * ` + keptShare + `) as net_income,
+				SUM(o.service_charge_amount * ` + keptShare + `) as service_charges,
+				COALESCE(SUM(tp.amount), 0) as tips
+			FROM orders o` + orderRefundsJoin + orderTipsJoin + `
+			WHERE ` + period.within("o.created_at") + `
+				AND o.status = 'completed'
+			GROUP BY 1
+			ORDER BY period DESC
+		`
+
+		rows, err := db.Query(query, period.Start, period.End)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"success": false,
@@ -265,15 +344,15 @@ func getIncomeReport(db *sql.DB) gin.HandlerFunc {
 		defer rows.Close()
 
 		var report []map[string]interface{}
-		var totalGross, totalTax, totalNet float64
+		var totalGross, totalRefunds, totalTax, totalNet, totalServiceCharges, totalTips float64
 		var totalOrders int
 
 		for rows.Next() {
-			var period interface{}
+			var bucket string
 			var orders int
-			var gross, tax, net float64
+			var gross, refunds, tax, net, serviceCharges, tips float64
 
-			err := rows.Scan(&period, &orders, &gross, &tax, &net)
+			err := rows.Scan(&bucket, &orders, &gross, &refunds, &tax, &net, &serviceCharges, &tips)
 			if err != nil {
 				c.JSON(500, gin.H{
 					"success": false,
@@ -285,27 +364,98 @@ func getIncomeReport(db *sql.DB) gin.HandlerFunc {
 
 			totalOrders += orders
 			totalGross += gross
+			totalRefunds += refunds
 			totalTax += tax
 			totalNet += net
+			totalServiceCharges += serviceCharges
+			totalTips += tips
+
+			row, err := incomeColumns.Row(bucket, orders, gross, refunds, tax, net, serviceCharges, tips)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to build income report",
+					"error":   err.Error(),
+				})
+				return
+			}
+			report = append(report, row)
+		}
 
-			report = append(report, map[string]interface{}{
-				"period": period,
-				"orders": orders,
-				"gross":  gross,
-				"tax":    tax,
-				"net":    net,
+		// Files hold the breakdown; the tax breakdown and comparison stay in the JSON report
+		if format != "json" {
+			writeReportFile(c, format, "income", "Income report", period, incomeColumns, report)
+			return
+		}
+
+		// Per-rate tax totals for the same period
+		taxRows, err := db.Query(`
+			SELECT ot.tax_name, ot.rate, ot.is_inclusive,
+				SUM(ot.taxable_amount * `+keptShare+`) as taxable_amount,
+				SUM(ot.tax_amount * `+keptShare+`) as tax_amount
+			FROM order_taxes ot
+			JOIN orders o ON ot.order_id = o.id`+orderRefundsJoin+`
+			WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			GROUP BY ot.tax_name, ot.rate, ot.is_inclusive
+			ORDER BY ot.tax_name, ot.rate
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch tax breakdown",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer taxRows.Close()
+
+		var taxBreakdown []map[string]interface{}
+		for taxRows.Next() {
+			var taxName string
+			var rate, taxable, taxAmount float64
+			var isInclusive bool
+
+			if err := taxRows.Scan(&taxName, &rate, &isInclusive, &taxable, &taxAmount); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan tax breakdown",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			taxBreakdown = append(taxBreakdown, map[string]interface{}{
+				"tax_name":       taxName,
+				"rate":           rate,
+				"is_inclusive":   isInclusive,
+				"taxable_amount": taxable,
+				"tax_amount":     taxAmount,
 			})
 		}
 
 		result := map[string]interface{}{
 			"summary": map[string]interface{}{
-				"total_orders":  totalOrders,
-				"gross_income":  totalGross,
-				"tax_collected": totalTax,
-				"net_income":    totalNet,
+				"total_orders":    totalOrders,
+				"gross_income":    totalGross,
+				"total_refunds":   totalRefunds,
+				"tax_collected":   totalTax,
+				"net_income":      totalNet,
+				"service_charges": totalServiceCharges,
+				"tips":            totalTips,
 			},
-			"breakdown": report,
-			"period":    period,
+			"breakdown":     report,
+			"tax_breakdown": taxBreakdown,
+			"period":        period.Name,
+			"range":         period.describe(),
+		}
+
+		comparison, err := reportComparison(c, db, period, incomeSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare income report")
+			return
+		}
+		if comparison != nil {
+			result["comparison"] = comparison
 		}
 
 		c.JSON(200, gin.H{
@@ -314,4 +464,1018 @@ func getIncomeReport(db *sql.DB) gin.HandlerFunc {
 			"data":    result,
 		})
 	}
-}
\ No newline at end of file
+}
+
+// incomeSummary totals income from completed orders over a period, net of refunds
+func incomeSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var totalOrders int
+	var gross, refunds, tax, net, serviceCharges, tips float64
+	err := db.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(o.total_amount - COALESCE(rf.amount, 0)), 0),
+			COALESCE(SUM(rf.amount), 0),
+			COALESCE(SUM(o.tax_amount * `+keptShare+`), 0),
+			COALESCE(SUM((o.total_amount - o.tax_amount) * `+keptShare+`), 0),
+			COALESCE(SUM(o.service_charge_amount * `+keptShare+`), 0),
+			COALESCE(SUM(tp.amount), 0)
+		FROM orders o`+orderRefundsJoin+orderTipsJoin+`
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+	`, period.Start, period.End).Scan(&totalOrders, &gross, &refunds, &tax, &net, &serviceCharges, &tips)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"total_orders":    totalOrders,
+		"gross_income":    gross,
+		"total_refunds":   refunds,
+		"tax_collected":   tax,
+		"net_income":      net,
+		"service_charges": serviceCharges,
+		"tips":            tips,
+	}, nil
+}
+
+// Void and comp report handler
+func getVoidCompReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch void/comp report")
+			return
+		}
+		dateFilter := period.within("a.created_at")
+
+		// Totals per adjustment type and reason code
+		rows, err := db.Query(`
+			SELECT a.adjustment_type, a.reason_code,
+				COUNT(*) as adjustment_count,
+				SUM(a.quantity) as item_count,
+				SUM(a.amount) as total_amount
+			FROM order_item_adjustments a
+			WHERE `+dateFilter+`
+			GROUP BY a.adjustment_type, a.reason_code
+			ORDER BY a.adjustment_type, total_amount DESC
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch void/comp report",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		summary := map[string]map[string]interface{}{
+			"void": {"count": 0, "items": 0, "amount": 0.0},
+			"comp": {"count": 0, "items": 0, "amount": 0.0},
+		}
+		var byReason []map[string]interface{}
+		for rows.Next() {
+			var adjustmentType, reasonCode string
+			var adjustmentCount, itemCount int
+			var amount float64
+
+			if err := rows.Scan(&adjustmentType, &reasonCode, &adjustmentCount, &itemCount, &amount); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan void/comp data",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			if totals, ok := summary[adjustmentType]; ok {
+				totals["count"] = totals["count"].(int) + adjustmentCount
+				totals["items"] = totals["items"].(int) + itemCount
+				totals["amount"] = totals["amount"].(float64) + amount
+			}
+
+			byReason = append(byReason, map[string]interface{}{
+				"adjustment_type": adjustmentType,
+				"reason_code":     reasonCode,
+				"count":           adjustmentCount,
+				"items":           itemCount,
+				"amount":          amount,
+			})
+		}
+
+		// Totals per approving manager
+		approverRows, err := db.Query(`
+			SELECT u.id::text, u.username, u.first_name, u.last_name, a.adjustment_type,
+				COUNT(*) as adjustment_count,
+				SUM(a.amount) as total_amount
+			FROM order_item_adjustments a
+			JOIN users u ON a.approved_by = u.id
+			WHERE `+dateFilter+`
+			GROUP BY u.id, u.username, u.first_name, u.last_name, a.adjustment_type
+			ORDER BY total_amount DESC
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch void/comp approvers",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer approverRows.Close()
+
+		var byApprover []map[string]interface{}
+		for approverRows.Next() {
+			var userID, username, firstName, lastName, adjustmentType string
+			var adjustmentCount int
+			var amount float64
+
+			if err := approverRows.Scan(&userID, &username, &firstName, &lastName, &adjustmentType, &adjustmentCount, &amount); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan void/comp approver data",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			byApprover = append(byApprover, map[string]interface{}{
+				"user_id":         userID,
+				"username":        username,
+				"name":            firstName + " " + lastName,
+				"adjustment_type": adjustmentType,
+				"count":           adjustmentCount,
+				"amount":          amount,
+			})
+		}
+
+		result := map[string]interface{}{
+			"summary":     summary,
+			"by_reason":   byReason,
+			"by_approver": byApprover,
+			"period":      period.Name,
+			"range":       period.describe(),
+		}
+
+		comparison, err := reportComparison(c, db, period, voidCompSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare void/comp report")
+			return
+		}
+		if comparison != nil {
+			result["comparison"] = comparison
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Void/comp report retrieved successfully",
+			"data":    result,
+		})
+	}
+}
+
+// voidCompSummary totals voids and comps over a period
+func voidCompSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var voidCount, compCount int
+	var voidAmount, compAmount float64
+	err := db.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE a.adjustment_type = 'void'),
+			COALESCE(SUM(a.amount) FILTER (WHERE a.adjustment_type = 'void'), 0),
+			COUNT(*) FILTER (WHERE a.adjustment_type = 'comp'),
+			COALESCE(SUM(a.amount) FILTER (WHERE a.adjustment_type = 'comp'), 0)
+		FROM order_item_adjustments a
+		WHERE `+period.within("a.created_at")+`
+	`, period.Start, period.End).Scan(&voidCount, &voidAmount, &compCount, &compAmount)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"void_count":  voidCount,
+		"void_amount": voidAmount,
+		"comp_count":  compCount,
+		"comp_amount": compAmount,
+	}, nil
+}
+
+// Discount report handler - totals per promotion for completed orders
+func getDiscountReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch discount report")
+			return
+		}
+
+		rows, err := db.Query(`
+			SELECT d.promotion_id::text, d.promotion_name,
+				COUNT(DISTINCT d.order_id) as order_count,
+				SUM(d.amount) as total_discount,
+				SUM(o.subtotal) as gross_sales
+			FROM order_discounts d
+			JOIN orders o ON d.order_id = o.id
+			WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			GROUP BY d.promotion_id, d.promotion_name
+			ORDER BY total_discount DESC
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch discount report",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		var promotions []map[string]interface{}
+		var totalDiscount float64
+		var totalOrders int
+		for rows.Next() {
+			var promotionID sql.NullString
+			var promotionName string
+			var orderCount int
+			var discount, grossSales float64
+
+			if err := rows.Scan(&promotionID, &promotionName, &orderCount, &discount, &grossSales); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan discount data",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			totalDiscount += discount
+			totalOrders += orderCount
+
+			var id interface{}
+			if promotionID.Valid {
+				id = promotionID.String
+			}
+			promotions = append(promotions, map[string]interface{}{
+				"promotion_id":   id,
+				"promotion_name": promotionName,
+				"order_count":    orderCount,
+				"total_discount": discount,
+				"gross_sales":    grossSales,
+			})
+		}
+
+		result := map[string]interface{}{
+			"promotions":     promotions,
+			"total_discount": totalDiscount,
+			"order_count":    totalOrders,
+			"period":         period.Name,
+			"range":          period.describe(),
+		}
+
+		comparison, err := reportComparison(c, db, period, discountSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare discount report")
+			return
+		}
+		if comparison != nil {
+			result["comparison"] = comparison
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Discount report retrieved successfully",
+			"data":    result,
+		})
+	}
+}
+
+// discountSummary totals discounts on completed orders over a period
+func discountSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var orderCount int
+	var totalDiscount float64
+	err := db.QueryRow(`
+		SELECT COUNT(DISTINCT d.order_id), COALESCE(SUM(d.amount), 0)
+		FROM order_discounts d
+		JOIN orders o ON d.order_id = o.id
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+	`, period.Start, period.End).Scan(&orderCount, &totalDiscount)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"total_discount": totalDiscount,
+		"order_count":    orderCount,
+	}, nil
+}
+
+// Tip report handler - tips and service charges per server for completed orders. Tips are
+// credited to the staff member who owns the order, whoever took the payment.
+func getTipReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch tip report")
+			return
+		}
+
+		rows, err := db.Query(`
+			SELECT u.id::text, u.username, u.first_name, u.last_name,
+				COUNT(*) as order_count,
+				COALESCE(SUM(tp.cash_tips + tp.card_tips), 0) as tips,
+				COALESCE(SUM(tp.cash_tips), 0) as cash_tips,
+				COALESCE(SUM(tp.card_tips), 0) as card_tips,
+				SUM(o.service_charge_amount) as service_charges
+			FROM orders o
+			JOIN users u ON o.user_id = u.id
+			LEFT JOIN (
+				SELECT order_id,
+					SUM(CASE WHEN payment_method = 'cash' THEN tip_amount ELSE 0 END) AS cash_tips,
+					SUM(CASE WHEN payment_method <> 'cash' THEN tip_amount ELSE 0 END) AS card_tips
+				FROM payments
+				WHERE status IN ('completed', 'refunded')
+				GROUP BY order_id
+			) tp ON tp.order_id = o.id
+			WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			GROUP BY u.id, u.username, u.first_name, u.last_name
+			ORDER BY tips DESC, u.username
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch tip report",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		staff := []map[string]interface{}{}
+		var totalTips, totalCashTips, totalCardTips, totalServiceCharges float64
+		for rows.Next() {
+			var userID, username, firstName, lastName string
+			var orderCount int
+			var tips, cashTips, cardTips, serviceCharges float64
+
+			if err := rows.Scan(&userID, &username, &firstName, &lastName, &orderCount,
+				&tips, &cashTips, &cardTips, &serviceCharges); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan tip data",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			totalTips += tips
+			totalCashTips += cashTips
+			totalCardTips += cardTips
+			totalServiceCharges += serviceCharges
+
+			staff = append(staff, map[string]interface{}{
+				"user_id":         userID,
+				"username":        username,
+				"name":            strings.TrimSpace(firstName + " " + lastName),
+				"order_count":     orderCount,
+				"tips":            tips,
+				"cash_tips":       cashTips,
+				"card_tips":       cardTips,
+				"service_charges": serviceCharges,
+			})
+		}
+
+		result := map[string]interface{}{
+			"staff": staff,
+			"summary": map[string]interface{}{
+				"tips":            totalTips,
+				"cash_tips":       totalCashTips,
+				"card_tips":       totalCardTips,
+				"service_charges": totalServiceCharges,
+			},
+			"period": period.Name,
+			"range":  period.describe(),
+		}
+
+		comparison, err := reportComparison(c, db, period, tipSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare tip report")
+			return
+		}
+		if comparison != nil {
+			result["comparison"] = comparison
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tip report retrieved successfully",
+			"data":    result,
+		})
+	}
+}
+
+// tipSummary totals tips and service charges on completed orders over a period
+func tipSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var tips, cashTips, cardTips, serviceCharges float64
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(tp.cash_tips + tp.card_tips), 0),
+			COALESCE(SUM(tp.cash_tips), 0),
+			COALESCE(SUM(tp.card_tips), 0),
+			COALESCE(SUM(o.service_charge_amount), 0)
+		FROM orders o
+		LEFT JOIN (
+			SELECT order_id,
+				SUM(CASE WHEN payment_method = 'cash' THEN tip_amount ELSE 0 END) AS cash_tips,
+				SUM(CASE WHEN payment_method <> 'cash' THEN tip_amount ELSE 0 END) AS card_tips
+			FROM payments
+			WHERE status IN ('completed', 'refunded')
+			GROUP BY order_id
+		) tp ON tp.order_id = o.id
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+	`, period.Start, period.End).Scan(&tips, &cashTips, &cardTips, &serviceCharges)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tips":            tips,
+		"cash_tips":       cashTips,
+		"card_tips":       cardTips,
+		"service_charges": serviceCharges,
+	}, nil
+}
+
+// itemDiscountShare allocates an order's discount to one of its items, oi, in proportion
+// to the item's share of the order subtotal
+const itemDiscountShare = `CASE WHEN o.subtotal > 0 THEN oi.total_price / o.subtotal * o.discount_amount ELSE 0 END`
+
+// productMixLine is one product's sales in the product mix report
+type productMixLine struct {
+	ProductID     string   `json:"product_id"`
+	ProductName   string   `json:"product_name"`
+	CategoryID    *string  `json:"category_id"`
+	CategoryName  string   `json:"category_name"`
+	IsAvailable   bool     `json:"is_available"`
+	Quantity      int      `json:"quantity"`
+	CompQuantity  int      `json:"comp_quantity"`
+	OrderCount    int      `json:"order_count"`
+	GrossSales    float64  `json:"gross_sales"`
+	Discounts     float64  `json:"discounts"`
+	NetSales      float64  `json:"net_sales"`
+	SalesShare    float64  `json:"sales_share"` // percent of net sales in the period
+	UnitCost      *float64 `json:"unit_cost"`
+	Cost          *float64 `json:"cost"`
+	Margin        *float64 `json:"margin"`
+	MarginPercent *float64 `json:"margin_percent"`
+	Rank          int      `json:"rank"` // by quantity sold, 1 is the best seller
+}
+
+// productMixCategory is one category's sales in the product mix report. Cost and margin
+// only cover products with a unit cost.
+type productMixCategory struct {
+	CategoryID    *string  `json:"category_id"`
+	CategoryName  string   `json:"category_name"`
+	ProductCount  int      `json:"product_count"`
+	Quantity      int      `json:"quantity"`
+	GrossSales    float64  `json:"gross_sales"`
+	Discounts     float64  `json:"discounts"`
+	NetSales      float64  `json:"net_sales"`
+	SalesShare    float64  `json:"sales_share"`
+	Cost          float64  `json:"cost"`
+	Margin        float64  `json:"margin"`
+	MarginPercent *float64 `json:"margin_percent"`
+	UncostedSales float64  `json:"uncosted_sales"` // net sales of products without a unit cost
+}
+
+// Product mix report handler - quantity, sales, discounts and margin per product and
+// category for completed orders. Voided items are left out; comped items count towards
+// quantity and cost but bring in no sales. Order discounts are spread over the items in
+// proportion to their price, and cost uses the current inventory unit cost.
+func getProductMixReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch product mix report")
+			return
+		}
+
+		limit := 10
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
+				limit = l
+			}
+		}
+
+		// Products that are still on the menu are listed even when nothing sold, so they
+		// show up among the worst sellers
+		rows, err := db.Query(`
+			SELECT p.id::text, p.name, c.id::text, COALESCE(c.name, 'Uncategorized'), COALESCE(p.is_available, false),
+				COALESCE(s.quantity, 0), COALESCE(s.comp_quantity, 0), COALESCE(s.order_count, 0),
+				COALESCE(s.gross_sales, 0), COALESCE(s.discounts, 0), inv.unit_cost
+			FROM products p
+			LEFT JOIN categories c ON p.category_id = c.id
+			LEFT JOIN inventory inv ON inv.product_id = p.id
+			LEFT JOIN (
+				SELECT oi.product_id,
+					SUM(oi.quantity) as quantity,
+					COALESCE(SUM(oi.quantity) FILTER (WHERE oi.adjustment = 'comp'), 0) as comp_quantity,
+					COUNT(DISTINCT oi.order_id) as order_count,
+					SUM(oi.total_price) as gross_sales,
+					SUM(`+itemDiscountShare+`) as discounts
+				FROM order_items oi
+				JOIN orders o ON oi.order_id = o.id
+				WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+					AND oi.adjustment IS DISTINCT FROM 'void'
+				GROUP BY oi.product_id
+			) s ON s.product_id = p.id
+			WHERE s.product_id IS NOT NULL OR p.is_available
+		`, period.Start, period.End)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch product mix report",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		products := []*productMixLine{}
+		var totalNet float64
+		for rows.Next() {
+			var line productMixLine
+			if err := rows.Scan(&line.ProductID, &line.ProductName, &line.CategoryID, &line.CategoryName, &line.IsAvailable,
+				&line.Quantity, &line.CompQuantity, &line.OrderCount, &line.GrossSales, &line.Discounts, &line.UnitCost); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan product mix data",
+					"error":   err.Error(),
+				})
+				return
+			}
+
+			line.Discounts = roundCents(line.Discounts)
+			line.NetSales = roundCents(line.GrossSales - line.Discounts)
+			if line.UnitCost != nil {
+				cost := roundCents(float64(line.Quantity) * *line.UnitCost)
+				margin := roundCents(line.NetSales - cost)
+				line.Cost = &cost
+				line.Margin = &margin
+				line.MarginPercent = percentOf(margin, line.NetSales)
+			}
+			totalNet += line.NetSales
+			products = append(products, &line)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to read product mix data",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		// Best sellers first; ties go to the product that brought in more
+		sort.SliceStable(products, func(i, j int) bool {
+			if products[i].Quantity != products[j].Quantity {
+				return products[i].Quantity > products[j].Quantity
+			}
+			if products[i].NetSales != products[j].NetSales {
+				return products[i].NetSales > products[j].NetSales
+			}
+			return products[i].ProductName < products[j].ProductName
+		})
+
+		categoryIndex := map[string]*productMixCategory{}
+		categories := []*productMixCategory{}
+		var totalQuantity int
+		var totalGross, totalDiscounts, totalCost, totalMargin float64
+		for i, line := range products {
+			line.Rank = i + 1
+			if pct := percentOf(line.NetSales, totalNet); pct != nil {
+				line.SalesShare = *pct
+			}
+
+			key := line.CategoryName
+			if line.CategoryID != nil {
+				key = *line.CategoryID
+			}
+			category, ok := categoryIndex[key]
+			if !ok {
+				category = &productMixCategory{CategoryID: line.CategoryID, CategoryName: line.CategoryName}
+				categoryIndex[key] = category
+				categories = append(categories, category)
+			}
+			category.ProductCount++
+			category.Quantity += line.Quantity
+			category.GrossSales += line.GrossSales
+			category.Discounts += line.Discounts
+			category.NetSales += line.NetSales
+			if line.Cost != nil {
+				category.Cost += *line.Cost
+				category.Margin += *line.Margin
+				totalCost += *line.Cost
+				totalMargin += *line.Margin
+			} else {
+				category.UncostedSales += line.NetSales
+			}
+
+			totalQuantity += line.Quantity
+			totalGross += line.GrossSales
+			totalDiscounts += line.Discounts
+		}
+
+		for _, category := range categories {
+			category.GrossSales = roundCents(category.GrossSales)
+			category.Discounts = roundCents(category.Discounts)
+			category.NetSales = roundCents(category.NetSales)
+			category.Cost = roundCents(category.Cost)
+			category.Margin = roundCents(category.Margin)
+			category.UncostedSales = roundCents(category.UncostedSales)
+			if pct := percentOf(category.NetSales, totalNet); pct != nil {
+				category.SalesShare = *pct
+			}
+			category.MarginPercent = percentOf(category.Margin, category.NetSales-category.UncostedSales)
+		}
+		sort.SliceStable(categories, func(i, j int) bool {
+			return categories[i].NetSales > categories[j].NetSales
+		})
+
+		best := products
+		if len(best) > limit {
+			best = best[:limit]
+		}
+		worst := []*productMixLine{}
+		for i := len(products) - 1; i >= 0 && len(worst) < limit; i-- {
+			worst = append(worst, products[i])
+		}
+
+		result := map[string]interface{}{
+			"products":      products,
+			"categories":    categories,
+			"best_sellers":  best,
+			"worst_sellers": worst,
+			"summary": map[string]interface{}{
+				"quantity":    totalQuantity,
+				"gross_sales": roundCents(totalGross),
+				"discounts":   roundCents(totalDiscounts),
+				"net_sales":   roundCents(totalNet),
+				"cost":        roundCents(totalCost),
+				"margin":      roundCents(totalMargin),
+			},
+			"period": period.Name,
+			"range":  period.describe(),
+		}
+
+		comparison, err := reportComparison(c, db, period, productMixSummary)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to compare product mix report")
+			return
+		}
+		if comparison != nil {
+			result["comparison"] = comparison
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Product mix report retrieved successfully",
+			"data":    result,
+		})
+	}
+}
+
+// productMixSummary totals items sold on completed orders over a period
+func productMixSummary(db *sql.DB, period *reportPeriod) (map[string]interface{}, error) {
+	var quantity int
+	var gross, discounts, cost, margin float64
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(oi.quantity), 0),
+			COALESCE(SUM(oi.total_price), 0),
+			COALESCE(SUM(`+itemDiscountShare+`), 0),
+			COALESCE(SUM(oi.quantity * inv.unit_cost), 0),
+			COALESCE(SUM(oi.total_price - `+itemDiscountShare+` - oi.quantity * inv.unit_cost), 0)
+		FROM order_items oi
+		JOIN orders o ON oi.order_id = o.id
+		LEFT JOIN inventory inv ON inv.product_id = oi.product_id
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			AND oi.adjustment IS DISTINCT FROM 'void'
+	`, period.Start, period.End).Scan(&quantity, &gross, &discounts, &cost, &margin)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"quantity":    quantity,
+		"gross_sales": roundCents(gross),
+		"discounts":   roundCents(discounts),
+		"net_sales":   roundCents(gross - discounts),
+		"cost":        roundCents(cost),
+		"margin":      roundCents(margin),
+	}, nil
+}
+
+// staffColumns are the rows of the staff report, one per staff member
+var staffColumns = export.Columns{
+	{Key: "user_id", Title: "User ID"},
+	{Key: "username", Title: "Username"},
+	{Key: "name", Title: "Name"},
+	{Key: "role", Title: "Role"},
+	{Key: "order_count", Title: "Orders"},
+	{Key: "sales", Title: "Sales"},
+	{Key: "refunds", Title: "Refunds"},
+	{Key: "average_ticket", Title: "Average ticket"},
+	{Key: "items", Title: "Items"},
+	{Key: "items_per_order", Title: "Items per order"},
+	{Key: "tips", Title: "Tips"},
+	{Key: "void_count", Title: "Voids"},
+	{Key: "void_amount", Title: "Void amount"},
+	{Key: "comp_count", Title: "Comps"},
+	{Key: "comp_amount", Title: "Comp amount"},
+	{Key: "discount_count", Title: "Discounts"},
+	{Key: "discount_amount", Title: "Discount amount"},
+	{Key: "payment_count", Title: "Payments"},
+	{Key: "payment_amount", Title: "Payment amount"},
+}
+
+// staffPerformance totals each staff member's sales and actions over a period. Sales, items
+// and tips come from the completed orders the staff member owns; voids and comps from the
+// adjustments they asked for; discounts from the ones they applied; payments from the ones
+// they took. userID limits it to one staff member when not empty.
+func staffPerformance(db *sql.DB, period *reportPeriod, userID string) ([]map[string]interface{}, error) {
+	args := []interface{}{period.Start, period.End}
+	userFilter := ""
+	if userID != "" {
+		args = append(args, userID)
+		userFilter = " AND u.id = $3"
+	}
+
+	rows, err := db.Query(`
+		SELECT u.id::text, u.username, u.first_name, u.last_name, u.role,
+			COALESCE(so.order_count, 0), COALESCE(so.sales, 0), COALESCE(so.refunds, 0),
+			COALESCE(so.items, 0), COALESCE(so.tips, 0),
+			COALESCE(adj.void_count, 0), COALESCE(adj.void_amount, 0),
+			COALESCE(adj.comp_count, 0), COALESCE(adj.comp_amount, 0),
+			COALESCE(dis.discount_count, 0), COALESCE(dis.discount_amount, 0),
+			COALESCE(pay.payment_count, 0), COALESCE(pay.payment_amount, 0)
+		FROM users u
+		LEFT JOIN (
+			SELECT o.user_id,
+				COUNT(*) as order_count,
+				SUM(o.total_amount - COALESCE(rf.amount, 0)) as sales,
+				SUM(COALESCE(rf.amount, 0)) as refunds,
+				SUM((SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi
+					WHERE oi.order_id = o.id AND oi.adjustment IS DISTINCT FROM 'void')) as items,
+				SUM(COALESCE(tp.amount, 0)) as tips
+			FROM orders o`+orderRefundsJoin+orderTipsJoin+`
+			WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			GROUP BY o.user_id
+		) so ON so.user_id = u.id
+		LEFT JOIN (
+			SELECT a.requested_by,
+				COUNT(*) FILTER (WHERE a.adjustment_type = 'void') as void_count,
+				COALESCE(SUM(a.amount) FILTER (WHERE a.adjustment_type = 'void'), 0) as void_amount,
+				COUNT(*) FILTER (WHERE a.adjustment_type = 'comp') as comp_count,
+				COALESCE(SUM(a.amount) FILTER (WHERE a.adjustment_type = 'comp'), 0) as comp_amount
+			FROM order_item_adjustments a
+			WHERE `+period.within("a.created_at")+`
+			GROUP BY a.requested_by
+		) adj ON adj.requested_by = u.id
+		LEFT JOIN (
+			SELECT d.applied_by, COUNT(*) as discount_count, SUM(d.amount) as discount_amount
+			FROM order_discounts d
+			JOIN orders o ON d.order_id = o.id
+			WHERE `+period.within("o.created_at")+` AND o.status = 'completed'
+			GROUP BY d.applied_by
+		) dis ON dis.applied_by = u.id
+		LEFT JOIN (
+			SELECT p.processed_by, COUNT(*) as payment_count, SUM(p.amount + p.tip_amount) as payment_amount
+			FROM payments p
+			WHERE `+period.within("p.processed_at")+` AND p.status IN ('completed', 'refunded')
+			GROUP BY p.processed_by
+		) pay ON pay.processed_by = u.id
+		WHERE (so.user_id IS NOT NULL OR adj.requested_by IS NOT NULL OR dis.applied_by IS NOT NULL
+			OR pay.processed_by IS NOT NULL)`+userFilter+`
+		ORDER BY COALESCE(so.sales, 0) DESC, u.username
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := []map[string]interface{}{}
+	for rows.Next() {
+		var id, username, firstName, lastName, role string
+		var orderCount, items, voidCount, compCount, discountCount, paymentCount int
+		var sales, refunds, tips, voidAmount, compAmount, discountAmount, paymentAmount float64
+
+		if err := rows.Scan(&id, &username, &firstName, &lastName, &role,
+			&orderCount, &sales, &refunds, &items, &tips,
+			&voidCount, &voidAmount, &compCount, &compAmount,
+			&discountCount, &discountAmount, &paymentCount, &paymentAmount); err != nil {
+			return nil, err
+		}
+
+		var averageTicket, itemsPerOrder float64
+		if orderCount > 0 {
+			averageTicket = roundCents(sales / float64(orderCount))
+			itemsPerOrder = math.Round(float64(items)/float64(orderCount)*10) / 10
+		}
+
+		row, err := staffColumns.Row(id, username, strings.TrimSpace(firstName+" "+lastName), role,
+			orderCount, sales, refunds, averageTicket, items, itemsPerOrder, tips,
+			voidCount, voidAmount, compCount, compAmount,
+			discountCount, discountAmount, paymentCount, paymentAmount)
+		if err != nil {
+			return nil, err
+		}
+		staff = append(staff, row)
+	}
+	return staff, rows.Err()
+}
+
+// staffShifts totals each staff member's completed orders per business day, with the first
+// and last order of the day. userID limits it to one staff member when not empty.
+func staffShifts(db *sql.DB, period *reportPeriod, userID string) ([]map[string]interface{}, error) {
+	days := *period
+	days.GroupBy = "day"
+
+	args := []interface{}{period.Start, period.End}
+	userFilter := ""
+	if userID != "" {
+		args = append(args, userID)
+		userFilter = " AND o.user_id = $3"
+	}
+
+	rows, err := db.Query(`
+		SELECT o.user_id::text, `+days.bucket("o.created_at")+` as business_date,
+			COUNT(*) as order_count,
+			SUM(o.total_amount - COALESCE(rf.amount, 0)) as sales,
+			COALESCE(SUM(tp.amount), 0) as tips,
+			MIN(o.created_at) as first_order_at,
+			MAX(o.created_at) as last_order_at
+		FROM orders o`+orderRefundsJoin+orderTipsJoin+`
+		WHERE `+period.within("o.created_at")+` AND o.status = 'completed' AND o.user_id IS NOT NULL`+userFilter+`
+		GROUP BY 1, 2
+		ORDER BY 2 DESC, sales DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []map[string]interface{}{}
+	for rows.Next() {
+		var id, businessDate string
+		var orderCount int
+		var sales, tips float64
+		var firstOrderAt, lastOrderAt time.Time
+
+		if err := rows.Scan(&id, &businessDate, &orderCount, &sales, &tips, &firstOrderAt, &lastOrderAt); err != nil {
+			return nil, err
+		}
+
+		shifts = append(shifts, map[string]interface{}{
+			"user_id":        id,
+			"business_date":  businessDate,
+			"order_count":    orderCount,
+			"sales":          sales,
+			"average_ticket": roundCents(sales / float64(orderCount)),
+			"tips":           tips,
+			"first_order_at": firstOrderAt,
+			"last_order_at":  lastOrderAt,
+		})
+	}
+	return shifts, rows.Err()
+}
+
+// Staff report handler - sales and actions per staff member and per shift
+func getStaffReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, ok := reportFormat(c)
+		if !ok {
+			return
+		}
+
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch staff report")
+			return
+		}
+
+		userID := c.Query("user_id")
+		if userID != "" {
+			if _, err := uuid.Parse(userID); err != nil {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Invalid user ID",
+					"error":   "invalid_uuid",
+				})
+				return
+			}
+		}
+
+		staff, err := staffPerformance(db, period, userID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch staff report",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		// Files hold the per-staff totals; shifts stay in the JSON report
+		if format != "json" {
+			writeReportFile(c, format, "staff", "Staff report", period, staffColumns, staff)
+			return
+		}
+
+		shifts, err := staffShifts(db, period, userID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch staff shifts",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Staff report retrieved successfully",
+			"data": map[string]interface{}{
+				"staff":  staff,
+				"shifts": shifts,
+				"period": period.Name,
+				"range":  period.describe(),
+			},
+		})
+	}
+}
+
+// Own performance handler - a staff member's own figures from the staff report
+func getMyPerformance(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, _, ok := middleware.GetUserFromContext(c)
+		if !ok {
+			c.JSON(401, gin.H{
+				"success": false,
+				"message": "Authentication required",
+				"error":   "auth_required",
+			})
+			return
+		}
+
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch performance")
+			return
+		}
+
+		staff, err := staffPerformance(db, period, userID.String())
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch performance",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		shifts, err := staffShifts(db, period, userID.String())
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch shifts",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		// No activity in the period is reported as zeros
+		var performance map[string]interface{}
+		if len(staff) > 0 {
+			performance = staff[0]
+		} else {
+			performance = map[string]interface{}{
+				"user_id":         userID.String(),
+				"order_count":     0,
+				"sales":           0.0,
+				"refunds":         0.0,
+				"average_ticket":  0.0,
+				"items":           0,
+				"items_per_order": 0.0,
+				"tips":            0.0,
+				"void_count":      0,
+				"void_amount":     0.0,
+				"comp_count":      0,
+				"comp_amount":     0.0,
+				"discount_count":  0,
+				"discount_amount": 0.0,
+				"payment_count":   0,
+				"payment_amount":  0.0,
+			}
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Performance retrieved successfully",
+			"data": map[string]interface{}{
+				"performance": performance,
+				"shifts":      shifts,
+				"period":      period.Name,
+				"range":       period.describe(),
+			},
+		})
+	}
+}
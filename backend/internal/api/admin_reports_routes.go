@@ -18,5 +18,12 @@ func SetupAdminReportsRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware
 		admin.GET("/reports/sales", getSalesReport(db))
 		admin.GET("/reports/orders", getOrdersReport(db))
 		admin.GET("/reports/income", getIncomeReport(db))
+		admin.GET("/reports/voids", getVoidCompReport(db))
+		admin.GET("/reports/discounts", getDiscountReport(db))
+		admin.GET("/reports/tips", getTipReport(db))
+		admin.GET("/reports/product-mix", getProductMixReport(db))
+		admin.GET("/reports/staff", getStaffReport(db))
+		admin.GET("/reports/kitchen", getKitchenReport(db))
+		admin.GET("/reports/order-volume", getOrderVolumeReport(db))
 	}
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 
+	"pos-backend/internal/gateway"
 	"pos-backend/internal/handlers"
 	"pos-backend/internal/middleware"
 	"pos-backend/internal/repository"
@@ -44,7 +45,7 @@ func SetupAdminRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.Ha
 		// Advanced order management
 		orderRepo := repository.NewPostgresOrderRepository(db)
 		orderHandler := handlers.NewOrderHandler(orderRepo)
-		paymentHandler := handlers.NewPaymentHandler(db)
+		paymentHandler := handlers.NewPaymentHandler(db, gateway.Default)
 		admin.POST("/orders", orderHandler.CreateOrder)                   // Admins can create any type of order
 		admin.POST("/orders/:id/payments", paymentHandler.ProcessPayment) // Admins can process payments
 	}
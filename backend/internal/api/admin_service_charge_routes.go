@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminServiceChargeRoutes configures automatic service charge rules for admin/manager roles
+func SetupAdminServiceChargeRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/service-charges", getServiceChargeRules(db))
+		admin.POST("/service-charges", createServiceChargeRule(db))
+		admin.PUT("/service-charges/:id", updateServiceChargeRule(db))
+		admin.DELETE("/service-charges/:id", deleteServiceChargeRule(db))
+	}
+}
+
+// Admin handler - List service charge rules
+func getServiceChargeRules(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := db.Query(`
+			SELECT id, name, rate, min_party_size, order_type, is_active, created_at, updated_at
+			FROM service_charge_rules
+			ORDER BY min_party_size ASC, name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch service charge rules",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		rules := []models.ServiceChargeRule{}
+		for rows.Next() {
+			var rule models.ServiceChargeRule
+			if err := rows.Scan(&rule.ID, &rule.Name, &rule.Rate, &rule.MinPartySize, &rule.OrderType,
+				&rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan service charge rule",
+					"error":   err.Error(),
+				})
+				return
+			}
+			rules = append(rules, rule)
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Service charge rules retrieved successfully",
+			"data":    rules,
+		})
+	}
+}
+
+// Admin handler - Create service charge rule
+func createServiceChargeRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name         string   `json:"name" binding:"required"`
+			Rate         *float64 `json:"rate" binding:"required"`
+			MinPartySize *int     `json:"min_party_size"`
+			OrderType    *string  `json:"order_type"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if *req.Rate <= 0 || *req.Rate >= 1 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Rate must be a fraction between 0 and 1 (e.g. 0.18 for 18%)",
+				"error":   "invalid_rate",
+			})
+			return
+		}
+
+		minPartySize := 1
+		if req.MinPartySize != nil {
+			minPartySize = *req.MinPartySize
+		}
+		if minPartySize < 1 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Minimum party size must be at least 1",
+				"error":   "invalid_party_size",
+			})
+			return
+		}
+
+		if req.OrderType != nil && !isValidOrderType(*req.OrderType) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid order type",
+				"error":   "invalid_order_type",
+			})
+			return
+		}
+
+		var ruleID string
+		err := db.QueryRow(`
+			INSERT INTO service_charge_rules (name, rate, min_party_size, order_type)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, req.Name, *req.Rate, minPartySize, req.OrderType).Scan(&ruleID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create service charge rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Service charge rule created successfully",
+			"data":    map[string]interface{}{"id": ruleID},
+		})
+	}
+}
+
+// Admin handler - Update service charge rule. Open orders pick up the change on their next edit.
+func updateServiceChargeRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID := c.Param("id")
+
+		var req struct {
+			Name         *string  `json:"name"`
+			Rate         *float64 `json:"rate"`
+			MinPartySize *int     `json:"min_party_size"`
+			OrderType    *string  `json:"order_type"` // empty string applies the rule to all order types
+			IsActive     *bool    `json:"is_active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.Rate != nil {
+			if *req.Rate <= 0 || *req.Rate >= 1 {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Rate must be a fraction between 0 and 1 (e.g. 0.18 for 18%)",
+					"error":   "invalid_rate",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("rate = $%d", argCount))
+			args = append(args, *req.Rate)
+			argCount++
+		}
+		if req.MinPartySize != nil {
+			if *req.MinPartySize < 1 {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Minimum party size must be at least 1",
+					"error":   "invalid_party_size",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("min_party_size = $%d", argCount))
+			args = append(args, *req.MinPartySize)
+			argCount++
+		}
+		if req.OrderType != nil {
+			if *req.OrderType == "" {
+				updates = append(updates, "order_type = NULL")
+			} else if !isValidOrderType(*req.OrderType) {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Invalid order type",
+					"error":   "invalid_order_type",
+				})
+				return
+			} else {
+				updates = append(updates, fmt.Sprintf("order_type = $%d", argCount))
+				args = append(args, *req.OrderType)
+				argCount++
+			}
+		}
+		if req.IsActive != nil {
+			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+			args = append(args, *req.IsActive)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, ruleID)
+
+		query := fmt.Sprintf(`
+			UPDATE service_charge_rules
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update service charge rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Service charge rule not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Service charge rule updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete service charge rule. Orders keep the charge already copied onto them.
+func deleteServiceChargeRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID := c.Param("id")
+
+		result, err := db.Exec("DELETE FROM service_charge_rules WHERE id = $1", ruleID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete service charge rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Service charge rule not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Service charge rule deleted successfully",
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminSettingsRoutes configures store-wide settings. Every signed-in role can read them
+// so tills can apply them; only admin/manager roles can change them.
+func SetupAdminSettingsRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	protected := router.Group("/")
+	protected.Use(authMiddleware)
+	{
+		protected.GET("/settings", getStoreSettings(db))
+	}
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/settings", getStoreSettings(db))
+		admin.PUT("/settings", updateStoreSettings(db))
+	}
+}
+
+// getStoreSettings returns the store-wide settings
+func getStoreSettings(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := repository.GetStoreSettings(c.Request.Context(), db)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch store settings",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Store settings retrieved successfully",
+			"data":    settings,
+		})
+	}
+}
+
+// updateStoreSettings changes store-wide settings
+func updateStoreSettings(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			CashRounding *float64 `json:"cash_rounding"`
+			TimeZone     *string  `json:"time_zone"`
+			DayCutoff    *string  `json:"day_cutoff"` // HH:MM
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.CashRounding != nil {
+			// Must be a whole number of cents, e.g. 0.05 or 1.00
+			cents := *req.CashRounding * 100
+			if cents < 1 || cents > 10000 || math.Abs(cents-math.Round(cents)) > 0.001 {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Cash rounding must be a coin value between 0.01 and 100.00",
+					"error":   "invalid_cash_rounding",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("cash_rounding = $%d", argCount))
+			args = append(args, *req.CashRounding)
+			argCount++
+		}
+
+		if req.TimeZone != nil {
+			valid, err := repository.IsValidTimeZone(c.Request.Context(), db, *req.TimeZone)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to check time zone",
+					"error":   err.Error(),
+				})
+				return
+			}
+			if !valid {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Unknown time zone; use an IANA name such as America/New_York",
+					"error":   "invalid_time_zone",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("time_zone = $%d", argCount))
+			args = append(args, *req.TimeZone)
+			argCount++
+		}
+
+		if req.DayCutoff != nil {
+			if _, err := time.Parse("15:04", *req.DayCutoff); err != nil {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Day cutoff must be a time in HH:MM format",
+					"error":   "invalid_day_cutoff",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("day_cutoff = $%d", argCount))
+			args = append(args, *req.DayCutoff)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		// The settings row is created on first save if the seed did not add it
+		if _, err := db.Exec("INSERT INTO store_settings (id) VALUES (true) ON CONFLICT (id) DO NOTHING"); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update store settings",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		query := fmt.Sprintf("UPDATE store_settings SET %s WHERE id = true", strings.Join(updates, ", "))
+		if _, err := db.Exec(query, args...); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update store settings",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		settings, err := repository.GetStoreSettings(c.Request.Context(), db)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Store settings updated but failed to fetch them",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Store settings updated successfully",
+			"data":    settings,
+		})
+	}
+}
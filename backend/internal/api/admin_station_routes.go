@@ -0,0 +1,249 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminStationRoutes configures kitchen station management for admin/manager roles
+func SetupAdminStationRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/kitchen-stations", getKitchenStations(db))
+		admin.POST("/kitchen-stations", createKitchenStation(db))
+		admin.PUT("/kitchen-stations/:id", updateKitchenStation(db))
+		admin.DELETE("/kitchen-stations/:id", deleteKitchenStation(db))
+	}
+}
+
+// getKitchenStations lists kitchen stations; active_only=true hides disabled ones
+func getKitchenStations(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := `
+			SELECT id, name, description, sort_order, is_active, created_at, updated_at
+			FROM kitchen_stations
+		`
+		if c.Query("active_only") == "true" {
+			query += " WHERE is_active = true"
+		}
+		query += " ORDER BY sort_order ASC, name ASC"
+
+		rows, err := db.Query(query)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch kitchen stations",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		stations := []models.KitchenStation{}
+		for rows.Next() {
+			var station models.KitchenStation
+			if err := rows.Scan(&station.ID, &station.Name, &station.Description, &station.SortOrder,
+				&station.IsActive, &station.CreatedAt, &station.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan kitchen station",
+					"error":   err.Error(),
+				})
+				return
+			}
+			stations = append(stations, station)
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Kitchen stations retrieved successfully",
+			"data":    stations,
+		})
+	}
+}
+
+// Admin handler - Create kitchen station
+func createKitchenStation(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name        string  `json:"name" binding:"required"`
+			Description *string `json:"description"`
+			SortOrder   int     `json:"sort_order"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		var stationID string
+		err := db.QueryRow(`
+			INSERT INTO kitchen_stations (name, description, sort_order)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, req.Name, req.Description, req.SortOrder).Scan(&stationID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create kitchen station",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Kitchen station created successfully",
+			"data":    map[string]interface{}{"id": stationID},
+		})
+	}
+}
+
+// Admin handler - Update kitchen station
+func updateKitchenStation(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stationID := c.Param("id")
+
+		var req struct {
+			Name        *string `json:"name"`
+			Description *string `json:"description"`
+			SortOrder   *int    `json:"sort_order"`
+			IsActive    *bool   `json:"is_active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.Description != nil {
+			updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+			args = append(args, req.Description)
+			argCount++
+		}
+		if req.SortOrder != nil {
+			updates = append(updates, fmt.Sprintf("sort_order = $%d", argCount))
+			args = append(args, *req.SortOrder)
+			argCount++
+		}
+		if req.IsActive != nil {
+			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+			args = append(args, *req.IsActive)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, stationID)
+
+		query := fmt.Sprintf(`
+			UPDATE kitchen_stations
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update kitchen station",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Kitchen station not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Kitchen station updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete kitchen station
+func deleteKitchenStation(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stationID := c.Param("id")
+
+		// Refuse while categories or products are still routed to the station
+		var assignedCount int
+		db.QueryRow(`
+			SELECT (SELECT COUNT(*) FROM categories WHERE station_id = $1) +
+			       (SELECT COUNT(*) FROM products WHERE station_id = $1)
+		`, stationID).Scan(&assignedCount)
+
+		if assignedCount > 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Cannot delete kitchen station assigned to categories or products",
+				"error":   "station_in_use",
+			})
+			return
+		}
+
+		result, err := db.Exec("DELETE FROM kitchen_stations WHERE id = $1", stationID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete kitchen station",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Kitchen station not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Kitchen station deleted successfully",
+		})
+	}
+}
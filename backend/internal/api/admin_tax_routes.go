@@ -0,0 +1,553 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminTaxRoutes configures tax class and tax rule management for admin/manager roles
+func SetupAdminTaxRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/tax-classes", getTaxClasses(db))
+		admin.POST("/tax-classes", createTaxClass(db))
+		admin.PUT("/tax-classes/:id", updateTaxClass(db))
+		admin.DELETE("/tax-classes/:id", deleteTaxClass(db))
+		admin.POST("/tax-classes/:id/rules", createTaxRule(db))
+		admin.PUT("/tax-rules/:id", updateTaxRule(db))
+		admin.DELETE("/tax-rules/:id", deleteTaxRule(db))
+	}
+}
+
+// Admin handler - List tax classes with their rules
+func getTaxClasses(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := db.Query(`
+			SELECT id, name, description, is_default, created_at, updated_at
+			FROM tax_classes
+			ORDER BY is_default DESC, name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch tax classes",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer rows.Close()
+
+		classes := []models.TaxClass{}
+		index := map[string]int{}
+		for rows.Next() {
+			var class models.TaxClass
+			if err := rows.Scan(&class.ID, &class.Name, &class.Description, &class.IsDefault,
+				&class.CreatedAt, &class.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan tax class",
+					"error":   err.Error(),
+				})
+				return
+			}
+			class.Rules = []models.TaxRule{}
+			index[class.ID.String()] = len(classes)
+			classes = append(classes, class)
+		}
+
+		ruleRows, err := db.Query(`
+			SELECT id, tax_class_id, name, rate, order_type, is_inclusive, is_active, created_at, updated_at
+			FROM tax_rules
+			ORDER BY name ASC
+		`)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch tax rules",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer ruleRows.Close()
+
+		for ruleRows.Next() {
+			var rule models.TaxRule
+			if err := ruleRows.Scan(&rule.ID, &rule.TaxClassID, &rule.Name, &rule.Rate, &rule.OrderType,
+				&rule.IsInclusive, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to scan tax rule",
+					"error":   err.Error(),
+				})
+				return
+			}
+			if i, ok := index[rule.TaxClassID.String()]; ok {
+				classes[i].Rules = append(classes[i].Rules, rule)
+			}
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tax classes retrieved successfully",
+			"data":    classes,
+		})
+	}
+}
+
+// Admin handler - Create tax class
+func createTaxClass(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name        string  `json:"name" binding:"required"`
+			Description *string `json:"description"`
+			IsDefault   bool    `json:"is_default"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to start transaction",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback()
+
+		// Only one class can be the default
+		if req.IsDefault {
+			if _, err := tx.Exec("UPDATE tax_classes SET is_default = false WHERE is_default = true"); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to reset default tax class",
+					"error":   err.Error(),
+				})
+				return
+			}
+		}
+
+		var classID string
+		if err := tx.QueryRow(`
+			INSERT INTO tax_classes (name, description, is_default)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, req.Name, req.Description, req.IsDefault).Scan(&classID); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create tax class",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create tax class",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Tax class created successfully",
+			"data":    map[string]interface{}{"id": classID},
+		})
+	}
+}
+
+// Admin handler - Update tax class
+func updateTaxClass(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		classID := c.Param("id")
+
+		var req struct {
+			Name        *string `json:"name"`
+			Description *string `json:"description"`
+			IsDefault   *bool   `json:"is_default"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.Description != nil {
+			updates = append(updates, fmt.Sprintf("description = $%d", argCount))
+			args = append(args, req.Description)
+			argCount++
+		}
+		if req.IsDefault != nil {
+			updates = append(updates, fmt.Sprintf("is_default = $%d", argCount))
+			args = append(args, *req.IsDefault)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, classID)
+
+		tx, err := db.Begin()
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to start transaction",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer tx.Rollback()
+
+		if req.IsDefault != nil && *req.IsDefault {
+			if _, err := tx.Exec("UPDATE tax_classes SET is_default = false WHERE is_default = true AND id <> $1", classID); err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to reset default tax class",
+					"error":   err.Error(),
+				})
+				return
+			}
+		}
+
+		query := fmt.Sprintf(`
+			UPDATE tax_classes
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update tax class",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Tax class not found",
+			})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update tax class",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tax class updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete tax class
+func deleteTaxClass(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		classID := c.Param("id")
+
+		// Refuse while categories or products still point at the class
+		var assignedCount int
+		db.QueryRow(`
+			SELECT (SELECT COUNT(*) FROM categories WHERE tax_class_id = $1) +
+			       (SELECT COUNT(*) FROM products WHERE tax_class_id = $1)
+		`, classID).Scan(&assignedCount)
+
+		if assignedCount > 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Cannot delete tax class assigned to categories or products",
+				"error":   "tax_class_in_use",
+			})
+			return
+		}
+
+		result, err := db.Exec("DELETE FROM tax_classes WHERE id = $1", classID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete tax class",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Tax class not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tax class deleted successfully",
+		})
+	}
+}
+
+// Admin handler - Create tax rule within a tax class
+func createTaxRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		classID := c.Param("id")
+
+		var req struct {
+			Name        string   `json:"name" binding:"required"`
+			Rate        *float64 `json:"rate" binding:"required"`
+			OrderType   *string  `json:"order_type"`
+			IsInclusive bool     `json:"is_inclusive"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		if *req.Rate < 0 || *req.Rate >= 1 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Rate must be a fraction between 0 and 1 (e.g. 0.08 for 8%)",
+				"error":   "invalid_rate",
+			})
+			return
+		}
+
+		if req.OrderType != nil && !isValidOrderType(*req.OrderType) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid order type",
+				"error":   "invalid_order_type",
+			})
+			return
+		}
+
+		var ruleID string
+		err := db.QueryRow(`
+			INSERT INTO tax_rules (tax_class_id, name, rate, order_type, is_inclusive)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, classID, req.Name, *req.Rate, req.OrderType, req.IsInclusive).Scan(&ruleID)
+
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to create tax rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(201, gin.H{
+			"success": true,
+			"message": "Tax rule created successfully",
+			"data":    map[string]interface{}{"id": ruleID},
+		})
+	}
+}
+
+// Admin handler - Update tax rule
+func updateTaxRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID := c.Param("id")
+
+		var req struct {
+			Name        *string  `json:"name"`
+			Rate        *float64 `json:"rate"`
+			OrderType   *string  `json:"order_type"` // empty string applies the rule to all order types
+			IsInclusive *bool    `json:"is_inclusive"`
+			IsActive    *bool    `json:"is_active"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		updates := []string{}
+		args := []interface{}{}
+		argCount := 1
+
+		if req.Name != nil {
+			updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+			args = append(args, *req.Name)
+			argCount++
+		}
+		if req.Rate != nil {
+			if *req.Rate < 0 || *req.Rate >= 1 {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Rate must be a fraction between 0 and 1 (e.g. 0.08 for 8%)",
+					"error":   "invalid_rate",
+				})
+				return
+			}
+			updates = append(updates, fmt.Sprintf("rate = $%d", argCount))
+			args = append(args, *req.Rate)
+			argCount++
+		}
+		if req.OrderType != nil {
+			if *req.OrderType == "" {
+				updates = append(updates, "order_type = NULL")
+			} else if !isValidOrderType(*req.OrderType) {
+				c.JSON(400, gin.H{
+					"success": false,
+					"message": "Invalid order type",
+					"error":   "invalid_order_type",
+				})
+				return
+			} else {
+				updates = append(updates, fmt.Sprintf("order_type = $%d", argCount))
+				args = append(args, *req.OrderType)
+				argCount++
+			}
+		}
+		if req.IsInclusive != nil {
+			updates = append(updates, fmt.Sprintf("is_inclusive = $%d", argCount))
+			args = append(args, *req.IsInclusive)
+			argCount++
+		}
+		if req.IsActive != nil {
+			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+			args = append(args, *req.IsActive)
+			argCount++
+		}
+
+		if len(updates) == 0 {
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "No fields to update",
+			})
+			return
+		}
+
+		updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+		args = append(args, ruleID)
+
+		query := fmt.Sprintf(`
+			UPDATE tax_rules
+			SET %s
+			WHERE id = $%d
+		`, strings.Join(updates, ", "), argCount)
+
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to update tax rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Tax rule not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tax rule updated successfully",
+		})
+	}
+}
+
+// Admin handler - Delete tax rule
+func deleteTaxRule(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ruleID := c.Param("id")
+
+		result, err := db.Exec("DELETE FROM tax_rules WHERE id = $1", ruleID)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to delete tax rule",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			c.JSON(404, gin.H{
+				"success": false,
+				"message": "Tax rule not found",
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Tax rule deleted successfully",
+		})
+	}
+}
+
+// isValidOrderType reports whether orderType is one of the supported order types
+func isValidOrderType(orderType string) bool {
+	switch orderType {
+	case "dine_in", "takeout", "delivery":
+		return true
+	}
+	return false
+}
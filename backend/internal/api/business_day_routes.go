@@ -0,0 +1,27 @@
+package api
+
+import (
+	"database/sql"
+
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBusinessDayRoutes configures X reports and the Z report close of the business day
+// for admin/manager roles
+func SetupBusinessDayRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	businessDayHandler := handlers.NewBusinessDayHandler(repository.NewPostgresBusinessDayRepository(db))
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/reports/x", businessDayHandler.GetXReport)
+		admin.POST("/business-days/close", businessDayHandler.CloseBusinessDay)
+		admin.GET("/business-days", businessDayHandler.GetZReports)
+		admin.GET("/business-days/:id", businessDayHandler.GetZReport)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 
+	"pos-backend/internal/gateway"
 	"pos-backend/internal/handlers"
 	"pos-backend/internal/middleware"
 	"pos-backend/internal/repository"
@@ -18,7 +19,7 @@ func SetupCounterRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.
 	{
 		orderRepo := repository.NewPostgresOrderRepository(db)
 		orderHandler := handlers.NewOrderHandler(orderRepo)
-		paymentHandler := handlers.NewPaymentHandler(db)
+		paymentHandler := handlers.NewPaymentHandler(db, gateway.Default)
 
 		counter.POST("/orders", orderHandler.CreateOrder)
 		counter.POST("/orders/:id/payments", paymentHandler.ProcessPayment)
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"database/sql"
+
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDrawerRoutes configures cash drawer sessions for the roles that take cash, and
+// over/short review for admin/manager roles
+func SetupDrawerRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	drawerHandler := handlers.NewDrawerHandler(repository.NewPostgresDrawerRepository(db))
+
+	cashiers := router.Group("/drawers")
+	cashiers.Use(authMiddleware)
+	cashiers.Use(middleware.RequireRoles([]string{"admin", "manager", "counter"}))
+	{
+		cashiers.POST("/sessions", drawerHandler.OpenDrawer)
+		cashiers.GET("/sessions/current", drawerHandler.GetCurrentDrawer)
+		cashiers.POST("/sessions/:id/movements", drawerHandler.RecordDrawerMovement)
+		cashiers.POST("/sessions/:id/close", drawerHandler.CloseDrawer)
+	}
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/drawer-sessions", drawerHandler.GetDrawerSessions)
+		admin.GET("/drawer-sessions/:id/report", drawerHandler.GetDrawerReport)
+		admin.POST("/drawer-sessions/:id/review", drawerHandler.ReviewDrawer)
+	}
+}
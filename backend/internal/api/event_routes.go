@@ -0,0 +1,22 @@
+package api
+
+import (
+	"database/sql"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupEventRoutes configures the real-time order event stream for all staff roles
+func SetupEventRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	eventHandler := handlers.NewEventHandler(events.Default)
+
+	stream := router.Group("/events")
+	{
+		stream.POST("/token", authMiddleware, eventHandler.IssueStreamToken)
+		stream.GET("/stream", middleware.StreamTokenAuth(authMiddleware), eventHandler.StreamEvents)
+	}
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"database/sql"
+
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupIngredientRoutes configures ingredient stock, recipes and the usage report for admin/manager roles
+func SetupIngredientRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	ingredientRepo := repository.NewPostgresIngredientRepository(db)
+	ingredientHandler := handlers.NewIngredientHandler(ingredientRepo)
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/ingredients", ingredientHandler.GetIngredients)
+		admin.POST("/ingredients", ingredientHandler.CreateIngredient)
+		admin.PUT("/ingredients/:id", ingredientHandler.UpdateIngredient)
+		admin.DELETE("/ingredients/:id", ingredientHandler.DeleteIngredient)
+		admin.POST("/ingredients/:id/stock", ingredientHandler.RecordIngredientStock)
+		admin.GET("/ingredients/:id/movements", ingredientHandler.GetIngredientMovements)
+		admin.GET("/products/:id/recipe", ingredientHandler.GetProductRecipe)
+		admin.PUT("/products/:id/recipe", ingredientHandler.SetProductRecipe)
+		admin.GET("/modifiers/:id/recipe", ingredientHandler.GetModifierRecipe)
+		admin.PUT("/modifiers/:id/recipe", ingredientHandler.SetModifierRecipe)
+		admin.GET("/reports/ingredient-usage", ingredientHandler.GetIngredientUsageReport)
+	}
+}
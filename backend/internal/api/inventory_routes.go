@@ -0,0 +1,30 @@
+package api
+
+import (
+	"database/sql"
+
+	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInventoryRoutes configures stock level management and the movement ledger for admin/manager roles
+func SetupInventoryRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	inventoryRepo := repository.NewPostgresInventoryRepository(db)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryRepo)
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequireRoles([]string{"admin", "manager"}))
+	{
+		admin.GET("/inventory", inventoryHandler.GetInventory)
+		admin.GET("/inventory/movements", inventoryHandler.GetInventoryMovements)
+		admin.GET("/inventory/:product_id", inventoryHandler.GetProductInventory)
+		admin.PUT("/inventory/:product_id", inventoryHandler.UpsertInventory)
+		admin.DELETE("/inventory/:product_id", inventoryHandler.DeleteInventory)
+		admin.POST("/inventory/:product_id/restock", inventoryHandler.RestockInventory)
+		admin.POST("/inventory/:product_id/adjust", inventoryHandler.AdjustInventory)
+	}
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// kitchenItemTimes selects, as t, the items fired in a period that the kitchen finished,
+// with minutes from being fired to being ready, wait_minutes from being fired to being
+// started (NULL when an item went straight to ready) and the product's preparation_time as
+// expected (NULL when it is not set). Queries using it take $1 start and $2 end.
+func kitchenItemTimes(period *reportPeriod) string {
+	return `
+		WITH t AS (
+			SELECT oi.order_id, oi.product_id, oi.station_id, oi.fired_at, oi.ready_at,
+				(EXTRACT(EPOCH FROM (oi.ready_at - oi.fired_at)) / 60)::float8 as minutes,
+				(EXTRACT(EPOCH FROM (oi.preparing_at - oi.fired_at)) / 60)::float8 as wait_minutes,
+				NULLIF(p.preparation_time, 0)::float8 as expected
+			FROM order_items oi
+			JOIN products p ON oi.product_id = p.id
+			WHERE ` + period.within("oi.fired_at") + `
+				AND oi.status IN ('ready', 'served') AND oi.ready_at >= oi.fired_at
+				AND oi.adjustment IS DISTINCT FROM 'void'
+		)`
+}
+
+// kitchenTimeStats aggregates t into the fields kitchenTiming is scanned from. The sorted
+// minutes come back whole so percentiles are worked out in Go.
+const kitchenTimeStats = `COUNT(*),
+				COALESCE(AVG(t.minutes), 0),
+				array_agg(t.minutes ORDER BY t.minutes),
+				COALESCE(MAX(t.minutes), 0),
+				AVG(t.wait_minutes),
+				AVG(t.expected),
+				AVG(t.minutes - t.expected),
+				COUNT(t.expected),
+				COUNT(*) FILTER (WHERE t.minutes <= t.expected)`
+
+// kitchenTiming is how long the kitchen took over a set of items, in minutes from an item
+// being fired to it being ready. The comparisons with preparation_time only cover items
+// whose product has one.
+type kitchenTiming struct {
+	Items           int      `json:"items"`
+	AvgMinutes      float64  `json:"avg_minutes"`
+	P90Minutes      float64  `json:"p90_minutes"`
+	MaxMinutes      float64  `json:"max_minutes"`
+	AvgWaitMinutes  *float64 `json:"avg_wait_minutes"` // fired until the kitchen started the item
+	ExpectedMinutes *float64 `json:"expected_minutes"` // average preparation_time
+	OverMinutes     *float64 `json:"over_minutes"`     // average time over preparation_time; negative when faster
+	OnTimePercent   *float64 `json:"on_time_percent"`  // items ready within preparation_time
+}
+
+// kitchenTimingGroup is the timing of one product, station, hour or business day
+type kitchenTimingGroup struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	kitchenTiming
+}
+
+// slowTicket is one station's part of an order fired at the same time, timed until its
+// last item was ready
+type slowTicket struct {
+	OrderID         string    `json:"order_id"`
+	OrderNumber     string    `json:"order_number"`
+	TableNumber     *string   `json:"table_number"`
+	StationName     string    `json:"station_name"`
+	FiredAt         time.Time `json:"fired_at"`
+	ReadyAt         time.Time `json:"ready_at"`
+	Items           int       `json:"items"`
+	Products        string    `json:"products"`
+	Minutes         float64   `json:"minutes"`
+	ExpectedMinutes *float64  `json:"expected_minutes"` // the slowest preparation_time on the ticket
+	OverMinutes     *float64  `json:"over_minutes"`
+}
+
+// scanKitchenTiming reads the kitchenTimeStats columns after the given leading columns
+func scanKitchenTiming(row interface{ Scan(...interface{}) error }, lead ...interface{}) (kitchenTiming, error) {
+	var timing kitchenTiming
+	var minutes []float64
+	var wait, expected, over sql.NullFloat64
+	var withExpected, onTime int
+
+	dest := append(lead, &timing.Items, &timing.AvgMinutes, pq.Array(&minutes), &timing.MaxMinutes,
+		&wait, &expected, &over, &withExpected, &onTime)
+	if err := row.Scan(dest...); err != nil {
+		return timing, err
+	}
+
+	timing.AvgMinutes = roundMinutes(timing.AvgMinutes)
+	timing.P90Minutes = roundMinutes(percentile(minutes, 0.9))
+	timing.MaxMinutes = roundMinutes(timing.MaxMinutes)
+	timing.AvgWaitMinutes = roundNullMinutes(wait)
+	timing.ExpectedMinutes = roundNullMinutes(expected)
+	timing.OverMinutes = roundNullMinutes(over)
+	timing.OnTimePercent = percentOf(float64(onTime), float64(withExpected))
+	return timing, nil
+}
+
+// kitchenTimingGroups times the period's items grouped by key, labelled with name
+func kitchenTimingGroups(db *sql.DB, period *reportPeriod, key, name, joins, orderBy string) ([]kitchenTimingGroup, error) {
+	rows, err := db.Query(kitchenItemTimes(period)+`
+		SELECT `+key+`, `+name+`, `+kitchenTimeStats+`
+		FROM t`+joins+`
+		GROUP BY 1, 2
+		ORDER BY `+orderBy, period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []kitchenTimingGroup{}
+	for rows.Next() {
+		var group kitchenTimingGroup
+		if group.kitchenTiming, err = scanKitchenTiming(rows, &group.Key, &group.Name); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// slowestTickets lists the station tickets in the period that took longest
+func slowestTickets(db *sql.DB, period *reportPeriod, limit int) ([]slowTicket, error) {
+	rows, err := db.Query(kitchenItemTimes(period)+`
+		SELECT o.id::text, o.order_number, dt.table_number, COALESCE(s.name, 'No station'),
+			t.fired_at, MAX(t.ready_at), COUNT(*), string_agg(p.name, ', ' ORDER BY p.name),
+			MAX(t.minutes), MAX(t.expected)
+		FROM t
+		JOIN orders o ON t.order_id = o.id
+		JOIN products p ON t.product_id = p.id
+		LEFT JOIN dining_tables dt ON o.table_id = dt.id
+		LEFT JOIN kitchen_stations s ON t.station_id = s.id
+		GROUP BY o.id, o.order_number, dt.table_number, t.station_id, s.name, t.fired_at
+		ORDER BY MAX(t.minutes) DESC
+		LIMIT $3
+	`, period.Start, period.End, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []slowTicket{}
+	for rows.Next() {
+		var ticket slowTicket
+		var tableNumber sql.NullString
+		var expected sql.NullFloat64
+		if err := rows.Scan(&ticket.OrderID, &ticket.OrderNumber, &tableNumber, &ticket.StationName,
+			&ticket.FiredAt, &ticket.ReadyAt, &ticket.Items, &ticket.Products,
+			&ticket.Minutes, &expected); err != nil {
+			return nil, err
+		}
+		if tableNumber.Valid {
+			ticket.TableNumber = &tableNumber.String
+		}
+		ticket.Minutes = roundMinutes(ticket.Minutes)
+		if expected.Valid {
+			over := roundMinutes(ticket.Minutes - expected.Float64)
+			ticket.ExpectedMinutes = &expected.Float64
+			ticket.OverMinutes = &over
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
+
+// Kitchen performance report handler - how long items take from being fired to being ready
+// per product, station, hour of the day and business day, against each product's
+// preparation_time, with the slowest tickets. Items finished before item timestamps were
+// recorded are left out.
+func getKitchenReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := parseReportPeriod(c, db)
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch kitchen report")
+			return
+		}
+
+		limit := 10
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
+				limit = l
+			}
+		}
+
+		summary, err := scanKitchenTiming(db.QueryRow(kitchenItemTimes(period)+`
+			SELECT `+kitchenTimeStats+`
+			FROM t
+		`, period.Start, period.End))
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch kitchen report",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		days := *period
+		days.GroupBy = "day"
+		groupings := []struct {
+			name                     string
+			key, label, joins, order string
+		}{
+			{"products", "p.id::text", "p.name", `
+		JOIN products p ON t.product_id = p.id`, "AVG(t.minutes - t.expected) DESC NULLS LAST, AVG(t.minutes) DESC"},
+			{"stations", "COALESCE(s.id::text, '')", "COALESCE(s.name, 'No station')", `
+		LEFT JOIN kitchen_stations s ON t.station_id = s.id`, "AVG(t.minutes) DESC"},
+			{"hours", period.hourOfDay("t.fired_at"), period.hourOfDay("t.fired_at"), "", "1"},
+			{"days", days.bucket("t.fired_at"), days.bucket("t.fired_at"), "", "1 DESC"},
+		}
+
+		result := map[string]interface{}{
+			"summary": summary,
+			"period":  period.Name,
+			"range":   period.describe(),
+		}
+		for _, grouping := range groupings {
+			groups, err := kitchenTimingGroups(db, period, grouping.key, grouping.label, grouping.joins, grouping.order)
+			if err != nil {
+				c.JSON(500, gin.H{
+					"success": false,
+					"message": "Failed to fetch kitchen " + grouping.name,
+					"error":   err.Error(),
+				})
+				return
+			}
+			result[grouping.name] = groups
+		}
+
+		tickets, err := slowestTickets(db, period, limit)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch slowest tickets",
+				"error":   err.Error(),
+			})
+			return
+		}
+		result["slowest_tickets"] = tickets
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Kitchen report retrieved successfully",
+			"data":    result,
+		})
+	}
+}
+
+// percentile is the p-th percentile (0 to 1) of sorted values, interpolating between the
+// nearest two the way PostgreSQL's percentile_cont does. It is 0 when there are no values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(position-float64(lower))
+}
+
+// roundMinutes rounds a time in minutes to one decimal place
+func roundMinutes(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
+
+func roundNullMinutes(minutes sql.NullFloat64) *float64 {
+	if !minutes.Valid {
+		return nil
+	}
+	rounded := roundMinutes(minutes.Float64)
+	return &rounded
+}
@@ -0,0 +1,35 @@
+package api
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"no items", nil, 0.9, 0},
+		{"no items p50", []float64{}, 0.5, 0},
+		{"one item p50", []float64{7}, 0.5, 7},
+		{"one item p90", []float64{7}, 0.9, 7},
+		{"two items p50", []float64{4, 10}, 0.5, 7},
+		{"two items p90", []float64{4, 10}, 0.9, 9.4},
+		{"four items p50", []float64{2, 4, 6, 20}, 0.5, 5},
+		{"four items p90", []float64{2, 4, 6, 20}, 0.9, 15.8},
+		{"odd count p50 is the middle", []float64{1, 3, 8}, 0.5, 3},
+		{"p100 is the slowest", []float64{1, 3, 8}, 1, 8},
+		{"p0 is the fastest", []float64{1, 3, 8}, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); !approxMinutes(got, tt.want) {
+			t.Errorf("%s: percentile = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// approxMinutes compares unrounded minutes
+func approxMinutes(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
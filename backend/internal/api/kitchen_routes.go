@@ -1,28 +1,82 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
+	"pos-backend/internal/handlers"
 	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 // SetupKitchenRoutes configures endpoints for kitchen staff
 func SetupKitchenRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	kitchenRepo := repository.NewPostgresKitchenRepository(db)
+	kitchenHandler := handlers.NewKitchenHandler(kitchenRepo)
+
 	kitchen := router.Group("/kitchen")
 	kitchen.Use(authMiddleware)
 	kitchen.Use(middleware.RequireRoles([]string{"kitchen", "admin", "manager"}))
 	{
 		kitchen.GET("/orders", getKitchenOrders(db))
-		kitchen.PATCH("/orders/:id/items/:item_id/status", updateOrderItemStatus(db))
+		kitchen.PATCH("/orders/:id/items/:item_id/status", kitchenHandler.UpdateItemStatus)
+		kitchen.GET("/stations", getKitchenStations(db))
+		kitchen.GET("/stations/:id/tickets", kitchenHandler.GetStationTickets)
+		kitchen.POST("/stations/:id/tickets/:order_id/bump", kitchenHandler.BumpStationTicket)
 	}
 }
 
-// getKitchenOrders retrieves active orders for kitchen processing
+// getKitchenOrders retrieves active orders for kitchen processing. Held items stay
+// hidden until their course is fired; orders with nothing fired yet are left out.
 func getKitchenOrders(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		status := c.DefaultQuery("status", "all")
+		switch status {
+		case "all", repository.OrderStatusPending, repository.OrderStatusConfirmed,
+			repository.OrderStatusPreparing, repository.OrderStatusReady:
+		default:
+			c.JSON(400, gin.H{
+				"success": false,
+				"message": "status must be all, pending, confirmed, preparing or ready",
+				"error":   "invalid_status",
+			})
+			return
+		}
+
+		// Today is the current business day in the store's time zone
+		ctx := c.Request.Context()
+		settings, err := repository.GetStoreSettings(ctx, db)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch store settings",
+				"error":   err.Error(),
+			})
+			return
+		}
+		today, err := repository.CurrentBusinessDate(ctx, db, settings)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch kitchen orders",
+				"error":   err.Error(),
+			})
+			return
+		}
+		start, end, err := repository.BusinessDayBounds(settings, today, today)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch kitchen orders",
+				"error":   err.Error(),
+			})
+			return
+		}
 
 		query := `
 			SELECT DISTINCT o.id::text, o.order_number, o.table_id::text, o.order_type, o.status, 
@@ -30,17 +84,19 @@ func getKitchenOrders(db *sql.DB) gin.HandlerFunc {
 		       t.table_number
 			FROM orders o
 			LEFT JOIN dining_tables t ON o.table_id = t.id
-			WHERE o.status IN ('pending', 'confirmed', 'preparing', 'ready') AND o.created_at::date = CURRENT_DATE
+			WHERE o.status IN ('pending', 'confirmed', 'preparing', 'ready') AND o.created_at >= $1 AND o.created_at < $2
+			  AND EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.is_held = false)
 		`
-		// WHERE o.status IN ('pending', 'confirmed', 'preparing', 'ready') AND o.created_at::date = CURRENT_DATE
+		args := []interface{}{start, end}
 
 		if status != "all" {
-			query += ` AND o.status = '` + status + `'`
+			args = append(args, status)
+			query += ` AND o.status = $3`
 		}
 
 		query += ` ORDER BY o.created_at ASC`
 
-		rows, err := db.Query(query)
+		rows, err := db.Query(query, args...)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"success": false,
@@ -52,6 +108,7 @@ func getKitchenOrders(db *sql.DB) gin.HandlerFunc {
 		defer rows.Close()
 
 		var orders []map[string]interface{}
+		var orderIDs []string
 		for rows.Next() {
 			var orderID, tableID interface{}
 			var orderNumber, orderType, orderStatus, customerName, tableNumber sql.NullString
@@ -80,6 +137,31 @@ func getKitchenOrders(db *sql.DB) gin.HandlerFunc {
 			}
 
 			orders = append(orders, order)
+			if id, ok := orderID.(string); ok {
+				orderIDs = append(orderIDs, id)
+			}
+		}
+		rows.Close()
+
+		items, err := loadKitchenItems(ctx, db, orderIDs)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch kitchen order items",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		now := time.Now()
+		for _, order := range orders {
+			id, _ := order["id"].(string)
+			orderItems := items[id]
+			if orderItems == nil {
+				orderItems = []models.OrderItem{}
+			}
+			order["items"] = orderItems
+			order["courses"] = repository.CourseTimings(orderItems, now)
 		}
 
 		c.JSON(200, gin.H{
@@ -90,44 +172,59 @@ func getKitchenOrders(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-// updateOrderItemStatus updates status of an order item
-func updateOrderItemStatus(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		orderID := c.Param("id")
-		itemID := c.Param("item_id")
-
-		var req struct {
-			Status string `json:"status"`
-		}
+// loadKitchenItems returns the fired, non-voided items of the given orders with their
+// modifiers, keyed by order ID
+func loadKitchenItems(ctx context.Context, db *sql.DB, orderIDs []string) (map[string][]models.OrderItem, error) {
+	items := map[string][]models.OrderItem{}
+	if len(orderIDs) == 0 {
+		return items, nil
+	}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{
-				"success": false,
-				"message": "Invalid request body",
-				"error":   err.Error(),
-			})
-			return
+	rows, err := db.QueryContext(ctx, `
+		SELECT oi.id, oi.order_id, oi.product_id, oi.quantity, oi.special_instructions, oi.status,
+		       oi.station_id, oi.course, oi.fired_at, oi.created_at, oi.updated_at,
+		       p.name, p.preparation_time
+		FROM order_items oi
+		JOIN products p ON oi.product_id = p.id
+		WHERE oi.order_id::text = ANY($1)
+		  AND oi.is_held = false
+		  AND oi.adjustment IS DISTINCT FROM 'void'
+		ORDER BY oi.course, oi.created_at
+	`, pq.Array(orderIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.OrderItem
+		var product models.Product
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.SpecialInstructions, &item.Status,
+			&item.StationID, &item.Course, &item.FiredAt, &item.CreatedAt, &item.UpdatedAt,
+			&product.Name, &product.PreparationTime); err != nil {
+			return nil, err
 		}
+		product.ID = item.ProductID
+		item.Product = &product
+		key := item.OrderID.String()
+		items[key] = append(items[key], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
-		// Update order item status
-		_, err := db.Exec(`
-			UPDATE order_items 
-			SET status = $1, updated_at = CURRENT_TIMESTAMP 
-			WHERE id = $2 AND order_id = $3
-		`, req.Status, itemID, orderID)
-
-		if err != nil {
-			c.JSON(500, gin.H{
-				"success": false,
-				"message": "Failed to update order item status",
-				"error":   err.Error(),
-			})
-			return
+	var refs []*models.OrderItem
+	for key := range items {
+		for i := range items[key] {
+			refs = append(refs, &items[key][i])
 		}
-
-		c.JSON(200, gin.H{
-			"success": true,
-			"message": "Order item status updated successfully",
-		})
 	}
+	if err := repository.LoadItemModifiers(ctx, db, refs); err != nil {
+		return nil, err
+	}
+
+	return items, nil
 }
+
+// moved: updateOrderItemStatus is now KitchenHandler.UpdateItemStatus in handlers/kitchen.go
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 
 	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
 	"pos-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -20,5 +21,19 @@ func SetupOrderRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.Ha
 		protected.GET("/orders", orderHandler.GetOrders)
 		protected.GET("/orders/:id", orderHandler.GetOrder)
 		protected.PATCH("/orders/:id/status", orderHandler.UpdateOrderStatus)
+		protected.GET("/orders/:id/transitions", orderHandler.GetOrderTransitions)
+		protected.GET("/orders/:id/item-changes", orderHandler.GetOrderItemChanges)
+
+		// Item edits on open orders (front-of-house roles only)
+		editors := middleware.RequireRoles([]string{"admin", "manager", "server", "counter"})
+		protected.POST("/orders/:id/items", editors, orderHandler.AddOrderItem)
+		protected.PATCH("/orders/:id/items/:item_id", editors, orderHandler.UpdateOrderItem)
+		protected.DELETE("/orders/:id/items/:item_id", editors, orderHandler.RemoveOrderItem)
+		protected.POST("/orders/:id/items/:item_id/void", editors, orderHandler.VoidOrderItem)
+		protected.POST("/orders/:id/items/:item_id/comp", editors, orderHandler.CompOrderItem)
+		protected.POST("/orders/:id/courses/:course/fire", editors, orderHandler.FireCourse)
+		protected.PATCH("/orders/:id/guests", editors, orderHandler.UpdateGuestCount)
+		protected.POST("/orders/:id/discounts", editors, orderHandler.ApplyOrderDiscount)
+		protected.DELETE("/orders/:id/discounts/:discount_id", editors, orderHandler.RemoveOrderDiscount)
 	}
 }
@@ -3,19 +3,26 @@ package api
 import (
 	"database/sql"
 
+	"pos-backend/internal/gateway"
 	"pos-backend/internal/handlers"
+	"pos-backend/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupPaymentRoutes configures protected payment viewing endpoints
 func SetupPaymentRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
-	paymentHandler := handlers.NewPaymentHandler(db)
+	paymentHandler := handlers.NewPaymentHandler(db, gateway.Default)
 
 	protected := router.Group("/")
 	protected.Use(authMiddleware)
 	{
 		protected.GET("/orders/:id/payments", paymentHandler.GetPayments)
 		protected.GET("/orders/:id/payment-summary", paymentHandler.GetPaymentSummary)
+		protected.GET("/orders/:id/refunds", paymentHandler.GetRefunds)
+
+		// Refunds by the roles that take payments; non-managers need manager approval
+		cashiers := middleware.RequireRoles([]string{"admin", "manager", "counter"})
+		protected.POST("/orders/:id/payments/:payment_id/refund", cashiers, paymentHandler.RefundPayment)
 	}
 }
\ No newline at end of file
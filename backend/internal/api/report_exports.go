@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"log"
+
+	"pos-backend/internal/export"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportFormat reads the format query parameter: json (the default) or a file format from
+// the export package. It answers 400 and returns false for anything else.
+func reportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", "json")
+	if format == "json" || export.IsFormat(format) {
+		return format, true
+	}
+	c.JSON(400, gin.H{
+		"success": false,
+		"message": "format must be json, csv, xlsx or pdf",
+		"error":   "invalid_format",
+	})
+	return "", false
+}
+
+// writeReportFile sends report rows as a file download named after the report and period,
+// e.g. sales-2024-01-01-to-2024-01-31.csv
+func writeReportFile(c *gin.Context, format, name, title string, period *reportPeriod, columns export.Columns, rows []map[string]interface{}) {
+	from := period.From.Format(businessDateLayout)
+	to := period.To.Format(businessDateLayout)
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s-to-%s.%s"`, name, from, to, format))
+	c.Status(200)
+
+	table := export.Table{
+		Title: title,
+		Subtitle: fmt.Sprintf("%s to %s, %s, business day from %s",
+			from, to, period.settings.TimeZone, period.settings.DayCutoff),
+		Columns: columns,
+		Rows:    rows,
+	}
+	// Headers are already sent, so a failure part way through can only be logged
+	if err := export.Write(c.Writer, format, table); err != nil {
+		log.Printf("report export %s: %v", name, err)
+	}
+}
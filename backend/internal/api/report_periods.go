@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const businessDateLayout = "2006-01-02"
+
+// maxReportDays caps how many business days one report may span
+const maxReportDays = 731
+
+// reportPeriod is the span of business days a report covers. Days run from the store's day
+// cutoff in its time zone, so a sale at 1 a.m. before a 4 a.m. cutoff counts toward the
+// previous business day.
+type reportPeriod struct {
+	Name     string // today, week, month, year or custom
+	From     time.Time
+	To       time.Time // last business date, inclusive
+	GroupBy  string    // hour, day or month
+	Start    time.Time // when From opens
+	End      time.Time // when the day after To opens
+	settings models.StoreSettings
+}
+
+// parseReportPeriod reads the period a report covers from the query string: business dates
+// from/to (YYYY-MM-DD, to defaults to today), or else a named period (today, week, month,
+// year). group_by overrides the bucket size picked from the span.
+func parseReportPeriod(c *gin.Context, db *sql.DB) (*reportPeriod, error) {
+	return newReportPeriod(c.Request.Context(), db, c.DefaultQuery("period", "today"),
+		c.Query("from"), c.Query("to"), c.Query("group_by"))
+}
+
+// newReportPeriod builds a period from business dates from/to, or else from a period name
+func newReportPeriod(ctx context.Context, db *sql.DB, name, from, to, groupBy string) (*reportPeriod, error) {
+	settings, err := repository.GetStoreSettings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	today, err := repository.CurrentBusinessDate(ctx, db, settings)
+	if err != nil {
+		return nil, err
+	}
+	todayDate, err := time.Parse(businessDateLayout, today)
+	if err != nil {
+		return nil, err
+	}
+	return buildReportPeriod(settings, todayDate, name, from, to, groupBy)
+}
+
+// buildReportPeriod works out the period's business dates, bucket size and bounds given
+// today's business date
+func buildReportPeriod(settings models.StoreSettings, todayDate time.Time, name, from, to, groupBy string) (*reportPeriod, error) {
+	var err error
+	p := &reportPeriod{settings: settings}
+	if from != "" || to != "" {
+		if from == "" {
+			return nil, fmt.Errorf("invalid_date_range: from is required with to")
+		}
+		if p.From, err = time.Parse(businessDateLayout, from); err != nil {
+			return nil, fmt.Errorf("invalid_date_range: from must be in YYYY-MM-DD format")
+		}
+		p.To = todayDate
+		if to != "" {
+			if p.To, err = time.Parse(businessDateLayout, to); err != nil {
+				return nil, fmt.Errorf("invalid_date_range: to must be in YYYY-MM-DD format")
+			}
+		}
+		if p.To.Before(p.From) {
+			return nil, fmt.Errorf("invalid_date_range: to is before from")
+		}
+		if p.days() > maxReportDays {
+			return nil, fmt.Errorf("invalid_date_range: at most %d days can be reported at once", maxReportDays)
+		}
+		p.Name = "custom"
+	} else {
+		p.Name = name
+		p.To = todayDate
+		switch p.Name {
+		case "week":
+			p.From = todayDate.AddDate(0, 0, -6)
+		case "month":
+			p.From = todayDate.AddDate(0, 0, -29)
+		case "year":
+			p.From = todayDate.AddDate(-1, 0, 1)
+		default:
+			p.Name = "today"
+			p.From = todayDate
+		}
+	}
+
+	switch p.GroupBy = groupBy; p.GroupBy {
+	case "hour", "day", "month":
+	case "":
+		if p.days() == 1 {
+			p.GroupBy = "hour"
+		} else if p.days() <= 92 {
+			p.GroupBy = "day"
+		} else {
+			p.GroupBy = "month"
+		}
+	default:
+		return nil, fmt.Errorf("invalid_date_range: group_by must be hour, day or month")
+	}
+
+	if err := p.resolve(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// resolve works out when the period's first business day opens and its last one closes
+func (p *reportPeriod) resolve() error {
+	var err error
+	p.Start, p.End, err = repository.BusinessDayBounds(p.settings,
+		p.From.Format(businessDateLayout), p.To.Format(businessDateLayout))
+	return err
+}
+
+// days is the number of business days in the period
+func (p *reportPeriod) days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+// within filters column to the period. Queries using it take $1 start and $2 end.
+func (p *reportPeriod) within(column string) string {
+	return column + " >= $1 AND " + column + " < $2"
+}
+
+// localTime is column converted to the store's time zone
+func (p *reportPeriod) localTime(column string) string {
+	return "(" + column + " AT TIME ZONE " + pq.QuoteLiteral(p.settings.TimeZone) + ")"
+}
+
+// hourOfDay labels column with the hour of the day it falls in, 00 to 23, in store time
+func (p *reportPeriod) hourOfDay(column string) string {
+	return "to_char(" + p.localTime(column) + ", 'HH24')"
+}
+
+// businessTime is column in store time moved back by the day cutoff, so its date is the
+// business day it belongs to
+func (p *reportPeriod) businessTime(column string) string {
+	return "(" + p.localTime(column) + " - " + pq.QuoteLiteral(p.settings.DayCutoff) + "::interval)"
+}
+
+// bucket labels column with the hour, business day or month it falls in, in store time
+func (p *reportPeriod) bucket(column string) string {
+	local := p.localTime(column)
+	businessDay := p.businessTime(column)
+	switch p.GroupBy {
+	case "hour":
+		return "to_char(DATE_TRUNC('hour', " + local + "), 'YYYY-MM-DD\"T\"HH24:00:00')"
+	case "month":
+		return "to_char(" + businessDay + ", 'YYYY-MM')"
+	default:
+		return "to_char(" + businessDay + ", 'YYYY-MM-DD')"
+	}
+}
+
+// describe is the period as reported back to the client
+func (p *reportPeriod) describe() map[string]interface{} {
+	return map[string]interface{}{
+		"name":       p.Name,
+		"from":       p.From.Format(businessDateLayout),
+		"to":         p.To.Format(businessDateLayout),
+		"group_by":   p.GroupBy,
+		"time_zone":  p.settings.TimeZone,
+		"day_cutoff": p.settings.DayCutoff,
+		"start":      p.Start,
+		"end":        p.End,
+	}
+}
+
+// reportSummary totals a report over a period, for comparing one period with another
+type reportSummary func(db *sql.DB, p *reportPeriod) (map[string]interface{}, error)
+
+// reportComparison compares the period with the one the compare query parameter asks for.
+// It is nil when no comparison was asked for.
+func reportComparison(c *gin.Context, db *sql.DB, p *reportPeriod, summarize reportSummary) (map[string]interface{}, error) {
+	compare := c.Query("compare")
+	if compare == "" {
+		return nil, nil
+	}
+
+	other, err := p.comparedWith(compare)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := summarize(db, p)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := summarize(db, other)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"period":  other.describe(),
+		"summary": previous,
+		"change":  summaryChange(current, previous),
+	}, nil
+}
+
+// comparedWith is the period compare names: previous (the same number of days just before
+// it) or last_year (the same dates a year earlier)
+func (p *reportPeriod) comparedWith(compare string) (*reportPeriod, error) {
+	other := &reportPeriod{Name: compare, GroupBy: p.GroupBy, settings: p.settings}
+	switch compare {
+	case "previous":
+		other.From = p.From.AddDate(0, 0, -p.days())
+		other.To = p.From.AddDate(0, 0, -1)
+	case "last_year":
+		other.From = p.From.AddDate(-1, 0, 0)
+		other.To = p.To.AddDate(-1, 0, 0)
+	default:
+		return nil, fmt.Errorf("invalid_date_range: compare must be previous or last_year")
+	}
+	if err := other.resolve(); err != nil {
+		return nil, err
+	}
+	return other, nil
+}
+
+// summaryChange is how much each figure moved from previous to current. The percentage
+// is nil when there is nothing to compare against.
+func summaryChange(current, previous map[string]interface{}) map[string]interface{} {
+	change := map[string]interface{}{}
+	for key, value := range current {
+		now, ok := summaryNumber(value)
+		if !ok {
+			continue
+		}
+		before, ok := summaryNumber(previous[key])
+		if !ok {
+			continue
+		}
+
+		var percent interface{}
+		if before != 0 {
+			percent = math.Round((now-before)/math.Abs(before)*1000) / 10
+		}
+		change[key] = map[string]interface{}{
+			"difference": roundCents(now - before),
+			"percent":    percent,
+		}
+	}
+	return change
+}
+
+// roundCents rounds a report figure to whole cents
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// percentOf is part as a percentage of whole to one decimal place, or nil when whole is zero
+func percentOf(part, whole float64) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	pct := math.Round(part/whole*1000) / 10
+	return &pct
+}
+
+func summaryNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// writeReportPeriodError reports a bad period as a 400 and anything else as a 500
+func writeReportPeriodError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	if strings.HasPrefix(msg, "invalid_date_range") {
+		c.JSON(400, gin.H{
+			"success": false,
+			"message": strings.TrimPrefix(msg, "invalid_date_range: "),
+			"error":   "invalid_date_range",
+		})
+		return
+	}
+	c.JSON(500, gin.H{
+		"success": false,
+		"message": fallbackMessage,
+		"error":   msg,
+	})
+}
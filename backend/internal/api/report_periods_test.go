@@ -0,0 +1,170 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+)
+
+func mustUTC(t *testing.T, value string) time.Time {
+	tm, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func TestBuildReportPeriodDates(t *testing.T) {
+	settings := models.StoreSettings{TimeZone: "UTC", DayCutoff: "00:00"}
+	today := "2026-10-16"
+
+	tests := []struct {
+		name, period, from, to, groupBy string
+		wantName, wantFrom, wantTo      string
+		wantGroupBy                     string
+	}{
+		{"default", "", "", "", "", "today", "2026-10-16", "2026-10-16", "hour"},
+		{"unknown name", "fortnight", "", "", "", "today", "2026-10-16", "2026-10-16", "hour"},
+		{"week", "week", "", "", "", "week", "2026-10-10", "2026-10-16", "day"},
+		{"month", "month", "", "", "", "month", "2026-09-17", "2026-10-16", "day"},
+		{"year", "year", "", "", "", "year", "2025-10-17", "2026-10-16", "month"},
+		{"from alone runs to today", "week", "2026-10-01", "", "", "custom", "2026-10-01", "2026-10-16", "day"},
+		{"calendar month", "", "2026-02-01", "2026-02-28", "", "custom", "2026-02-01", "2026-02-28", "day"},
+		{"92 days are still daily", "", "2026-10-01", "2026-12-31", "", "custom", "2026-10-01", "2026-12-31", "day"},
+		{"longer is monthly", "", "2026-01-01", "2026-04-03", "", "custom", "2026-01-01", "2026-04-03", "month"},
+		{"group_by wins", "", "2026-10-01", "2026-12-31", "month", "custom", "2026-10-01", "2026-12-31", "month"},
+	}
+	for _, tt := range tests {
+		p, err := buildReportPeriod(settings, businessDate(t, today), tt.period, tt.from, tt.to, tt.groupBy)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if p.Name != tt.wantName || p.From.Format(businessDateLayout) != tt.wantFrom ||
+			p.To.Format(businessDateLayout) != tt.wantTo || p.GroupBy != tt.wantGroupBy {
+			t.Errorf("%s: got %s %s to %s by %s, want %s %s to %s by %s", tt.name,
+				p.Name, p.From.Format(businessDateLayout), p.To.Format(businessDateLayout), p.GroupBy,
+				tt.wantName, tt.wantFrom, tt.wantTo, tt.wantGroupBy)
+		}
+	}
+}
+
+func TestBuildReportPeriodErrors(t *testing.T) {
+	settings := models.StoreSettings{TimeZone: "UTC", DayCutoff: "00:00"}
+	tests := []struct {
+		name, from, to, groupBy string
+	}{
+		{"to without from", "", "2026-10-16", ""},
+		{"bad from", "16/10/2026", "", ""},
+		{"bad to", "2026-10-01", "2026-10-32", ""},
+		{"backwards", "2026-10-16", "2026-10-01", ""},
+		{"too long", "2024-01-01", "2026-01-02", ""},
+		{"bad group_by", "2026-10-01", "2026-10-16", "week"},
+	}
+	for _, tt := range tests {
+		_, err := buildReportPeriod(settings, businessDate(t, "2026-10-16"), "", tt.from, tt.to, tt.groupBy)
+		if err == nil || !strings.HasPrefix(err.Error(), "invalid_date_range:") {
+			t.Errorf("%s: error = %v, want invalid_date_range", tt.name, err)
+		}
+	}
+}
+
+func TestReportPeriodBoundsInStoreTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  models.StoreSettings
+		from, to  string
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			// Opens in winter time and closes in summer time
+			name:      "month across a daylight saving change",
+			settings:  models.StoreSettings{TimeZone: "America/New_York", DayCutoff: "04:00"},
+			from:      "2026-03-01",
+			to:        "2026-03-31",
+			wantStart: "2026-03-01T09:00:00Z",
+			wantEnd:   "2026-04-01T08:00:00Z",
+		},
+		{
+			name:      "quarter ending at midnight local time",
+			settings:  models.StoreSettings{TimeZone: "Europe/London", DayCutoff: "00:00"},
+			from:      "2026-10-01",
+			to:        "2026-12-31",
+			wantStart: "2026-09-30T23:00:00Z",
+			wantEnd:   "2027-01-01T00:00:00Z",
+		},
+		{
+			name:      "leap February ahead of UTC",
+			settings:  models.StoreSettings{TimeZone: "Asia/Tokyo", DayCutoff: "05:00"},
+			from:      "2028-02-01",
+			to:        "2028-02-29",
+			wantStart: "2028-01-31T20:00:00Z",
+			wantEnd:   "2028-02-29T20:00:00Z",
+		},
+		{
+			name:      "single day with a late cutoff",
+			settings:  models.StoreSettings{TimeZone: "UTC", DayCutoff: "06:30"},
+			from:      "2026-12-31",
+			to:        "2026-12-31",
+			wantStart: "2026-12-31T06:30:00Z",
+			wantEnd:   "2027-01-01T06:30:00Z",
+		},
+	}
+	for _, tt := range tests {
+		p, err := buildReportPeriod(tt.settings, businessDate(t, "2028-12-31"), "", tt.from, tt.to, "")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !p.Start.Equal(mustUTC(t, tt.wantStart)) || !p.End.Equal(mustUTC(t, tt.wantEnd)) {
+			t.Errorf("%s: runs %s to %s, want %s to %s", tt.name,
+				p.Start.UTC().Format(time.RFC3339), p.End.UTC().Format(time.RFC3339), tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestReportPeriodComparedWith(t *testing.T) {
+	settings := models.StoreSettings{TimeZone: "America/New_York", DayCutoff: "04:00"}
+	tests := []struct {
+		name, from, to, compare string
+		wantFrom, wantTo        string
+	}{
+		{"previous month is the same number of days", "2026-03-01", "2026-03-31", "previous", "2026-01-29", "2026-02-28"},
+		{"previous quarter", "2026-04-01", "2026-06-30", "previous", "2025-12-31", "2026-03-31"},
+		{"previous day", "2026-10-16", "2026-10-16", "previous", "2026-10-15", "2026-10-15"},
+		{"last year", "2026-10-01", "2026-12-31", "last_year", "2025-10-01", "2025-12-31"},
+		{"last year from a leap day", "2028-02-01", "2028-02-29", "last_year", "2027-02-01", "2027-03-01"},
+	}
+	for _, tt := range tests {
+		p, err := buildReportPeriod(settings, businessDate(t, "2028-12-31"), "", tt.from, tt.to, "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		other, err := p.comparedWith(tt.compare)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if other.From.Format(businessDateLayout) != tt.wantFrom || other.To.Format(businessDateLayout) != tt.wantTo {
+			t.Errorf("%s: compared with %s to %s, want %s to %s", tt.name,
+				other.From.Format(businessDateLayout), other.To.Format(businessDateLayout), tt.wantFrom, tt.wantTo)
+		}
+		if other.Name != tt.compare || other.GroupBy != p.GroupBy {
+			t.Errorf("%s: compared period is %s by %s", tt.name, other.Name, other.GroupBy)
+		}
+		// The compared period's bounds are in store time too
+		wantStart, _, _ := repository.BusinessDayBounds(settings, tt.wantFrom, tt.wantTo)
+		if !other.Start.Equal(wantStart) {
+			t.Errorf("%s: compared period opens %v, want %v", tt.name, other.Start, wantStart)
+		}
+	}
+
+	p, _ := buildReportPeriod(settings, businessDate(t, "2026-10-16"), "week", "", "", "")
+	if _, err := p.comparedWith("last_week"); err == nil || !strings.HasPrefix(err.Error(), "invalid_date_range:") {
+		t.Errorf("unknown comparison: error = %v, want invalid_date_range", err)
+	}
+}
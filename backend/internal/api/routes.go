@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -21,11 +22,24 @@ func SetupRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.Handler
 	SetupTableRoutes(router, db, authMiddleware)
 	SetupOrderRoutes(router, db, authMiddleware)
 	SetupPaymentRoutes(router, db, authMiddleware)
+	SetupDrawerRoutes(router, db, authMiddleware)
 	SetupServerRoutes(router, db, authMiddleware)
 	SetupCounterRoutes(router, db, authMiddleware)
 	SetupAdminReportsRoutes(router, db, authMiddleware)
+	SetupBusinessDayRoutes(router, db, authMiddleware)
+	SetupStaffRoutes(router, db, authMiddleware)
 	SetupAdminRoutes(router, db, authMiddleware)
+	SetupAdminSettingsRoutes(router, db, authMiddleware)
+	SetupAdminTaxRoutes(router, db, authMiddleware)
+	SetupAdminServiceChargeRoutes(router, db, authMiddleware)
+	SetupAdminPromotionRoutes(router, db, authMiddleware)
+	SetupAdminModifierRoutes(router, db, authMiddleware)
+	SetupAdminStationRoutes(router, db, authMiddleware)
+	SetupAdminPrinterRoutes(router, db, authMiddleware)
+	SetupInventoryRoutes(router, db, authMiddleware)
+	SetupIngredientRoutes(router, db, authMiddleware)
 	SetupKitchenRoutes(router, db, authMiddleware)
+	SetupEventRoutes(router, db, authMiddleware)
 }
 
 // moved: getDashboardStats is now defined in admin_reports.go
@@ -36,7 +50,7 @@ func SetupRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.Handler
 
 // moved: getKitchenOrders is now defined in kitchen_routes.go
 
-// moved: updateOrderItemStatus is now defined in kitchen_routes.go
+// moved: updateOrderItemStatus is now KitchenHandler.UpdateItemStatus in handlers/kitchen.go
 
 // moved: createDineInOrder is now defined in server_routes.go
 
@@ -51,6 +65,8 @@ func createCategory(db *sql.DB) gin.HandlerFunc {
 			Description *string `json:"description"`
 			Color       *string `json:"color"`
 			SortOrder   int     `json:"sort_order"`
+			TaxClassID  *string `json:"tax_class_id"`
+			StationID   *string `json:"station_id"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -64,10 +80,10 @@ func createCategory(db *sql.DB) gin.HandlerFunc {
 
 		var categoryID string
 		err := db.QueryRow(`
-			INSERT INTO categories (name, description, color, sort_order)
-			VALUES ($1, $2, $3, $4)
+			INSERT INTO categories (name, description, color, sort_order, tax_class_id, station_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
 			RETURNING id
-		`, req.Name, req.Description, req.Color, req.SortOrder).Scan(&categoryID)
+		`, req.Name, req.Description, req.Color, req.SortOrder, req.TaxClassID, req.StationID).Scan(&categoryID)
 
 		if err != nil {
 			c.JSON(500, gin.H{
@@ -96,6 +112,8 @@ func updateCategory(db *sql.DB) gin.HandlerFunc {
 			Description *string `json:"description"`
 			Color       *string `json:"color"`
 			SortOrder   *int    `json:"sort_order"`
+			TaxClassID  *string `json:"tax_class_id"` // empty string clears the tax class
+			StationID   *string `json:"station_id"`   // empty string clears the station
 			IsActive    *bool   `json:"is_active"`
 		}
 
@@ -133,6 +151,24 @@ func updateCategory(db *sql.DB) gin.HandlerFunc {
 			args = append(args, *req.SortOrder)
 			argCount++
 		}
+		if req.TaxClassID != nil {
+			if *req.TaxClassID == "" {
+				updates = append(updates, "tax_class_id = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("tax_class_id = $%d", argCount))
+				args = append(args, *req.TaxClassID)
+				argCount++
+			}
+		}
+		if req.StationID != nil {
+			if *req.StationID == "" {
+				updates = append(updates, "station_id = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("station_id = $%d", argCount))
+				args = append(args, *req.StationID)
+				argCount++
+			}
+		}
 		if req.IsActive != nil {
 			updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
 			args = append(args, *req.IsActive)
@@ -239,6 +275,8 @@ func createProduct(db *sql.DB) gin.HandlerFunc {
 			SKU             *string `json:"sku"`
 			PreparationTime int     `json:"preparation_time"`
 			SortOrder       int     `json:"sort_order"`
+			TaxClassID      *string `json:"tax_class_id"`
+			StationID       *string `json:"station_id"` // overrides the category station
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -252,10 +290,10 @@ func createProduct(db *sql.DB) gin.HandlerFunc {
 
 		var productID string
 		err := db.QueryRow(`
-			INSERT INTO products (category_id, name, description, price, image_url, barcode, sku, preparation_time, sort_order)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			INSERT INTO products (category_id, name, description, price, image_url, barcode, sku, preparation_time, sort_order, tax_class_id, station_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			RETURNING id
-		`, req.CategoryID, req.Name, req.Description, req.Price, req.ImageURL, req.Barcode, req.SKU, req.PreparationTime, req.SortOrder).Scan(&productID)
+		`, req.CategoryID, req.Name, req.Description, req.Price, req.ImageURL, req.Barcode, req.SKU, req.PreparationTime, req.SortOrder, req.TaxClassID, req.StationID).Scan(&productID)
 
 		if err != nil {
 			c.JSON(500, gin.H{
@@ -290,6 +328,8 @@ func updateProduct(db *sql.DB) gin.HandlerFunc {
 			IsAvailable     *bool    `json:"is_available"`
 			PreparationTime *int     `json:"preparation_time"`
 			SortOrder       *int     `json:"sort_order"`
+			TaxClassID      *string  `json:"tax_class_id"` // empty string clears the tax class
+			StationID       *string  `json:"station_id"`   // empty string falls back to the category station
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -356,6 +396,24 @@ func updateProduct(db *sql.DB) gin.HandlerFunc {
 			args = append(args, *req.SortOrder)
 			argCount++
 		}
+		if req.TaxClassID != nil {
+			if *req.TaxClassID == "" {
+				updates = append(updates, "tax_class_id = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("tax_class_id = $%d", argCount))
+				args = append(args, *req.TaxClassID)
+				argCount++
+			}
+		}
+		if req.StationID != nil {
+			if *req.StationID == "" {
+				updates = append(updates, "station_id = NULL")
+			} else {
+				updates = append(updates, fmt.Sprintf("station_id = $%d", argCount))
+				args = append(args, *req.StationID)
+				argCount++
+			}
+		}
 
 		if len(updates) == 0 {
 			c.JSON(400, gin.H{
@@ -697,6 +755,7 @@ func updateUser(db *sql.DB) gin.HandlerFunc {
 			FirstName *string `json:"first_name"`
 			LastName  *string `json:"last_name"`
 			Role      *string `json:"role"`
+			PIN       *string `json:"pin"`
 			IsActive  *bool   `json:"is_active"`
 		}
 
@@ -738,6 +797,48 @@ func updateUser(db *sql.DB) gin.HandlerFunc {
 			args = append(args, string(hashedPassword))
 			argCount++
 		}
+		if req.PIN != nil {
+			if *req.PIN == "" {
+				updates = append(updates, "pin_hash = NULL")
+			} else {
+				if err := repository.ValidatePIN(*req.PIN); err != nil {
+					c.JSON(400, gin.H{
+						"success": false,
+						"message": "PIN must be 4 to 8 digits",
+						"error":   "invalid_pin",
+					})
+					return
+				}
+				if err := repository.CheckPINAvailable(c.Request.Context(), db, *req.PIN, userID); err != nil {
+					if strings.HasPrefix(err.Error(), "pin_in_use") {
+						c.JSON(409, gin.H{
+							"success": false,
+							"message": "PIN is already in use; choose a different one",
+							"error":   "pin_in_use",
+						})
+						return
+					}
+					c.JSON(500, gin.H{
+						"success": false,
+						"message": "Failed to check PIN",
+						"error":   err.Error(),
+					})
+					return
+				}
+				hashedPIN, err := bcrypt.GenerateFromPassword([]byte(*req.PIN), bcrypt.DefaultCost)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"success": false,
+						"message": "Failed to hash PIN",
+						"error":   err.Error(),
+					})
+					return
+				}
+				updates = append(updates, fmt.Sprintf("pin_hash = $%d", argCount))
+				args = append(args, string(hashedPIN))
+				argCount++
+			}
+		}
 		if req.FirstName != nil {
 			updates = append(updates, fmt.Sprintf("first_name = $%d", argCount))
 			args = append(args, *req.FirstName)
@@ -995,7 +1096,7 @@ func getAdminCategories(db *sql.DB) gin.HandlerFunc {
 		offset := (page - 1) * perPage
 
 		// Build query with filters
-		queryBuilder := "SELECT id, name, description, color, sort_order, is_active, created_at, updated_at FROM categories WHERE 1=1"
+		queryBuilder := "SELECT id, name, description, color, sort_order, tax_class_id, station_id, is_active, created_at, updated_at FROM categories WHERE 1=1"
 		args := []interface{}{}
 		argCount := 0
 
@@ -1048,7 +1149,7 @@ func getAdminCategories(db *sql.DB) gin.HandlerFunc {
 
 			err := rows.Scan(
 				&category.ID, &category.Name, &category.Description, &category.Color,
-				&category.SortOrder, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
+				&category.SortOrder, &category.TaxClassID, &category.StationID, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
 			)
 			if err != nil {
 				c.JSON(500, gin.H{
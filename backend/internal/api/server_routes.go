@@ -30,11 +30,15 @@ func createDineInOrder(db *sql.DB) gin.HandlerFunc {
 			TableID      *string `json:"table_id"`
 			CustomerName *string `json:"customer_name"`
 			Items        []struct {
-				ProductID           string  `json:"product_id"`
-				Quantity            int     `json:"quantity"`
-				SpecialInstructions *string `json:"special_instructions"`
+				ProductID           string   `json:"product_id"`
+				Quantity            int      `json:"quantity"`
+				SpecialInstructions *string  `json:"special_instructions"`
+				ModifierIDs         []string `json:"modifier_ids"`
+				Course              int      `json:"course"`
+				Hold                bool     `json:"hold"`
 			} `json:"items"`
-			Notes *string `json:"notes"`
+			Notes      *string `json:"notes"`
+			GuestCount *int    `json:"guest_count"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -57,6 +61,7 @@ func createDineInOrder(db *sql.DB) gin.HandlerFunc {
 			"order_type":    "dine_in", // Force dine-in for servers
 			"items":         req.Items,
 			"notes":         req.Notes,
+			"guest_count":   req.GuestCount,
 		}
 
 		// Convert to JSON and back to simulate the request
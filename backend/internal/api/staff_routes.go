@@ -0,0 +1,17 @@
+package api
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupStaffRoutes configures self-service endpoints where any signed-in staff member sees
+// only their own figures
+func SetupStaffRoutes(router *gin.RouterGroup, db *sql.DB, authMiddleware gin.HandlerFunc) {
+	staff := router.Group("/staff")
+	staff.Use(authMiddleware)
+	{
+		staff.GET("/me/performance", getMyPerformance(db))
+	}
+}
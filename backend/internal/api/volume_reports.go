@@ -0,0 +1,242 @@
+package api
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orderCovers is how many guests an order served: its guest count, falling back to the
+// table's seating capacity, or one for orders without a table
+const orderCovers = `COALESCE(o.guest_count, dt.seating_capacity, 1)`
+
+// volumeSlot is the order volume in one weekday and hour. Weekday runs from 1 (Monday) to 7
+// (Sunday) by business day, so orders after midnight but before the day cutoff count
+// towards the evening before; hour is the store's clock hour.
+type volumeSlot struct {
+	Weekday    int     `json:"weekday"`
+	Hour       int     `json:"hour"`
+	Orders     int     `json:"orders"`
+	Covers     int     `json:"covers"`
+	Revenue    float64 `json:"revenue"`
+	AvgOrders  float64 `json:"avg_orders"` // per occurrence of the weekday in the period
+	AvgCovers  float64 `json:"avg_covers"`
+	AvgRevenue float64 `json:"avg_revenue"`
+}
+
+// forecastHour is the volume expected in one hour of a forecast day
+type forecastHour struct {
+	Hour    int     `json:"hour"`
+	Orders  float64 `json:"orders"`
+	Covers  float64 `json:"covers"`
+	Revenue float64 `json:"revenue"`
+}
+
+// forecastDay is the volume expected on one business day, with the hours that expect any
+type forecastDay struct {
+	Date    string         `json:"date"`
+	Weekday int            `json:"weekday"`
+	Orders  float64        `json:"orders"`
+	Covers  float64        `json:"covers"`
+	Revenue float64        `json:"revenue"`
+	Hours   []forecastHour `json:"hours"`
+}
+
+// isoWeekday numbers a date's weekday from 1 (Monday) to 7 (Sunday)
+func isoWeekday(date time.Time) int {
+	if date.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(date.Weekday())
+}
+
+// volumeHeatmap totals orders that were not cancelled, their covers and their revenue per
+// weekday and hour of the period. Revenue only counts completed orders, net of refunds.
+// Every one of the 168 slots is returned, Monday 00:00 first.
+func volumeHeatmap(db *sql.DB, period *reportPeriod) ([]volumeSlot, error) {
+	rows, err := db.Query(`
+		SELECT EXTRACT(ISODOW FROM `+period.businessTime("o.created_at")+`)::int,
+			EXTRACT(HOUR FROM `+period.localTime("o.created_at")+`)::int,
+			COUNT(*),
+			COALESCE(SUM(`+orderCovers+`), 0),
+			COALESCE(SUM(o.total_amount - COALESCE(rf.amount, 0)) FILTER (WHERE o.status = 'completed'), 0)
+		FROM orders o
+		LEFT JOIN dining_tables dt ON o.table_id = dt.id`+orderRefundsJoin+`
+		WHERE `+period.within("o.created_at")+` AND o.status <> 'cancelled'
+		GROUP BY 1, 2
+	`, period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := make([]volumeSlot, 7*24)
+	for i := range slots {
+		slots[i].Weekday = i/24 + 1
+		slots[i].Hour = i % 24
+	}
+	for rows.Next() {
+		var weekday, hour, orders, covers int
+		var revenue float64
+		if err := rows.Scan(&weekday, &hour, &orders, &covers, &revenue); err != nil {
+			return nil, err
+		}
+		slot := &slots[(weekday-1)*24+hour]
+		slot.Orders = orders
+		slot.Covers = covers
+		slot.Revenue = revenue
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	averageSlots(slots, period.From, period.To)
+	return slots, nil
+}
+
+// averageSlots sets each slot's averages per occurrence of its weekday between the
+// business days from and to, inclusive
+func averageSlots(slots []volumeSlot, from, to time.Time) {
+	var occurrences [8]int
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		occurrences[isoWeekday(date)]++
+	}
+	for i := range slots {
+		if n := float64(occurrences[slots[i].Weekday]); n > 0 {
+			slots[i].AvgOrders = math.Round(float64(slots[i].Orders)/n*10) / 10
+			slots[i].AvgCovers = math.Round(float64(slots[i].Covers)/n*10) / 10
+			slots[i].AvgRevenue = roundCents(slots[i].Revenue / n)
+		}
+	}
+}
+
+// forecastDays expects each hour of the weeks after today to see the average of its
+// weekday and hour in slots, which must hold all 168 slots Monday 00:00 first
+func forecastDays(slots []volumeSlot, today time.Time, weeks int) []forecastDay {
+	days := []forecastDay{}
+	for i := 1; i <= 7*weeks; i++ {
+		date := today.AddDate(0, 0, i)
+		day := forecastDay{
+			Date:    date.Format(businessDateLayout),
+			Weekday: isoWeekday(date),
+			Hours:   []forecastHour{},
+		}
+		for _, slot := range slots[(day.Weekday-1)*24 : day.Weekday*24] {
+			if slot.Orders == 0 {
+				continue
+			}
+			day.Hours = append(day.Hours, forecastHour{
+				Hour:    slot.Hour,
+				Orders:  slot.AvgOrders,
+				Covers:  slot.AvgCovers,
+				Revenue: slot.AvgRevenue,
+			})
+			day.Orders += slot.AvgOrders
+			day.Covers += slot.AvgCovers
+			day.Revenue += slot.AvgRevenue
+		}
+		day.Orders = math.Round(day.Orders*10) / 10
+		day.Covers = math.Round(day.Covers*10) / 10
+		day.Revenue = roundCents(day.Revenue)
+		days = append(days, day)
+	}
+	return days
+}
+
+// volumeForecast forecasts the volume of each hour over the next weeks with a seasonal
+// moving average: an hour is expected to see the average of the same weekday and hour over
+// the last historyWeeks full weeks, days with no orders counting as zero. The current
+// business day is part of neither the history nor the forecast.
+func volumeForecast(c *gin.Context, db *sql.DB, historyWeeks, forecastWeeks int) (map[string]interface{}, error) {
+	today, err := newReportPeriod(c.Request.Context(), db, "today", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	history := &reportPeriod{
+		Name:     "history",
+		From:     today.From.AddDate(0, 0, -7*historyWeeks),
+		To:       today.From.AddDate(0, 0, -1),
+		GroupBy:  "hour",
+		settings: today.settings,
+	}
+	if err := history.resolve(); err != nil {
+		return nil, err
+	}
+
+	// Each weekday occurs exactly historyWeeks times in the history, so summing per slot
+	// and dividing by the number of weeks gives the average
+	slots, err := volumeHeatmap(db, history)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"method":        "seasonal_moving_average",
+		"history_weeks": historyWeeks,
+		"history":       history.describe(),
+		"days":          forecastDays(slots, today.From, forecastWeeks),
+	}, nil
+}
+
+// Order volume report handler - a weekday by hour heatmap of orders, covers and revenue
+// over the period (the last 30 days unless asked otherwise), and a forecast of the same
+// figures per hour for the next forecast_weeks weeks (1 to 4, default 1) based on the last
+// weeks weeks (1 to 12, default 4)
+func getOrderVolumeReport(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period, err := newReportPeriod(c.Request.Context(), db, c.DefaultQuery("period", "month"),
+			c.Query("from"), c.Query("to"), "day")
+		if err != nil {
+			writeReportPeriodError(c, err, "Failed to fetch order volume report")
+			return
+		}
+
+		historyWeeks := 4
+		if weeksStr := c.Query("weeks"); weeksStr != "" {
+			if w, err := strconv.Atoi(weeksStr); err == nil && w > 0 && w <= 12 {
+				historyWeeks = w
+			}
+		}
+		forecastWeeks := 1
+		if weeksStr := c.Query("forecast_weeks"); weeksStr != "" {
+			if w, err := strconv.Atoi(weeksStr); err == nil && w > 0 && w <= 4 {
+				forecastWeeks = w
+			}
+		}
+
+		heatmap, err := volumeHeatmap(db, period)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to fetch order volume heatmap",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		forecast, err := volumeForecast(c, db, historyWeeks, forecastWeeks)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"success": false,
+				"message": "Failed to forecast order volume",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"success": true,
+			"message": "Order volume report retrieved successfully",
+			"data": map[string]interface{}{
+				"heatmap":  heatmap,
+				"forecast": forecast,
+				"period":   period.Name,
+				"range":    period.describe(),
+			},
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func businessDate(t *testing.T, date string) time.Time {
+	d, err := time.Parse(businessDateLayout, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// emptySlots returns the 168 weekday and hour slots with no orders
+func emptySlots() []volumeSlot {
+	slots := make([]volumeSlot, 7*24)
+	for i := range slots {
+		slots[i].Weekday = i/24 + 1
+		slots[i].Hour = i % 24
+	}
+	return slots
+}
+
+func slotAt(slots []volumeSlot, weekday, hour int) *volumeSlot {
+	return &slots[(weekday-1)*24+hour]
+}
+
+func TestIsoWeekday(t *testing.T) {
+	tests := map[string]int{
+		"2026-10-19": 1, // Monday
+		"2026-10-24": 6,
+		"2026-10-25": 7, // Sunday
+	}
+	for date, want := range tests {
+		if got := isoWeekday(businessDate(t, date)); got != want {
+			t.Errorf("isoWeekday(%s) = %d, want %d", date, got, want)
+		}
+	}
+}
+
+func TestAverageSlots(t *testing.T) {
+	slots := emptySlots()
+	*slotAt(slots, 1, 12) = volumeSlot{Weekday: 1, Hour: 12, Orders: 5, Covers: 9, Revenue: 100.02}
+	*slotAt(slots, 3, 19) = volumeSlot{Weekday: 3, Hour: 19, Orders: 4, Covers: 7, Revenue: 60}
+
+	// Mondays occur twice from Monday 5th to Monday 12th, Wednesdays once
+	averageSlots(slots, businessDate(t, "2026-10-05"), businessDate(t, "2026-10-12"))
+
+	monday := slotAt(slots, 1, 12)
+	if monday.AvgOrders != 2.5 || monday.AvgCovers != 4.5 || monday.AvgRevenue != 50.01 {
+		t.Errorf("Monday averages = %v orders, %v covers, %v revenue, want 2.5, 4.5, 50.01",
+			monday.AvgOrders, monday.AvgCovers, monday.AvgRevenue)
+	}
+	wednesday := slotAt(slots, 3, 19)
+	if wednesday.AvgOrders != 4 || wednesday.AvgCovers != 7 || wednesday.AvgRevenue != 60 {
+		t.Errorf("Wednesday averages = %v orders, %v covers, %v revenue, want 4, 7, 60",
+			wednesday.AvgOrders, wednesday.AvgCovers, wednesday.AvgRevenue)
+	}
+}
+
+func TestForecastDays(t *testing.T) {
+	slots := emptySlots()
+	*slotAt(slots, 1, 12) = volumeSlot{Weekday: 1, Hour: 12, Orders: 5, Covers: 9, Revenue: 100.02}
+	*slotAt(slots, 1, 13) = volumeSlot{Weekday: 1, Hour: 13, Orders: 1, Covers: 1, Revenue: 10}
+	*slotAt(slots, 2, 20) = volumeSlot{Weekday: 2, Hour: 20, Orders: 3, Covers: 6, Revenue: 45}
+
+	// Two full weeks of history ending the day before today, a Monday
+	today := businessDate(t, "2026-10-19")
+	averageSlots(slots, today.AddDate(0, 0, -14), today.AddDate(0, 0, -1))
+
+	days := forecastDays(slots, today, 2)
+	if len(days) != 14 {
+		t.Fatalf("forecast %d days, want 14", len(days))
+	}
+	if days[0].Date != "2026-10-20" || days[13].Date != "2026-11-02" {
+		t.Errorf("forecast runs %s to %s, want 2026-10-20 to 2026-11-02", days[0].Date, days[13].Date)
+	}
+
+	tuesday := days[0]
+	if tuesday.Weekday != 2 || len(tuesday.Hours) != 1 || tuesday.Hours[0].Hour != 20 || tuesday.Orders != 1.5 {
+		t.Errorf("Tuesday = %+v, want 1.5 orders at 20:00", tuesday)
+	}
+
+	monday := days[6]
+	if monday.Weekday != 1 || monday.Orders != 3 || monday.Covers != 5 || monday.Revenue != 55.01 {
+		t.Errorf("Monday = %v orders, %v covers, %v revenue, want 3, 5, 55.01",
+			monday.Orders, monday.Covers, monday.Revenue)
+	}
+	if len(monday.Hours) != 2 || monday.Hours[0].Hour != 12 || monday.Hours[1].Hour != 13 {
+		t.Errorf("Monday hours = %+v, want 12:00 and 13:00", monday.Hours)
+	}
+
+	// Each weekday repeats the same expectation week after week
+	if days[7].Orders != days[0].Orders || days[13].Revenue != monday.Revenue {
+		t.Error("the second week differs from the first")
+	}
+
+	quiet := days[1]
+	if quiet.Orders != 0 || quiet.Hours == nil || len(quiet.Hours) != 0 {
+		t.Errorf("Wednesday = %+v, want no orders and an empty hour list", quiet)
+	}
+}
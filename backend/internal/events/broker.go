@@ -0,0 +1,200 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types published to stream subscribers
+const (
+	OrderCreated       = "order-created"
+	ItemAdded          = "item-added"
+	ItemChanged        = "item-changed"
+	ItemStatusChanged  = "item-status-changed"
+	OrderStatusChanged = "order-status-changed"
+	PaymentCompleted   = "payment-completed"
+	PaymentRefunded    = "payment-refunded"
+	CourseFired        = "course-fired"
+)
+
+// roleEventTypes lists the event types each role may subscribe to
+var roleEventTypes = map[string][]string{
+	"admin":   {OrderCreated, ItemAdded, ItemChanged, ItemStatusChanged, OrderStatusChanged, CourseFired, PaymentCompleted, PaymentRefunded},
+	"manager": {OrderCreated, ItemAdded, ItemChanged, ItemStatusChanged, OrderStatusChanged, CourseFired, PaymentCompleted, PaymentRefunded},
+	"counter": {OrderCreated, ItemAdded, ItemChanged, ItemStatusChanged, OrderStatusChanged, CourseFired, PaymentCompleted, PaymentRefunded},
+	"server":  {OrderCreated, ItemAdded, ItemChanged, ItemStatusChanged, OrderStatusChanged, CourseFired, PaymentCompleted, PaymentRefunded},
+	"kitchen": {OrderCreated, ItemAdded, ItemChanged, ItemStatusChanged, OrderStatusChanged, CourseFired},
+}
+
+// Event is a single change pushed to subscribers
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	OrderID   uuid.UUID   `json:"order_id"`
+	Stations  []string    `json:"stations,omitempty"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Filter selects the events a subscriber receives
+type Filter struct {
+	Types   map[string]bool
+	Station string
+}
+
+// NewFilter builds a filter for a role. types is an optional comma separated list that
+// narrows the role's event types; station limits station-specific events to one station.
+func NewFilter(role, types, station string) (Filter, bool) {
+	allowed, ok := roleEventTypes[role]
+	if !ok {
+		return Filter{}, false
+	}
+
+	filter := Filter{Types: map[string]bool{}, Station: station}
+	requested := map[string]bool{}
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			requested[t] = true
+		}
+	}
+	for _, t := range allowed {
+		if len(requested) == 0 || requested[t] {
+			filter.Types[t] = true
+		}
+	}
+	return filter, true
+}
+
+// Matches reports whether an event passes the filter. Events that are not tied to
+// a station (e.g. a whole order being cancelled) reach every station.
+func (f Filter) Matches(e Event) bool {
+	if !f.Types[e.Type] {
+		return false
+	}
+	if f.Station == "" || len(e.Stations) == 0 {
+		return true
+	}
+	for _, s := range e.Stations {
+		if s == f.Station {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives matching events on C until it is closed. A subscriber that
+// falls too far behind is dropped and C is closed; it should reconnect and resume.
+type Subscription struct {
+	C      chan Event
+	filter Filter
+	broker *Broker
+}
+
+// Close stops delivery to the subscription
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// Broker fans events out to subscribers and keeps a bounded history for resume
+type Broker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker creates a broker that keeps the last historySize events for replay.
+// IDs start from the boot time in milliseconds so an ID handed out by an earlier
+// process is always older than the buffered history and triggers a resync.
+func NewBroker(historySize int) *Broker {
+	return &Broker{
+		nextID:      uint64(time.Now().UnixMilli()) * 1000,
+		historySize: historySize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Default is the process wide broker used by repositories and handlers
+var Default = NewBroker(1000)
+
+// Publish sends an event through the default broker
+func Publish(eventType string, orderID uuid.UUID, stations []string, data interface{}) Event {
+	return Default.Publish(eventType, orderID, stations, data)
+}
+
+// Publish records an event and delivers it to matching subscribers
+func (b *Broker) Publish(eventType string, orderID uuid.UUID, stations []string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := Event{
+		ID:        b.nextID,
+		Type:      eventType,
+		OrderID:   orderID,
+		Stations:  stations,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+			// too slow; drop it so the client reconnects and replays from its last ID
+			delete(b.subscribers, sub)
+			close(sub.C)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a subscriber. When lastEventID is set, the matching events
+// published after it are returned for replay; resumed is false when those events
+// are no longer buffered and the client has to reload its state instead.
+func (b *Broker) Subscribe(filter Filter, lastEventID uint64) (sub *Subscription, replay []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{C: make(chan Event, 64), filter: filter, broker: b}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil, true
+	}
+
+	if lastEventID > b.nextID || (len(b.history) > 0 && lastEventID < b.history[0].ID-1) ||
+		(len(b.history) == 0 && lastEventID != b.nextID) {
+		return sub, nil, false
+	}
+
+	for _, event := range b.history {
+		if event.ID > lastEventID && filter.Matches(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, true
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.C)
+	}
+}
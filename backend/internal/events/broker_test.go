@@ -0,0 +1,171 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func allTypes() Filter {
+	filter, _ := NewFilter("admin", "", "")
+	return filter
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestNewFilter(t *testing.T) {
+	if _, ok := NewFilter("customer", "", ""); ok {
+		t.Error("unknown role got a filter")
+	}
+
+	kitchen, ok := NewFilter("kitchen", "", "")
+	if !ok {
+		t.Fatal("kitchen got no filter")
+	}
+	if kitchen.Types[PaymentCompleted] {
+		t.Error("kitchen may subscribe to payments")
+	}
+
+	// Asking for a type the role may not see does not grant it
+	narrowed, _ := NewFilter("kitchen", "course-fired, payment-completed", "")
+	if len(narrowed.Types) != 1 || !narrowed.Types[CourseFired] {
+		t.Errorf("narrowed types = %v, want only %s", narrowed.Types, CourseFired)
+	}
+}
+
+func TestFilterMatchesStations(t *testing.T) {
+	filter, _ := NewFilter("kitchen", "", "grill")
+	tests := []struct {
+		event Event
+		want  bool
+	}{
+		{Event{Type: OrderCreated, Stations: []string{"grill", "bar"}}, true},
+		{Event{Type: OrderCreated, Stations: []string{"bar"}}, false},
+		{Event{Type: OrderStatusChanged}, true}, // not tied to a station
+		{Event{Type: PaymentCompleted}, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Matches(tt.event); got != tt.want {
+			t.Errorf("Matches(%s to %v) = %v, want %v", tt.event.Type, tt.event.Stations, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribeReplaysMissedEvents(t *testing.T) {
+	b := NewBroker(10)
+	orderID := uuid.New()
+	first := b.Publish(OrderCreated, orderID, nil, nil)
+	second := b.Publish(PaymentCompleted, orderID, nil, nil)
+	third := b.Publish(OrderStatusChanged, orderID, nil, nil)
+
+	sub, replay, resumed := b.Subscribe(allTypes(), first.ID)
+	defer sub.Close()
+	if !resumed {
+		t.Fatal("could not resume from a buffered event")
+	}
+	if got := eventIDs(replay); len(got) != 2 || got[0] != second.ID || got[1] != third.ID {
+		t.Errorf("replayed %v, want [%d %d]", got, second.ID, third.ID)
+	}
+
+	// Replay honours the filter
+	kitchen, _ := NewFilter("kitchen", "", "")
+	sub2, replay, _ := b.Subscribe(kitchen, first.ID)
+	defer sub2.Close()
+	if got := eventIDs(replay); len(got) != 1 || got[0] != third.ID {
+		t.Errorf("kitchen replayed %v, want [%d]", got, third.ID)
+	}
+}
+
+func TestSubscribeUpToDate(t *testing.T) {
+	b := NewBroker(10)
+	last := b.Publish(OrderCreated, uuid.New(), nil, nil)
+
+	sub, replay, resumed := b.Subscribe(allTypes(), last.ID)
+	defer sub.Close()
+	if !resumed || len(replay) != 0 {
+		t.Errorf("resumed = %v with %d events, want true with none", resumed, len(replay))
+	}
+
+	// A fresh broker has nothing buffered yet but can still resume from its start
+	empty := NewBroker(10)
+	sub2, _, resumed := empty.Subscribe(allTypes(), empty.nextID)
+	defer sub2.Close()
+	if !resumed {
+		t.Error("could not resume an empty broker from its current ID")
+	}
+}
+
+func TestSubscribeNeedsResync(t *testing.T) {
+	b := NewBroker(2)
+	orderID := uuid.New()
+	evicted := b.Publish(OrderCreated, orderID, nil, nil)
+	b.Publish(OrderCreated, orderID, nil, nil)
+	b.Publish(OrderCreated, orderID, nil, nil)
+	latest := b.Publish(OrderCreated, orderID, nil, nil)
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+	}{
+		{"events after it were evicted", evicted.ID},
+		{"from a later process", latest.ID + 100},
+		{"from an earlier process", 1},
+	}
+	for _, tt := range tests {
+		sub, replay, resumed := b.Subscribe(allTypes(), tt.lastEventID)
+		sub.Close()
+		if resumed || replay != nil {
+			t.Errorf("%s: resumed = %v with %d events, want a resync", tt.name, resumed, len(replay))
+		}
+	}
+}
+
+func TestPublishDeliversLiveEvents(t *testing.T) {
+	b := NewBroker(10)
+	grill, _ := NewFilter("kitchen", "", "grill")
+	sub, _, _ := b.Subscribe(grill, 0)
+	defer sub.Close()
+
+	b.Publish(OrderCreated, uuid.New(), []string{"bar"}, nil)
+	sent := b.Publish(OrderCreated, uuid.New(), []string{"grill"}, nil)
+
+	select {
+	case got := <-sub.C:
+		if got.ID != sent.ID {
+			t.Errorf("received event %d, want %d", got.ID, sent.ID)
+		}
+	default:
+		t.Fatal("no event delivered")
+	}
+	select {
+	case got := <-sub.C:
+		t.Errorf("received unexpected event %d", got.ID)
+	default:
+	}
+}
+
+func TestPublishDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(200)
+	sub, _, _ := b.Subscribe(allTypes(), 0)
+
+	for i := 0; i <= cap(sub.C); i++ {
+		b.Publish(OrderCreated, uuid.New(), nil, nil)
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received != cap(sub.C) {
+		t.Errorf("received %d events before being dropped, want %d", received, cap(sub.C))
+	}
+
+	// Closing a dropped subscription is harmless
+	sub.Close()
+}
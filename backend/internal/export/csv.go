@@ -0,0 +1,32 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WriteCSV streams the table as CSV with a heading row. The title is left out so the file
+// loads straight into a spreadsheet.
+func WriteCSV(w io.Writer, table Table) error {
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		record[i] = col.Title
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		for i, col := range table.Columns {
+			record[i] = cellText(row[col.Key])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A4 landscape, in points
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+)
+
+const (
+	pdfFontSize    = 9.0
+	pdfMinFontSize = 5.0
+	pdfCharWidth   = 0.6 // Courier glyphs are 600/1000 of the font size wide
+	pdfColumnGap   = 2   // characters between columns
+	pdfMaxCell     = 40  // longer cells are cut short
+)
+
+// WritePDF renders the table as a plain printable PDF: the title and subtitle on top, the
+// column headings repeated on every page and numbers right-aligned. It uses the built-in
+// Courier font so no font has to be embedded, shrinking the text when the columns would
+// not fit across the page.
+func WritePDF(w io.Writer, table Table) error {
+	cells := make([][]string, len(table.Rows))
+	widths := make([]int, len(table.Columns))
+	numeric := make([]bool, len(table.Columns))
+	for i, col := range table.Columns {
+		widths[i] = utf8.RuneCountInString(col.Title)
+		numeric[i] = true
+	}
+	for r, row := range table.Rows {
+		cells[r] = make([]string, len(table.Columns))
+		for i, col := range table.Columns {
+			value := row[col.Key]
+			text := cellText(value)
+			if _, ok := cellNumber(value); !ok && text != "" {
+				numeric[i] = false
+			}
+			cells[r][i] = truncateCell(text)
+			if n := utf8.RuneCountInString(cells[r][i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	lineChars := 0
+	for _, width := range widths {
+		lineChars += width + pdfColumnGap
+	}
+	fontSize := pdfFontSize
+	if lineChars > 0 {
+		if fit := (pdfPageWidth - 2*pdfMargin) / (float64(lineChars) * pdfCharWidth); fit < fontSize {
+			fontSize = fit
+		}
+	}
+	if fontSize < pdfMinFontSize {
+		fontSize = pdfMinFontSize
+	}
+	leading := fontSize * 1.3
+
+	heading := formatLine(columnTitles(table.Columns), widths, numeric)
+	rule := strings.Repeat("-", utf8.RuneCountInString(heading))
+
+	// Title, subtitle and a blank line, then the headings and rule
+	headerLines := 5
+	perPage := int((pdfPageHeight-2*pdfMargin)/leading) - headerLines - 1
+	if perPage < 1 {
+		perPage = 1
+	}
+	pageCount := (len(cells) + perPage - 1) / perPage
+	if pageCount == 0 {
+		pageCount = 1
+	}
+
+	generated := time.Now().Format("2006-01-02 15:04")
+	var pages [][]byte
+	for page := 0; page < pageCount; page++ {
+		var content bytes.Buffer
+		top := pdfPageHeight - pdfMargin
+
+		fmt.Fprintf(&content, "BT\n/F2 %.2f Tf\n%.2f %.2f Td\n%.2f TL\n", fontSize+3, pdfMargin, top, leading+3)
+		fmt.Fprintf(&content, "(%s) Tj\n", pdfString(table.Title))
+		fmt.Fprintf(&content, "/F1 %.2f Tf\n%.2f TL\n", fontSize, leading)
+		subtitle := table.Subtitle
+		if subtitle != "" {
+			subtitle += "  "
+		}
+		subtitle += fmt.Sprintf("Generated %s  Page %d of %d", generated, page+1, pageCount)
+		fmt.Fprintf(&content, "T* (%s) Tj\nT*\n", pdfString(subtitle))
+		fmt.Fprintf(&content, "/F2 %.2f Tf\nT* (%s) Tj\n/F1 %.2f Tf\n", fontSize, pdfString(heading), fontSize)
+		fmt.Fprintf(&content, "T* (%s) Tj\n", pdfString(rule))
+
+		end := (page + 1) * perPage
+		if end > len(cells) {
+			end = len(cells)
+		}
+		for _, row := range cells[page*perPage : end] {
+			fmt.Fprintf(&content, "T* (%s) Tj\n", pdfString(formatLine(row, widths, numeric)))
+		}
+		if len(cells) == 0 {
+			content.WriteString("T* (No data for this period) Tj\n")
+		}
+		content.WriteString("ET\n")
+		pages = append(pages, content.Bytes())
+	}
+
+	return writePDFDocument(w, pages)
+}
+
+// writePDFDocument lays out the catalog, page tree, fonts and one content stream per page,
+// followed by the cross-reference table of byte offsets
+func writePDFDocument(w io.Writer, pages [][]byte) error {
+	var doc bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	doc.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are fixed; each page then takes a page object and its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
+
+func columnTitles(cols Columns) []string {
+	titles := make([]string, len(cols))
+	for i, col := range cols {
+		titles[i] = col.Title
+	}
+	return titles
+}
+
+// formatLine pads each cell to its column width, right-aligning numeric columns
+func formatLine(cells []string, widths []int, numeric []bool) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if numeric[i] {
+			b.WriteString(pad + cell)
+		} else {
+			b.WriteString(cell + pad)
+		}
+		if i < len(cells)-1 {
+			b.WriteString(strings.Repeat(" ", pdfColumnGap))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func truncateCell(text string) string {
+	if runes := []rune(text); len(runes) > pdfMaxCell {
+		return string(runes[:pdfMaxCell-1]) + "~"
+	}
+	return text
+}
+
+// pdfString escapes text for a PDF string literal. Latin-1 characters and the euro sign are
+// written as octal codes in the font's WinAnsi encoding; anything else becomes a question mark.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '€':
+			b.WriteString("\\200")
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeTestPDF(t *testing.T, rows int) []byte {
+	t.Helper()
+	table := Table{
+		Title:    "Sales (daily)",
+		Subtitle: "2026-10-01 to 2026-10-16",
+		Columns:  Columns{{"date", "Date"}, {"revenue", "Revenue"}},
+	}
+	for i := 0; i < rows; i++ {
+		table.Rows = append(table.Rows, map[string]interface{}{"date": fmt.Sprintf("2026-10-%02d", i%28+1), "revenue": float64(i) * 1.5})
+	}
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// checkPDFStructure checks the header, the trailer and that the cross-reference table
+// points at the objects
+func checkPDFStructure(t *testing.T, doc []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) {
+		t.Errorf("header = %q, want %%PDF-1.4", doc[:min(len(doc), 9)])
+	}
+	if !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Error("document does not end with the EOF marker")
+	}
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	if match == nil {
+		t.Fatal("no startxref before the EOF marker")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if xref >= len(doc) || !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(doc[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("bad xref subsection %q", lines[1])
+	}
+	if !strings.Contains(string(doc), fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>", count)) {
+		t.Errorf("trailer does not give /Size %d", count)
+	}
+	for n := 1; n < count; n++ {
+		offset, err := strconv.Atoi(strings.Fields(lines[2+n])[0])
+		if err != nil || !bytes.HasPrefix(doc[offset:], []byte(fmt.Sprintf("%d 0 obj\n", n))) {
+			t.Errorf("xref entry %d (%q) does not point at object %d", n, lines[2+n], n)
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	doc := writeTestPDF(t, 3)
+	checkPDFStructure(t, doc)
+	if !bytes.Contains(doc, []byte(`(Sales \(daily\)) Tj`)) {
+		t.Error("title is missing or not escaped")
+	}
+	if !bytes.Contains(doc, []byte("/Count 1 >>")) {
+		t.Error("short report is not a single page")
+	}
+}
+
+func TestWritePDFPages(t *testing.T) {
+	doc := writeTestPDF(t, 200)
+	checkPDFStructure(t, doc)
+	count := regexp.MustCompile(`/Count (\d+) >>`).FindSubmatch(doc)
+	if count == nil {
+		t.Fatal("no page tree")
+	}
+	pages, _ := strconv.Atoi(string(count[1]))
+	if pages < 2 {
+		t.Fatalf("200 rows fit on %d page", pages)
+	}
+	// The headings repeat on every page
+	if got := len(regexp.MustCompile(`\(Date +Revenue\) Tj`).FindAll(doc, -1)); got != pages {
+		t.Errorf("headings appear %d times over %d pages", got, pages)
+	}
+	if !bytes.Contains(doc, []byte(fmt.Sprintf("Page %d of %d", pages, pages))) {
+		t.Error("last page is not numbered")
+	}
+}
+
+func TestWritePDFEmpty(t *testing.T) {
+	doc := writeTestPDF(t, 0)
+	checkPDFStructure(t, doc)
+	if !bytes.Contains(doc, []byte("(No data for this period) Tj")) {
+		t.Error("empty report does not say so")
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`a (b) \c`, `a \(b\) \\c`},
+		{"café", `caf\351`},
+		{"€5", `\2005`},
+		{"日本", "??"},
+	}
+	for _, tt := range tests {
+		if got := pdfString(tt.in); got != tt.want {
+			t.Errorf("pdfString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
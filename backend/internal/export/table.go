@@ -0,0 +1,119 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// File formats a report can be exported in
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// Column is one field of a report row: its key in the JSON output and its heading in files
+type Column struct {
+	Key   string
+	Title string
+}
+
+// Columns defines a report's rows once for the JSON output and every file format
+type Columns []Column
+
+// Row pairs values with the columns, in order, giving the row as the JSON output has it
+func (cols Columns) Row(values ...interface{}) (map[string]interface{}, error) {
+	if len(values) != len(cols) {
+		return nil, fmt.Errorf("export: row has %d values for %d columns", len(values), len(cols))
+	}
+	row := make(map[string]interface{}, len(cols))
+	for i, col := range cols {
+		row[col.Key] = values[i]
+	}
+	return row, nil
+}
+
+// Table is a report ready to be written to a file
+type Table struct {
+	Title    string
+	Subtitle string // e.g. the period covered
+	Columns  Columns
+	Rows     []map[string]interface{}
+}
+
+// IsFormat reports whether format is a file format reports can be exported in
+func IsFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX || format == FormatPDF
+}
+
+// ContentType is the MIME type of a file format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// Write writes the table to w in the given format
+func Write(w io.Writer, format string, table Table) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, table)
+	case FormatXLSX:
+		return WriteXLSX(w, table)
+	case FormatPDF:
+		return WritePDF(w, table)
+	}
+	return fmt.Errorf("unsupported_format: %s", format)
+}
+
+// cellText formats a value the way it appears in CSV and PDF files
+func cellText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', 2, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// cellNumber returns the value as a number when it is one, for spreadsheet cells
+func cellNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case *float64:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,21 @@
+package export
+
+import "testing"
+
+func TestColumnsRow(t *testing.T) {
+	cols := Columns{{Key: "date", Title: "Date"}, {Key: "orders", Title: "Orders"}}
+
+	row, err := cols.Row("2026-10-16", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["date"] != "2026-10-16" || row["orders"] != 12 || len(row) != 2 {
+		t.Errorf("row = %v", row)
+	}
+
+	for _, values := range [][]interface{}{{"2026-10-16"}, {"2026-10-16", 12, 3.5}} {
+		if row, err := cols.Row(values...); err == nil {
+			t.Errorf("Row(%v) = %v, want an error for %d values", values, row, len(values))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sheetCell is a worksheet cell as WriteXLSX writes it
+type sheetCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  int    `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type worksheet struct {
+	Rows []struct {
+		Number int         `xml:"r,attr"`
+		Cells  []sheetCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readZipPart(t *testing.T, r *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := r.Open(name)
+	if err != nil {
+		t.Fatalf("workbook has no %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWriteXLSX(t *testing.T) {
+	table := Table{
+		Title:   "Sales: Q4/2026",
+		Columns: Columns{{"item", "Item & size"}, {"sold", "Sold"}, {"revenue", "Revenue"}, {"notes", "Notes"}},
+		Rows: []map[string]interface{}{
+			{"item": "Fish & Chips <large>", "sold": 12, "revenue": 155.4, "notes": nil},
+			{"item": `"Quoted" 'tea'`, "sold": 0, "revenue": 0.0, "notes": "  spaced  "},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip file: %v", err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		readZipPart(t, r, name)
+	}
+
+	workbook := string(readZipPart(t, r, "xl/workbook.xml"))
+	if !strings.Contains(workbook, `<sheet name="Sales- Q4-2026"`) {
+		t.Errorf("sheet name not cleaned up:\n%s", workbook)
+	}
+
+	raw := readZipPart(t, r, "xl/worksheets/sheet1.xml")
+	if !bytes.Contains(raw, []byte("Fish &amp; Chips &lt;large&gt;")) || !bytes.Contains(raw, []byte("Item &amp; size")) {
+		t.Errorf("text is not XML escaped:\n%s", raw)
+	}
+	var sheet worksheet
+	if err := xml.Unmarshal(raw, &sheet); err != nil {
+		t.Fatalf("sheet1.xml does not parse: %v", err)
+	}
+
+	want := [][]sheetCell{
+		{
+			{Ref: "A1", Type: "inlineStr", Style: xlsxStyleHeading, Inline: "Item & size"},
+			{Ref: "B1", Type: "inlineStr", Style: xlsxStyleHeading, Inline: "Sold"},
+			{Ref: "C1", Type: "inlineStr", Style: xlsxStyleHeading, Inline: "Revenue"},
+			{Ref: "D1", Type: "inlineStr", Style: xlsxStyleHeading, Inline: "Notes"},
+		},
+		{
+			{Ref: "A2", Type: "inlineStr", Inline: "Fish & Chips <large>"},
+			{Ref: "B2", Value: "12"},                           // whole numbers stay plain
+			{Ref: "C2", Style: xlsxStyleMoney, Value: "155.4"}, // amounts get two decimals
+			// a nil value leaves the cell out
+		},
+		{
+			{Ref: "A3", Type: "inlineStr", Inline: `"Quoted" 'tea'`},
+			{Ref: "B3", Value: "0"},
+			{Ref: "C3", Style: xlsxStyleMoney, Value: "0"},
+			{Ref: "D3", Type: "inlineStr", Inline: "  spaced  "},
+		},
+	}
+	if len(sheet.Rows) != len(want) {
+		t.Fatalf("sheet has %d rows, want %d", len(sheet.Rows), len(want))
+	}
+	for i, row := range sheet.Rows {
+		if row.Number != i+1 {
+			t.Errorf("row %d is numbered %d", i+1, row.Number)
+		}
+		if len(row.Cells) != len(want[i]) {
+			t.Errorf("row %d has %d cells, want %d: %+v", i+1, len(row.Cells), len(want[i]), row.Cells)
+			continue
+		}
+		for j, cell := range row.Cells {
+			if cell != want[i][j] {
+				t.Errorf("cell %s = %+v, want %+v", want[i][j].Ref, cell, want[i][j])
+			}
+		}
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	tests := []struct {
+		col, row int
+		want     string
+	}{
+		{0, 1, "A1"},
+		{25, 2, "Z2"},
+		{26, 3, "AA3"},
+		{51, 4, "AZ4"},
+		{702, 5, "AAA5"},
+	}
+	for _, tt := range tests {
+		if got := cellRef(tt.col, tt.row); got != tt.want {
+			t.Errorf("cellRef(%d, %d) = %s, want %s", tt.col, tt.row, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PaymentGateway is a card processor. Payments are authorized first and then
+// captured; an authorization that is not captured is voided, and captured
+// payments are given back with refunds. Failures are returned as coded errors:
+// "payment_declined: <reason>" when the processor refuses, "gateway_timeout: ..."
+// when it does not answer in time, and "gateway_error: ..." for anything else.
+type PaymentGateway interface {
+	Authorize(ctx context.Context, req AuthorizeRequest) (reference string, err error)
+	Capture(ctx context.Context, reference string, amount float64) error
+	Void(ctx context.Context, reference string) error
+	Refund(ctx context.Context, reference string, amount float64) (refundReference string, err error)
+}
+
+// AuthorizeRequest describes a card charge to authorize
+type AuthorizeRequest struct {
+	OrderID       uuid.UUID
+	PaymentID     uuid.UUID
+	PaymentMethod string
+	Amount        float64
+	CardToken     string // token from the card terminal or tokenization form
+}
+
+// Default is the process wide gateway used by payment handlers
+var Default PaymentGateway = NewMockGateway(0)
+
+// New returns the gateway registered under name. There is no default: the mock approves
+// every ordinary card, so it has to be asked for by name.
+func New(name string) (PaymentGateway, error) {
+	switch name {
+	case "mock":
+		return NewMockGateway(0), nil
+	case "":
+		return nil, fmt.Errorf("no payment gateway configured")
+	}
+	return nil, fmt.Errorf("unknown payment gateway %q", name)
+}
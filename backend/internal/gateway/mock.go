@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Card tokens the mock gateway treats specially; any other token is approved
+const (
+	TokenDecline           = "tok_decline"
+	TokenInsufficientFunds = "tok_insufficient_funds"
+	TokenExpiredCard       = "tok_expired_card"
+	TokenTimeout           = "tok_timeout"
+)
+
+var mockDeclines = map[string]string{
+	TokenDecline:           "card_declined",
+	TokenInsufficientFunds: "insufficient_funds",
+	TokenExpiredCard:       "expired_card",
+}
+
+// MockGateway is an in-memory processor for running card flows offline. Its outcome
+// depends only on the card token, so the same request always behaves the same way.
+// TokenTimeout never answers and fails once the caller's context expires.
+type MockGateway struct {
+	mu      sync.Mutex
+	latency time.Duration
+	prefix  string
+	seq     uint64
+	charges map[string]*mockCharge
+}
+
+type mockCharge struct {
+	authorized float64
+	captured   float64
+	refunded   float64
+	voided     bool
+}
+
+// NewMockGateway creates a mock gateway that waits latency before every answer
+func NewMockGateway(latency time.Duration) *MockGateway {
+	return &MockGateway{
+		latency: latency,
+		prefix:  fmt.Sprintf("mock_%d", time.Now().Unix()),
+		charges: map[string]*mockCharge{},
+	}
+}
+
+func (g *MockGateway) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	if req.CardToken == TokenTimeout {
+		<-ctx.Done()
+		return "", fmt.Errorf("gateway_timeout: no answer from mock gateway: %v", ctx.Err())
+	}
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+	if reason, ok := mockDeclines[req.CardToken]; ok {
+		return "", fmt.Errorf("payment_declined: %s", reason)
+	}
+	if req.Amount <= 0 {
+		return "", fmt.Errorf("gateway_error: invalid amount %.2f", req.Amount)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	reference := g.nextReference("auth")
+	g.charges[reference] = &mockCharge{authorized: req.Amount}
+	return reference, nil
+}
+
+func (g *MockGateway) Capture(ctx context.Context, reference string, amount float64) error {
+	if err := g.wait(ctx); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[reference]
+	switch {
+	case !ok:
+		return fmt.Errorf("gateway_error: unknown authorization %s", reference)
+	case charge.voided:
+		return fmt.Errorf("gateway_error: authorization %s was voided", reference)
+	case charge.captured > 0:
+		return fmt.Errorf("gateway_error: authorization %s was already captured", reference)
+	case amount > charge.authorized+0.005:
+		return fmt.Errorf("gateway_error: capture %.2f exceeds authorized %.2f", amount, charge.authorized)
+	}
+	charge.captured = amount
+	return nil
+}
+
+func (g *MockGateway) Void(ctx context.Context, reference string) error {
+	if err := g.wait(ctx); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[reference]
+	switch {
+	case !ok:
+		return fmt.Errorf("gateway_error: unknown authorization %s", reference)
+	case charge.captured > 0:
+		return fmt.Errorf("gateway_error: authorization %s was captured; refund it instead", reference)
+	}
+	charge.voided = true
+	return nil
+}
+
+// Refund gives back part of a captured charge. Charges from before a restart are not
+// known to the mock and are treated as settled, so their refunds are approved.
+func (g *MockGateway) Refund(ctx context.Context, reference string, amount float64) (string, error) {
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if charge, ok := g.charges[reference]; ok {
+		if charge.refunded+amount > charge.captured+0.005 {
+			return "", fmt.Errorf("gateway_error: refund %.2f exceeds captured %.2f", charge.refunded+amount, charge.captured)
+		}
+		charge.refunded += amount
+	}
+	return g.nextReference("refund"), nil
+}
+
+func (g *MockGateway) wait(ctx context.Context) error {
+	if g.latency <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(g.latency):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("gateway_timeout: no answer from mock gateway: %v", ctx.Err())
+	}
+}
+
+// nextReference must be called with g.mu held
+func (g *MockGateway) nextReference(kind string) string {
+	g.seq++
+	return fmt.Sprintf("%s_%s_%06d", g.prefix, kind, g.seq)
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func authorize(g *MockGateway, token string, amount float64) (string, error) {
+	return g.Authorize(context.Background(), AuthorizeRequest{
+		OrderID: uuid.New(), PaymentID: uuid.New(), PaymentMethod: "credit_card", Amount: amount, CardToken: token,
+	})
+}
+
+// wantCode checks that err is a coded gateway error
+func wantCode(t *testing.T, what string, err error, code string) {
+	t.Helper()
+	if code == "" {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", what, err)
+		}
+		return
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), code) {
+		t.Errorf("%s: error = %v, want %s", what, err, code)
+	}
+}
+
+func TestMockAuthorize(t *testing.T) {
+	tests := []struct {
+		token  string
+		amount float64
+		want   string
+	}{
+		{"tok_visa", 25, ""},
+		{"", 25, ""},
+		{TokenDecline, 25, "payment_declined: card_declined"},
+		{TokenInsufficientFunds, 25, "payment_declined: insufficient_funds"},
+		{TokenExpiredCard, 25, "payment_declined: expired_card"},
+		{"tok_visa", 0, "gateway_error"},
+		{TokenDecline, 0, "payment_declined"}, // the card is refused before the amount is checked
+	}
+
+	g := NewMockGateway(0)
+	for _, tt := range tests {
+		reference, err := authorize(g, tt.token, tt.amount)
+		wantCode(t, tt.token, err, tt.want)
+		if tt.want == "" && reference == "" {
+			t.Errorf("%s: approved without a reference", tt.token)
+		}
+	}
+}
+
+func TestMockAuthorizeTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := NewMockGateway(0).Authorize(ctx, AuthorizeRequest{Amount: 25, CardToken: TokenTimeout})
+	wantCode(t, "timeout token", err, "gateway_timeout")
+
+	// Latency longer than the caller waits is a timeout too
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = NewMockGateway(time.Second).Authorize(ctx, AuthorizeRequest{Amount: 25, CardToken: "tok_visa"})
+	wantCode(t, "slow gateway", err, "gateway_timeout")
+}
+
+func TestMockCaptureVoidRefund(t *testing.T) {
+	ctx := context.Background()
+	g := NewMockGateway(0)
+
+	captured, _ := authorize(g, "tok_visa", 20)
+	wantCode(t, "capture over the authorization", g.Capture(ctx, captured, 25), "gateway_error")
+	wantCode(t, "capture", g.Capture(ctx, captured, 18), "")
+	wantCode(t, "second capture", g.Capture(ctx, captured, 18), "gateway_error")
+	wantCode(t, "void after capture", g.Void(ctx, captured), "gateway_error")
+
+	_, err := g.Refund(ctx, captured, 10)
+	wantCode(t, "partial refund", err, "")
+	_, err = g.Refund(ctx, captured, 10)
+	wantCode(t, "refund over what was captured", err, "gateway_error")
+	_, err = g.Refund(ctx, captured, 8)
+	wantCode(t, "refund the rest", err, "")
+
+	voided, _ := authorize(g, "tok_visa", 20)
+	wantCode(t, "void", g.Void(ctx, voided), "")
+	wantCode(t, "capture after void", g.Capture(ctx, voided, 20), "gateway_error")
+
+	wantCode(t, "capture unknown", g.Capture(ctx, "mock_unknown", 20), "gateway_error")
+	wantCode(t, "void unknown", g.Void(ctx, "mock_unknown"), "gateway_error")
+	_, err = g.Refund(ctx, "mock_from_before_restart", 20)
+	wantCode(t, "refund from before a restart", err, "")
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("mock"); err != nil {
+		t.Errorf("New(mock) = %v", err)
+	}
+	if _, err := New(""); err == nil {
+		t.Error("New with no name fell back to a gateway")
+	}
+	if _, err := New("acme"); err == nil {
+		t.Error("New accepted an unknown gateway")
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BusinessDayHandler struct {
+	repo repository.BusinessDayRepository
+}
+
+func NewBusinessDayHandler(repo repository.BusinessDayRepository) *BusinessDayHandler {
+	return &BusinessDayHandler{repo: repo}
+}
+
+// GetXReport returns the open business day's totals so far without closing it
+func (h *BusinessDayHandler) GetXReport(c *gin.Context) {
+	report, err := h.repo.CurrentReport(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to build X report",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "X report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// CloseBusinessDay freezes the open business day into a Z report
+func (h *BusinessDayHandler) CloseBusinessDay(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	// The business date is optional, so an empty body is fine
+	var req models.CloseBusinessDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.BusinessDate != nil {
+		if _, err := time.Parse("2006-01-02", *req.BusinessDate); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Business date must be in YYYY-MM-DD format",
+				Error:   stringPtr("invalid_business_date"),
+			})
+			return
+		}
+	}
+
+	reportID, err := h.repo.CloseDay(c.Request.Context(), req.BusinessDate, userID)
+	if err != nil {
+		writeBusinessDayError(c, err, "Failed to close business day")
+		return
+	}
+
+	report, err := h.repo.GetZReport(c.Request.Context(), reportID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Business day closed but failed to fetch the Z report",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Business day closed successfully",
+		Data:    report,
+	})
+}
+
+// GetZReports lists closed business days
+func (h *BusinessDayHandler) GetZReports(c *gin.Context) {
+	page := 1
+	perPage := 50
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := c.Query("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 100 {
+			perPage = pp
+		}
+	}
+
+	reports, total, err := h.repo.ListZReports(c.Request.Context(), perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch Z reports",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Message: "Z reports retrieved successfully",
+		Data:    reports,
+		Meta: models.MetaData{
+			CurrentPage: page,
+			PerPage:     perPage,
+			Total:       total,
+			TotalPages:  (total + perPage - 1) / perPage,
+		},
+	})
+}
+
+// GetZReport returns a closed business day with its breakdowns
+func (h *BusinessDayHandler) GetZReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid Z report ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	report, err := h.repo.GetZReport(c.Request.Context(), reportID)
+	if err != nil {
+		writeBusinessDayError(c, err, "Failed to fetch Z report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Z report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// writeBusinessDayError maps repository error codes from business day closes to API responses
+func writeBusinessDayError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "z_report_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Z report not found",
+			Error:   stringPtr("z_report_not_found"),
+		})
+	case strings.Contains(msg, "business_day_already_closed"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "That business day is already closed",
+			Error:   stringPtr("business_day_already_closed"),
+		})
+	case strings.Contains(msg, "invalid_business_date"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Business date " + strings.TrimPrefix(msg, "invalid_business_date: "),
+			Error:   stringPtr("invalid_business_date"),
+		})
+	case strings.Contains(msg, "open_orders"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Cannot close the day: " + strings.TrimPrefix(msg, "open_orders: "),
+			Error:   stringPtr("open_orders"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pos-backend/internal/gateway"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// gatewayTimeout bounds each exchange with the payment gateway
+const gatewayTimeout = 15 * time.Second
+
+// chargeCard records a pending card payment, then authorizes and captures it with the
+// gateway, together with any tip, and returns the processor reference. The caller holds the
+// order lock from repository.LockPayableOrder, so no other payment on the order can run in
+// between. The pending record is written outside the order transaction so an interrupted
+// charge still leaves a trace (repository.ExpireStalePayments expires it later); a
+// declined or failed charge marks it failed.
+func (h *PaymentHandler) chargeCard(ctx context.Context, paymentID, orderID, userID uuid.UUID, drawerSessionID *uuid.UUID, req models.ProcessPaymentRequest) (string, error) {
+	if _, err := h.db.ExecContext(ctx, `
+		INSERT INTO payments (id, order_id, payment_method, amount, tip_amount, reference_number, status, drawer_session_id, processed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7, $8)
+	`, paymentID, orderID, req.PaymentMethod, req.Amount, req.TipAmount, req.ReferenceNumber, drawerSessionID, userID); err != nil {
+		return "", err
+	}
+
+	charge := req.Amount + req.TipAmount
+	cardToken := ""
+	if req.CardToken != nil {
+		cardToken = *req.CardToken
+	}
+
+	gwCtx, cancel := context.WithTimeout(ctx, gatewayTimeout)
+	defer cancel()
+
+	reference, err := h.gateway.Authorize(gwCtx, gateway.AuthorizeRequest{
+		OrderID:       orderID,
+		PaymentID:     paymentID,
+		PaymentMethod: req.PaymentMethod,
+		Amount:        charge,
+		CardToken:     cardToken,
+	})
+	if err == nil {
+		if err = h.gateway.Capture(gwCtx, reference, charge); err != nil {
+			// Release the hold on the card rather than leave it authorized
+			voidCtx, voidCancel := context.WithTimeout(context.Background(), gatewayTimeout)
+			if voidErr := h.gateway.Void(voidCtx, reference); voidErr != nil {
+				log.Printf("payment %s: failed to void authorization %s: %v", paymentID, reference, voidErr)
+			}
+			voidCancel()
+		}
+	}
+
+	if err != nil {
+		var failedReference *string
+		if reference != "" {
+			failedReference = &reference
+		}
+		if _, dbErr := h.db.Exec(`
+			UPDATE payments
+			SET status = 'failed', reference_number = COALESCE($1, reference_number), processed_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, failedReference, paymentID); dbErr != nil {
+			log.Printf("payment %s: failed to mark payment failed: %v", paymentID, dbErr)
+		}
+		return "", err
+	}
+
+	return reference, nil
+}
+
+// reverseCharge refunds a captured charge whose payment could not be recorded and marks it failed
+func (h *PaymentHandler) reverseCharge(paymentID uuid.UUID, reference string, amount float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), gatewayTimeout)
+	defer cancel()
+
+	if _, err := h.gateway.Refund(ctx, reference, amount); err != nil {
+		log.Printf("payment %s: failed to reverse charge %s: %v", paymentID, reference, err)
+	}
+	if _, err := h.db.Exec("UPDATE payments SET status = 'failed' WHERE id = $1", paymentID); err != nil {
+		log.Printf("payment %s: failed to mark payment failed: %v", paymentID, err)
+	}
+}
+
+// settleRefund sends a refund of a card payment back through the gateway. Cash refunds
+// and card payments taken without the gateway have nothing to settle.
+func (h *PaymentHandler) settleRefund(ctx context.Context, paymentMethod string, reference *string, amount float64) (*string, error) {
+	if paymentMethod == "cash" || reference == nil || *reference == "" {
+		return nil, nil
+	}
+
+	gwCtx, cancel := context.WithTimeout(ctx, gatewayTimeout)
+	defer cancel()
+
+	refundReference, err := h.gateway.Refund(gwCtx, *reference, amount)
+	if err != nil {
+		return nil, err
+	}
+	return &refundReference, nil
+}
+
+// writeGatewayError maps payment gateway error codes to API responses
+func writeGatewayError(c *gin.Context, err error, paymentID uuid.UUID) {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "payment_declined"):
+		reason := strings.TrimPrefix(msg, "payment_declined: ")
+		c.JSON(http.StatusPaymentRequired, models.APIResponse{
+			Success: false,
+			Message: "Payment declined: " + reason,
+			Error:   stringPtr("payment_declined"),
+			Data:    gin.H{"payment_id": paymentID, "decline_reason": reason},
+		})
+	case strings.HasPrefix(msg, "gateway_timeout"):
+		c.JSON(http.StatusGatewayTimeout, models.APIResponse{
+			Success: false,
+			Message: "The payment processor did not respond; the payment was not taken",
+			Error:   stringPtr("gateway_timeout"),
+			Data:    gin.H{"payment_id": paymentID},
+		})
+	case strings.HasPrefix(msg, "gateway_error"):
+		c.JSON(http.StatusBadGateway, models.APIResponse{
+			Success: false,
+			Message: "The payment processor reported an error",
+			Error:   stringPtr(msg),
+			Data:    gin.H{"payment_id": paymentID},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to process card payment",
+			Error:   stringPtr(msg),
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cashTender is how a cash payment is split between the order, the tip and the change
+type cashTender struct {
+	Amount   float64 // applied to the order balance
+	Tendered float64
+	Change   float64
+	Rounding float64 // cash due minus the exact balance when the payment settles the order
+}
+
+// tenderCash works out a cash payment against the remaining balance. Paying more than the
+// balance is not an error: the payment is capped at the balance and the rest is change.
+// A payment that settles the order is due the balance rounded to the smallest coin.
+func tenderCash(req models.ProcessPaymentRequest, remaining, cashRounding float64) (cashTender, error) {
+	t := cashTender{Amount: req.Amount}
+	if req.TenderedAmount != nil {
+		t.Tendered = *req.TenderedAmount
+	} else {
+		t.Tendered = req.Amount + req.TipAmount
+	}
+	if t.Amount <= 0 {
+		t.Amount = t.Tendered - req.TipAmount
+	}
+
+	due := t.Amount
+	cashDue := repository.RoundCash(remaining, cashRounding)
+	if t.Amount >= cashDue-0.005 {
+		t.Amount = remaining
+		due = cashDue
+		t.Rounding = repository.RoundCash(cashDue-remaining, 0.01)
+	}
+
+	if t.Amount <= 0 {
+		return t, fmt.Errorf("invalid_amount: payment amount must be greater than zero")
+	}
+	if t.Tendered < due+req.TipAmount-0.005 {
+		return t, fmt.Errorf("insufficient_tender: %.2f is due but %.2f was tendered", due+req.TipAmount, t.Tendered)
+	}
+	t.Change = repository.RoundCash(t.Tendered-due-req.TipAmount, 0.01)
+	return t, nil
+}
+
+// writeTenderError maps cash tendering errors to API responses
+func writeTenderError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "insufficient_tender"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Not enough cash tendered: " + strings.TrimPrefix(msg, "insufficient_tender: "),
+			Error:   stringPtr("insufficient_tender"),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Payment amount must be greater than zero",
+			Error:   stringPtr("invalid_amount"),
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"pos-backend/internal/models"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestTenderCash(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.ProcessPaymentRequest
+		remaining  float64
+		rounding   float64
+		want       cashTender
+		wantErrors string
+	}{
+		{
+			name:      "exact change",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TenderedAmount: floatPtr(12.47)},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 12.47, Tendered: 12.47},
+		},
+		{
+			name:      "change from a note",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TenderedAmount: floatPtr(20)},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 12.47, Tendered: 20, Change: 7.53},
+		},
+		{
+			name:      "overpayment is capped at the balance",
+			req:       models.ProcessPaymentRequest{Amount: 30, TenderedAmount: floatPtr(30)},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 12.47, Tendered: 30, Change: 17.53},
+		},
+		{
+			name:      "tender without an amount pays the balance",
+			req:       models.ProcessPaymentRequest{TenderedAmount: floatPtr(20)},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 12.47, Tendered: 20, Change: 7.53},
+		},
+		{
+			name:      "settling payment is rounded down to the coin",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TenderedAmount: floatPtr(20)},
+			remaining: 12.47, rounding: 0.05,
+			want: cashTender{Amount: 12.47, Tendered: 20, Change: 7.55, Rounding: -0.02},
+		},
+		{
+			name:      "settling payment is rounded up to the coin",
+			req:       models.ProcessPaymentRequest{Amount: 12.5, TenderedAmount: floatPtr(12.5)},
+			remaining: 12.48, rounding: 0.05,
+			want: cashTender{Amount: 12.48, Tendered: 12.5, Rounding: 0.02},
+		},
+		{
+			name:      "rounded amount due settles the order",
+			req:       models.ProcessPaymentRequest{Amount: 12.45, TenderedAmount: floatPtr(12.45)},
+			remaining: 12.47, rounding: 0.05,
+			want: cashTender{Amount: 12.47, Tendered: 12.45, Rounding: -0.02},
+		},
+		{
+			name:      "part payment is not rounded",
+			req:       models.ProcessPaymentRequest{Amount: 5.03, TenderedAmount: floatPtr(10)},
+			remaining: 12.47, rounding: 0.05,
+			want: cashTender{Amount: 5.03, Tendered: 10, Change: 4.97},
+		},
+		{
+			name:      "tip is paid on top",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TipAmount: 2, TenderedAmount: floatPtr(20)},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 12.47, Tendered: 20, Change: 5.53},
+		},
+		{
+			name:      "no tender means exact cash",
+			req:       models.ProcessPaymentRequest{Amount: 5, TipAmount: 1},
+			remaining: 12.47, rounding: 0.01,
+			want: cashTender{Amount: 5, Tendered: 6},
+		},
+		{
+			name:      "not enough tendered",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TenderedAmount: floatPtr(10)},
+			remaining: 12.47, rounding: 0.01,
+			wantErrors: "insufficient_tender",
+		},
+		{
+			name:      "not enough for the tip",
+			req:       models.ProcessPaymentRequest{Amount: 12.47, TipAmount: 2, TenderedAmount: floatPtr(13)},
+			remaining: 12.47, rounding: 0.01,
+			wantErrors: "insufficient_tender",
+		},
+		{
+			name:      "nothing paid",
+			req:       models.ProcessPaymentRequest{},
+			remaining: 12.47, rounding: 0.01,
+			wantErrors: "invalid_amount",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tenderCash(tt.req, tt.remaining, tt.rounding)
+		if tt.wantErrors != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErrors) {
+				t.Errorf("%s: error = %v, want %s", tt.name, err, tt.wantErrors)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DrawerHandler struct {
+	repo repository.DrawerRepository
+}
+
+func NewDrawerHandler(repo repository.DrawerRepository) *DrawerHandler {
+	return &DrawerHandler{repo: repo}
+}
+
+// OpenDrawer starts a drawer session with an opening float
+func (h *DrawerHandler) OpenDrawer(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.OpenDrawerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if *req.OpeningFloat < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Opening float cannot be negative",
+			Error:   stringPtr("invalid_amount"),
+		})
+		return
+	}
+
+	drawerName := strings.TrimSpace(req.DrawerName)
+	if drawerName == "" {
+		drawerName = repository.DefaultDrawerName
+	}
+
+	sessionID, err := h.repo.OpenSession(c.Request.Context(), drawerName, *req.OpeningFloat, userID)
+	if err != nil {
+		writeDrawerError(c, err, "Failed to open drawer")
+		return
+	}
+
+	h.respondWithSession(c, sessionID, http.StatusCreated, "Drawer opened successfully")
+}
+
+// GetCurrentDrawer returns the drawer session the signed-in user has open
+func (h *DrawerHandler) GetCurrentDrawer(c *gin.Context) {
+	userID, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	session, err := h.repo.CurrentSession(c.Request.Context(), userID)
+	if err != nil {
+		writeDrawerError(c, err, "Failed to fetch drawer session")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Drawer session retrieved successfully",
+		Data:    blindSession(session, role),
+	})
+}
+
+// RecordDrawerMovement logs a pay-in, pay-out or no-sale open on the caller's drawer
+func (h *DrawerHandler) RecordDrawerMovement(c *gin.Context) {
+	session, userID, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+
+	var req models.DrawerMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	switch req.MovementType {
+	case repository.DrawerPayIn, repository.DrawerPayOut:
+		if req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Amount must be greater than zero",
+				Error:   stringPtr("invalid_amount"),
+			})
+			return
+		}
+		if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "A reason is required for pay-ins and pay-outs",
+				Error:   stringPtr("reason_required"),
+			})
+			return
+		}
+	case repository.DrawerNoSale:
+		req.Amount = 0
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Movement type must be pay_in, pay_out or no_sale",
+			Error:   stringPtr("invalid_movement_type"),
+		})
+		return
+	}
+
+	if _, err := h.repo.RecordMovement(c.Request.Context(), session.ID, req.MovementType, req.Amount, req.Reason, userID); err != nil {
+		writeDrawerError(c, err, "Failed to record drawer movement")
+		return
+	}
+
+	h.respondWithSession(c, session.ID, http.StatusCreated, "Drawer movement recorded successfully")
+}
+
+// CloseDrawer records the blind count for the caller's drawer. The expected amount and
+// over/short are kept from the counting user; managers see them in the drawer report.
+func (h *DrawerHandler) CloseDrawer(c *gin.Context) {
+	session, userID, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+
+	var req models.CloseDrawerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if *req.CountedAmount < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Counted amount cannot be negative",
+			Error:   stringPtr("invalid_amount"),
+		})
+		return
+	}
+
+	if err := h.repo.CloseSession(c.Request.Context(), session.ID, *req.CountedAmount, req.Notes, userID); err != nil {
+		writeDrawerError(c, err, "Failed to close drawer")
+		return
+	}
+
+	h.respondWithSession(c, session.ID, http.StatusOK, "Drawer closed successfully")
+}
+
+// GetDrawerSessions lists drawer sessions, optionally filtered by status
+func (h *DrawerHandler) GetDrawerSessions(c *gin.Context) {
+	page := 1
+	perPage := 50
+	status := c.Query("status")
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := c.Query("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 100 {
+			perPage = pp
+		}
+	}
+
+	sessions, total, err := h.repo.ListSessions(c.Request.Context(), status, perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch drawer sessions",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Message: "Drawer sessions retrieved successfully",
+		Data:    sessions,
+		Meta: models.MetaData{
+			CurrentPage: page,
+			PerPage:     perPage,
+			Total:       total,
+			TotalPages:  (total + perPage - 1) / perPage,
+		},
+	})
+}
+
+// GetDrawerReport returns the over/short reconciliation of a drawer session
+func (h *DrawerHandler) GetDrawerReport(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid drawer session ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	report, err := h.repo.SessionReport(c.Request.Context(), sessionID)
+	if err != nil {
+		writeDrawerError(c, err, "Failed to build drawer report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Drawer report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// ReviewDrawer signs off a closed drawer session's over/short
+func (h *DrawerHandler) ReviewDrawer(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid drawer session ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	// Notes are optional, so an empty body is fine
+	var req models.ReviewDrawerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if err := h.repo.ReviewSession(c.Request.Context(), sessionID, req.Notes, userID); err != nil {
+		writeDrawerError(c, err, "Failed to review drawer session")
+		return
+	}
+
+	report, err := h.repo.SessionReport(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Drawer reviewed but failed to fetch report",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Drawer session reviewed successfully",
+		Data:    report,
+	})
+}
+
+// ownedSession loads the session in the path and checks the caller may work on it: the
+// user who opened it, or a manager
+func (h *DrawerHandler) ownedSession(c *gin.Context) (*models.DrawerSession, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid drawer session ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return nil, uuid.Nil, false
+	}
+
+	userID, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return nil, uuid.Nil, false
+	}
+
+	session, err := h.repo.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		writeDrawerError(c, err, "Failed to fetch drawer session")
+		return nil, uuid.Nil, false
+	}
+
+	if !repository.IsApproverRole(role) && (session.OpenedBy == nil || *session.OpenedBy != userID) {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "This drawer was opened by someone else",
+			Error:   stringPtr("not_session_owner"),
+		})
+		return nil, uuid.Nil, false
+	}
+
+	return session, userID, true
+}
+
+// respondWithSession reloads a session after a change and writes it to the response
+func (h *DrawerHandler) respondWithSession(c *gin.Context, sessionID uuid.UUID, status int, message string) {
+	session, err := h.repo.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Drawer updated but failed to fetch details",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	_, _, role, _ := middleware.GetUserFromContext(c)
+	c.JSON(status, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    blindSession(session, role),
+	})
+}
+
+// blindSession hides the expected amount and over/short from roles that count the drawer
+func blindSession(session *models.DrawerSession, role string) *models.DrawerSession {
+	if !repository.IsApproverRole(role) {
+		session.ExpectedAmount = nil
+		session.OverShort = nil
+	}
+	return session
+}
+
+// writeDrawerError maps repository error codes from drawer sessions to API responses
+func writeDrawerError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "session_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Drawer session not found",
+			Error:   stringPtr("session_not_found"),
+		})
+	case strings.Contains(msg, "session_already_open"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "You already have a drawer open",
+			Error:   stringPtr("session_already_open"),
+		})
+	case strings.Contains(msg, "drawer_in_use"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "That drawer is already open",
+			Error:   stringPtr("drawer_in_use"),
+		})
+	case strings.Contains(msg, "session_not_open"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Drawer session is already closed",
+			Error:   stringPtr("session_not_open"),
+		})
+	case strings.Contains(msg, "session_not_closed"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Only closed drawer sessions can be reviewed",
+			Error:   stringPtr("session_not_closed"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
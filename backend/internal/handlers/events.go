@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval keeps idle connections open through proxies
+const heartbeatInterval = 25 * time.Second
+
+type EventHandler struct {
+	broker *events.Broker
+}
+
+func NewEventHandler(broker *events.Broker) *EventHandler {
+	return &EventHandler{broker: broker}
+}
+
+// IssueStreamToken returns a short-lived token for opening the event stream with
+// ?stream_token=, so the session token stays out of URLs and logs
+func (h *EventHandler) IssueStreamToken(c *gin.Context) {
+	userID, username, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	token, expiresAt, err := middleware.GenerateStreamToken(userID, username, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to issue stream token",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stream token issued",
+		Data: map[string]interface{}{
+			"token":      token,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
+// StreamEvents pushes order events to the client as Server-Sent Events.
+// Reconnecting clients send Last-Event-ID (or last_event_id) to replay what they missed;
+// if that is no longer possible a "resync" event tells them to reload their state.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	_, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	filter, ok := events.NewFilter(role, c.Query("types"), c.Query("station"))
+	if !ok {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Insufficient permissions",
+			Error:   stringPtr("insufficient_permissions"),
+		})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Last-Event-ID",
+				Error:   stringPtr("invalid_event_id"),
+			})
+			return
+		}
+		lastID = id
+	}
+
+	sub, replay, resumed := h.broker.Subscribe(filter, lastID)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	if !resumed {
+		fmt.Fprint(c.Writer, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		if err := writeEvent(c, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-sub.C:
+			if !open {
+				// dropped for falling behind; the client reconnects with its last ID
+				return
+			}
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeEvent(c *gin.Context, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type IngredientHandler struct {
+	repo repository.IngredientRepository
+}
+
+func NewIngredientHandler(repo repository.IngredientRepository) *IngredientHandler {
+	return &IngredientHandler{repo: repo}
+}
+
+// GetIngredients lists ingredients, optionally only those at or below their minimum
+func (h *IngredientHandler) GetIngredients(c *gin.Context) {
+	ingredients, err := h.repo.ListIngredients(c.Request.Context(), c.Query("low_stock") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch ingredients",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ingredients retrieved successfully",
+		Data:    ingredients,
+	})
+}
+
+// CreateIngredient adds an ingredient with its opening stock
+func (h *IngredientHandler) CreateIngredient(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.IngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.Name == nil || *req.Name == "" || req.Unit == nil || *req.Unit == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Name and unit are required",
+			Error:   stringPtr("missing_fields"),
+		})
+		return
+	}
+
+	if !validIngredientAmounts(c, req) {
+		return
+	}
+
+	id, err := h.repo.CreateIngredient(c.Request.Context(), req, userID)
+	if err != nil {
+		writeIngredientError(c, err, "Failed to create ingredient")
+		return
+	}
+
+	h.respondWithIngredient(c, id, http.StatusCreated, "Ingredient created successfully")
+}
+
+// UpdateIngredient changes ingredient details
+func (h *IngredientHandler) UpdateIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	var req models.IngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.CurrentStock != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Stock changes must be recorded as a purchase, waste, count or adjustment",
+			Error:   stringPtr("stock_not_editable"),
+		})
+		return
+	}
+
+	if !validIngredientAmounts(c, req) {
+		return
+	}
+
+	if err := h.repo.UpdateIngredient(c.Request.Context(), id, req); err != nil {
+		writeIngredientError(c, err, "Failed to update ingredient")
+		return
+	}
+
+	h.respondWithIngredient(c, id, http.StatusOK, "Ingredient updated successfully")
+}
+
+// DeleteIngredient removes an ingredient not used by any recipe
+func (h *IngredientHandler) DeleteIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteIngredient(c.Request.Context(), id); err != nil {
+		writeIngredientError(c, err, "Failed to delete ingredient")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ingredient deleted successfully",
+	})
+}
+
+// RecordIngredientStock records a purchase, waste, stock count or adjustment
+func (h *IngredientHandler) RecordIngredientStock(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.IngredientStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	switch req.MovementType {
+	case repository.IngredientPurchase, repository.IngredientWaste:
+		if req.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Quantity must be greater than zero",
+				Error:   stringPtr("invalid_quantity"),
+			})
+			return
+		}
+	case repository.IngredientCount:
+		if req.Quantity < 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Counted stock cannot be negative",
+				Error:   stringPtr("invalid_quantity"),
+			})
+			return
+		}
+	case repository.IngredientAdjustment:
+		if req.Quantity == 0 || req.Reason == nil || *req.Reason == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Adjustments need a non-zero quantity and a reason",
+				Error:   stringPtr("invalid_adjustment"),
+			})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "movement_type must be one of purchase, waste, count, adjustment",
+			Error:   stringPtr("invalid_movement_type"),
+		})
+		return
+	}
+
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Unit cost cannot be negative",
+			Error:   stringPtr("invalid_unit_cost"),
+		})
+		return
+	}
+
+	if err := h.repo.RecordIngredientStock(c.Request.Context(), id, req, userID); err != nil {
+		writeIngredientError(c, err, "Failed to record ingredient stock")
+		return
+	}
+
+	h.respondWithIngredient(c, id, http.StatusOK, "Ingredient stock recorded successfully")
+}
+
+// GetIngredientMovements returns the stock ledger of an ingredient
+func (h *IngredientHandler) GetIngredientMovements(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	page := 1
+	perPage := 50
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := c.Query("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 200 {
+			perPage = pp
+		}
+	}
+
+	offset := (page - 1) * perPage
+
+	movements, total, err := h.repo.ListIngredientMovements(c.Request.Context(), id, perPage, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch ingredient movements",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Message: "Ingredient movements retrieved successfully",
+		Data:    movements,
+		Meta: models.MetaData{
+			CurrentPage: page,
+			PerPage:     perPage,
+			Total:       total,
+			TotalPages:  totalPages,
+		},
+	})
+}
+
+// GetProductRecipe returns the ingredients used by one unit of a product
+func (h *IngredientHandler) GetProductRecipe(c *gin.Context) {
+	h.getRecipe(c, h.repo.GetProductRecipe)
+}
+
+// SetProductRecipe replaces the recipe of a product
+func (h *IngredientHandler) SetProductRecipe(c *gin.Context) {
+	h.setRecipe(c, h.repo.SetProductRecipe, h.repo.GetProductRecipe)
+}
+
+// GetModifierRecipe returns the extra ingredients used when a modifier is chosen
+func (h *IngredientHandler) GetModifierRecipe(c *gin.Context) {
+	h.getRecipe(c, h.repo.GetModifierRecipe)
+}
+
+// SetModifierRecipe replaces the recipe of a modifier
+func (h *IngredientHandler) SetModifierRecipe(c *gin.Context) {
+	h.setRecipe(c, h.repo.SetModifierRecipe, h.repo.GetModifierRecipe)
+}
+
+// GetIngredientUsageReport compares theoretical usage from sales with stock counts
+func (h *IngredientHandler) GetIngredientUsageReport(c *gin.Context) {
+	period := c.DefaultQuery("period", "today") // today, week, month
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var from time.Time
+	switch period {
+	case "week":
+		from = today.AddDate(0, 0, -7)
+	case "month":
+		from = today.AddDate(0, 0, -30)
+	default: // today
+		from = today
+	}
+
+	usage, err := h.repo.IngredientUsage(c.Request.Context(), from, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch ingredient usage",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	var theoreticalCost, varianceCost float64
+	for _, u := range usage {
+		theoreticalCost += u.TheoreticalUsage * u.UnitCost
+		varianceCost += u.VarianceCost
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ingredient usage report retrieved successfully",
+		Data: gin.H{
+			"ingredients":      usage,
+			"theoretical_cost": theoreticalCost,
+			"variance_cost":    varianceCost,
+			"period":           period,
+		},
+	})
+}
+
+func (h *IngredientHandler) getRecipe(c *gin.Context, get func(ctx context.Context, id uuid.UUID) ([]models.RecipeItem, error)) {
+	id, ok := parseIDParam(c, "id", "Invalid ID")
+	if !ok {
+		return
+	}
+
+	items, err := get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch recipe",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Recipe retrieved successfully",
+		Data:    items,
+	})
+}
+
+func (h *IngredientHandler) setRecipe(c *gin.Context,
+	set func(ctx context.Context, id uuid.UUID, lines []models.RecipeLine) error,
+	get func(ctx context.Context, id uuid.UUID) ([]models.RecipeItem, error)) {
+	id, ok := parseIDParam(c, "id", "Invalid ID")
+	if !ok {
+		return
+	}
+
+	var req models.SetRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	for _, line := range req.Items {
+		if line.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Recipe quantities must be greater than zero",
+				Error:   stringPtr("invalid_recipe"),
+			})
+			return
+		}
+	}
+
+	if err := set(c.Request.Context(), id, req.Items); err != nil {
+		writeIngredientError(c, err, "Failed to save recipe")
+		return
+	}
+
+	items, err := get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Recipe saved but failed to fetch details",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Recipe saved successfully",
+		Data:    items,
+	})
+}
+
+func (h *IngredientHandler) respondWithIngredient(c *gin.Context, id uuid.UUID, status int, message string) {
+	ingredient, err := h.repo.GetIngredient(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Ingredient saved but failed to fetch details",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    ingredient,
+	})
+}
+
+func validIngredientAmounts(c *gin.Context, req models.IngredientRequest) bool {
+	if (req.CurrentStock != nil && *req.CurrentStock < 0) ||
+		(req.MinimumStock != nil && *req.MinimumStock < 0) ||
+		(req.UnitCost != nil && *req.UnitCost < 0) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Stock levels and unit cost cannot be negative",
+			Error:   stringPtr("invalid_stock_level"),
+		})
+		return false
+	}
+	return true
+}
+
+func parseIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: message,
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeIngredientError maps repository error codes from ingredient changes to API responses
+func writeIngredientError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case err == sql.ErrNoRows || strings.Contains(msg, "no rows"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Not found",
+			Error:   stringPtr("not_found"),
+		})
+	case strings.Contains(msg, "ingredient_exists"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "An ingredient with this name already exists",
+			Error:   stringPtr("ingredient_exists"),
+		})
+	case strings.Contains(msg, "ingredient_in_use"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Ingredient is used by recipes; remove it from them first",
+			Error:   stringPtr("ingredient_in_use"),
+		})
+	case strings.Contains(msg, "invalid_recipe"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: strings.TrimPrefix(msg, "invalid_recipe: "),
+			Error:   stringPtr("invalid_recipe"),
+		})
+	case strings.Contains(msg, "no_fields"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "No fields to update",
+			Error:   stringPtr("no_fields"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
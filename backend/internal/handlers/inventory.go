@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InventoryHandler struct {
+	repo repository.InventoryRepository
+}
+
+func NewInventoryHandler(repo repository.InventoryRepository) *InventoryHandler {
+	return &InventoryHandler{repo: repo}
+}
+
+// GetInventory lists stock levels, optionally only items at or below their minimum
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	items, err := h.repo.ListInventory(c.Request.Context(), c.Query("low_stock") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch inventory",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Inventory retrieved successfully",
+		Data:    items,
+	})
+}
+
+// GetProductInventory returns the stock level of a single product
+func (h *InventoryHandler) GetProductInventory(c *gin.Context) {
+	productID, ok := parseProductParam(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.repo.GetInventory(c.Request.Context(), productID)
+	if err != nil {
+		writeInventoryError(c, err, "Failed to fetch inventory")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Inventory retrieved successfully",
+		Data:    item,
+	})
+}
+
+// UpsertInventory starts tracking a product or updates its stock limits
+func (h *InventoryHandler) UpsertInventory(c *gin.Context) {
+	productID, ok := parseProductParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.UpsertInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if (req.CurrentStock != nil && *req.CurrentStock < 0) ||
+		(req.MinimumStock != nil && *req.MinimumStock < 0) ||
+		(req.MaximumStock != nil && *req.MaximumStock < 0) ||
+		(req.UnitCost != nil && *req.UnitCost < 0) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Stock levels and unit cost cannot be negative",
+			Error:   stringPtr("invalid_stock_level"),
+		})
+		return
+	}
+
+	if err := h.repo.UpsertInventory(c.Request.Context(), productID, req, userID); err != nil {
+		writeInventoryError(c, err, "Failed to save inventory")
+		return
+	}
+
+	h.respondWithInventory(c, productID, "Inventory saved successfully")
+}
+
+// DeleteInventory stops tracking stock for a product
+func (h *InventoryHandler) DeleteInventory(c *gin.Context) {
+	productID, ok := parseProductParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteInventory(c.Request.Context(), productID); err != nil {
+		writeInventoryError(c, err, "Failed to delete inventory")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock tracking removed successfully",
+	})
+}
+
+// RestockInventory records a stock delivery
+func (h *InventoryHandler) RestockInventory(c *gin.Context) {
+	productID, ok := parseProductParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.RestockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Quantity must be greater than zero",
+			Error:   stringPtr("invalid_quantity"),
+		})
+		return
+	}
+
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Unit cost cannot be negative",
+			Error:   stringPtr("invalid_unit_cost"),
+		})
+		return
+	}
+
+	if err := h.repo.Restock(c.Request.Context(), productID, req, userID); err != nil {
+		writeInventoryError(c, err, "Failed to restock")
+		return
+	}
+
+	h.respondWithInventory(c, productID, "Stock received successfully")
+}
+
+// AdjustInventory applies a manual stock correction with a reason
+func (h *InventoryHandler) AdjustInventory(c *gin.Context) {
+	productID, ok := parseProductParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if (req.QuantityChange == nil) == (req.CountedStock == nil) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Provide either quantity_change or counted_stock",
+			Error:   stringPtr("invalid_adjustment"),
+		})
+		return
+	}
+
+	if req.CountedStock != nil && *req.CountedStock < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Counted stock cannot be negative",
+			Error:   stringPtr("invalid_adjustment"),
+		})
+		return
+	}
+
+	if !repository.IsValidStockAdjustmentReason(req.Reason) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid reason",
+			Error:   stringPtr("invalid_reason"),
+			Data:    gin.H{"valid_reasons": repository.StockAdjustmentReasons()},
+		})
+		return
+	}
+
+	if err := h.repo.AdjustStock(c.Request.Context(), productID, req, userID); err != nil {
+		writeInventoryError(c, err, "Failed to adjust stock")
+		return
+	}
+
+	h.respondWithInventory(c, productID, "Stock adjusted successfully")
+}
+
+// GetInventoryMovements returns the stock ledger with pagination and filtering
+func (h *InventoryHandler) GetInventoryMovements(c *gin.Context) {
+	page := 1
+	perPage := 50
+	movementType := c.Query("movement_type")
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := c.Query("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 200 {
+			perPage = pp
+		}
+	}
+
+	var productID *uuid.UUID
+	if productIDStr := c.Query("product_id"); productIDStr != "" {
+		id, err := uuid.Parse(productIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid product ID",
+				Error:   stringPtr("invalid_uuid"),
+			})
+			return
+		}
+		productID = &id
+	}
+
+	offset := (page - 1) * perPage
+
+	movements, total, err := h.repo.ListMovements(c.Request.Context(), productID, movementType, perPage, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch inventory movements",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Message: "Inventory movements retrieved successfully",
+		Data:    movements,
+		Meta: models.MetaData{
+			CurrentPage: page,
+			PerPage:     perPage,
+			Total:       total,
+			TotalPages:  totalPages,
+		},
+	})
+}
+
+func (h *InventoryHandler) respondWithInventory(c *gin.Context, productID uuid.UUID, message string) {
+	item, err := h.repo.GetInventory(c.Request.Context(), productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Inventory updated but failed to fetch details",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    item,
+	})
+}
+
+func parseProductParam(c *gin.Context) (uuid.UUID, bool) {
+	productID, err := uuid.Parse(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid product ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, false
+	}
+	return productID, true
+}
+
+// writeInventoryError maps repository error codes from stock changes to API responses
+func writeInventoryError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "product_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Product not found",
+			Error:   stringPtr("product_not_found"),
+		})
+	case strings.Contains(msg, "inventory_not_found"), err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Stock is not tracked for this product",
+			Error:   stringPtr("inventory_not_found"),
+		})
+	case strings.Contains(msg, "insufficient_stock"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: strings.TrimPrefix(msg, "insufficient_stock: "),
+			Error:   stringPtr("insufficient_stock"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
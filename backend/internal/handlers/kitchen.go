@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type KitchenHandler struct {
+	repo repository.KitchenRepository
+}
+
+func NewKitchenHandler(repo repository.KitchenRepository) *KitchenHandler {
+	return &KitchenHandler{repo: repo}
+}
+
+// GetStationTickets lists the open items routed to a station, grouped into one ticket per order
+func (h *KitchenHandler) GetStationTickets(c *gin.Context) {
+	stationID, ok := parseStationParam(c)
+	if !ok {
+		return
+	}
+
+	station, err := h.repo.GetStation(c.Request.Context(), stationID)
+	if err != nil {
+		writeKitchenError(c, err, "Failed to fetch station")
+		return
+	}
+
+	tickets, err := h.repo.ListStationTickets(c.Request.Context(), stationID)
+	if err != nil {
+		writeKitchenError(c, err, "Failed to fetch station tickets")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Station tickets retrieved successfully",
+		Data: gin.H{
+			"station": station,
+			"tickets": tickets,
+		},
+	})
+}
+
+// BumpStationTicket marks a station's items on an order as ready
+func (h *KitchenHandler) BumpStationTicket(c *gin.Context) {
+	stationID, ok := parseStationParam(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	if _, err := h.repo.GetStation(c.Request.Context(), stationID); err != nil {
+		writeKitchenError(c, err, "Failed to fetch station")
+		return
+	}
+
+	status, err := h.repo.BumpStationTicket(c.Request.Context(), stationID, orderID, userID)
+	if err != nil {
+		writeKitchenError(c, err, "Failed to bump station ticket")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Station ticket bumped successfully",
+		Data: gin.H{
+			"order_id":     orderID,
+			"station_id":   stationID,
+			"order_status": status,
+		},
+	})
+}
+
+// UpdateItemStatus sets the kitchen status of an order item; the order status follows its items
+func (h *KitchenHandler) UpdateItemStatus(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid item ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if !repository.IsValidItemStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order item status",
+			Error:   stringPtr("invalid_status"),
+		})
+		return
+	}
+
+	orderStatus, err := h.repo.UpdateItemStatus(c.Request.Context(), orderID, itemID, req.Status, userID)
+	if err != nil {
+		writeKitchenError(c, err, "Failed to update order item status")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Order item status updated successfully",
+		Data: gin.H{
+			"order_id":     orderID,
+			"item_id":      itemID,
+			"status":       req.Status,
+			"order_status": orderStatus,
+		},
+	})
+}
+
+func parseStationParam(c *gin.Context) (uuid.UUID, bool) {
+	stationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid station ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, false
+	}
+	return stationID, true
+}
+
+// writeKitchenError maps repository error codes from kitchen operations to API responses
+func writeKitchenError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "station_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Kitchen station not found",
+			Error:   stringPtr("station_not_found"),
+		})
+	case strings.Contains(msg, "order_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order not found",
+			Error:   stringPtr("order_not_found"),
+		})
+	case strings.Contains(msg, "order_item_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order item not found",
+			Error:   stringPtr("order_item_not_found"),
+		})
+	case strings.Contains(msg, "order_item_voided"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Voided items cannot be prepared",
+			Error:   stringPtr("order_item_voided"),
+		})
+	case strings.Contains(msg, "order_item_held"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Item is held; " + strings.TrimPrefix(msg, "order_item_held: "),
+			Error:   stringPtr("order_item_held"),
+		})
+	case strings.Contains(msg, "station_ticket_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "No open items for this station on the order",
+			Error:   stringPtr("station_ticket_not_found"),
+		})
+	case strings.Contains(msg, "order_not_in_kitchen"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order is no longer in the kitchen",
+			Error:   stringPtr("order_not_in_kitchen"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VoidOrderItem voids an order item, removing its charge from the order
+func (h *OrderHandler) VoidOrderItem(c *gin.Context) {
+	h.adjustOrderItem(c, repository.AdjustmentVoid)
+}
+
+// CompOrderItem comps an order item so the guest is not charged for it
+func (h *OrderHandler) CompOrderItem(c *gin.Context) {
+	h.adjustOrderItem(c, repository.AdjustmentComp)
+}
+
+// adjustOrderItem applies a void or comp after checking the reason code and manager approval
+func (h *OrderHandler) adjustOrderItem(c *gin.Context, adjustmentType string) {
+	orderID, itemID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.AdjustOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if !repository.IsValidAdjustmentReason(req.ReasonCode) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid reason code",
+			Error:   stringPtr("invalid_reason_code"),
+			Data:    gin.H{"valid_reason_codes": repository.AdjustmentReasonCodes()},
+		})
+		return
+	}
+
+	// Managers approve their own adjustments; everyone else needs a manager's sign-off
+	approvedBy := userID
+	if !repository.IsApproverRole(role) {
+		if req.Approval == nil {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Manager approval is required",
+				Error:   stringPtr("approval_required"),
+			})
+			return
+		}
+
+		approverID, err := h.repo.VerifyManagerApproval(c.Request.Context(), *req.Approval, userID)
+		if err != nil {
+			writeOrderItemError(c, err, "Failed to verify manager approval")
+			return
+		}
+		approvedBy = approverID
+	}
+
+	if err := h.repo.AdjustOrderItem(c.Request.Context(), orderID, itemID, adjustmentType, req.ReasonCode, req.Notes, userID, approvedBy); err != nil {
+		writeOrderItemError(c, err, "Failed to "+adjustmentType+" order item")
+		return
+	}
+
+	message := "Order item voided successfully"
+	if adjustmentType == repository.AdjustmentComp {
+		message = "Order item comped successfully"
+	}
+	h.respondWithOrder(c, orderID, http.StatusOK, message)
+}
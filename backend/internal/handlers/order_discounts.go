@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ApplyOrderDiscount applies a promotion to an open order
+func (h *OrderHandler) ApplyOrderDiscount(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.ApplyDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if _, err := h.repo.ApplyDiscount(c.Request.Context(), orderID, req.PromotionID, userID); err != nil {
+		writeOrderDiscountError(c, err, "Failed to apply discount")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusCreated, "Discount applied successfully")
+}
+
+// RemoveOrderDiscount removes an applied promotion from an open order
+func (h *OrderHandler) RemoveOrderDiscount(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	discountID, err := uuid.Parse(c.Param("discount_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid discount ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	if err := h.repo.RemoveDiscount(c.Request.Context(), orderID, discountID); err != nil {
+		writeOrderDiscountError(c, err, "Failed to remove discount")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusOK, "Discount removed successfully")
+}
+
+// writeOrderDiscountError maps repository error codes from promotions to API responses
+func writeOrderDiscountError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "promotion_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Promotion not found",
+			Error:   stringPtr("promotion_not_found"),
+		})
+	case strings.Contains(msg, "discount_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Discount not found on this order",
+			Error:   stringPtr("discount_not_found"),
+		})
+	case strings.Contains(msg, "promotion_not_active"),
+		strings.Contains(msg, "min_spend_not_met"),
+		strings.Contains(msg, "promotion_not_stackable"),
+		strings.Contains(msg, "promotion_already_applied"),
+		strings.Contains(msg, "promotion_not_applicable"):
+		code := msg[:strings.Index(msg, ":")]
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: strings.TrimSpace(msg[len(code)+1:]),
+			Error:   stringPtr(code),
+		})
+	default:
+		writeOrderItemError(c, err, fallbackMessage)
+	}
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AddOrderItem adds an item to an existing open order
+func (h *OrderHandler) AddOrderItem(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.CreateOrderItem
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Quantity must be greater than zero",
+			Error:   stringPtr("invalid_quantity"),
+		})
+		return
+	}
+
+	if req.Course < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Course must be a positive number",
+			Error:   stringPtr("invalid_course"),
+		})
+		return
+	}
+
+	if _, err := h.repo.AddOrderItem(c.Request.Context(), orderID, req, userID); err != nil {
+		writeOrderItemError(c, err, "Failed to add order item")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusCreated, "Order item added successfully")
+}
+
+// UpdateOrderItem changes the quantity or instructions of an order item
+func (h *OrderHandler) UpdateOrderItem(c *gin.Context) {
+	orderID, itemID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.UpdateOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.Quantity == nil && req.SpecialInstructions == nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "No fields to update",
+			Error:   stringPtr("no_fields"),
+		})
+		return
+	}
+
+	if req.Quantity != nil && *req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Quantity must be greater than zero; remove the item instead",
+			Error:   stringPtr("invalid_quantity"),
+		})
+		return
+	}
+
+	if err := h.repo.UpdateOrderItem(c.Request.Context(), orderID, itemID, req, userID); err != nil {
+		writeOrderItemError(c, err, "Failed to update order item")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusOK, "Order item updated successfully")
+}
+
+// RemoveOrderItem removes an item from an open order
+func (h *OrderHandler) RemoveOrderItem(c *gin.Context) {
+	orderID, itemID, ok := parseOrderItemParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	// The body is optional for DELETE requests
+	var req models.RemoveOrderItemRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid request body",
+				Error:   stringPtr(err.Error()),
+			})
+			return
+		}
+	}
+
+	if err := h.repo.RemoveOrderItem(c.Request.Context(), orderID, itemID, userID, req.Notes); err != nil {
+		writeOrderItemError(c, err, "Failed to remove order item")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusOK, "Order item removed successfully")
+}
+
+// FireCourse sends the held items of a course to the kitchen
+func (h *OrderHandler) FireCourse(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	course, err := strconv.Atoi(c.Param("course"))
+	if err != nil || course < 1 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Course must be a positive number",
+			Error:   stringPtr("invalid_course"),
+		})
+		return
+	}
+
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	if _, err := h.repo.FireCourse(c.Request.Context(), orderID, course, userID); err != nil {
+		writeOrderItemError(c, err, "Failed to fire course")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusOK, "Course fired successfully")
+}
+
+// UpdateGuestCount changes the party size of an open order, which may change its service charge
+func (h *OrderHandler) UpdateGuestCount(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	var req models.UpdateGuestCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	if req.GuestCount < 1 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Guest count must be at least 1",
+			Error:   stringPtr("invalid_guest_count"),
+		})
+		return
+	}
+
+	if err := h.repo.SetGuestCount(c.Request.Context(), orderID, req.GuestCount); err != nil {
+		writeOrderItemError(c, err, "Failed to update guest count")
+		return
+	}
+
+	h.respondWithOrder(c, orderID, http.StatusOK, "Guest count updated successfully")
+}
+
+// GetOrderItemChanges returns the item edit log for an order
+func (h *OrderHandler) GetOrderItemChanges(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	changes, err := h.repo.ListOrderItemChanges(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch order item changes",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Order item changes retrieved successfully",
+		Data:    changes,
+	})
+}
+
+// respondWithOrder reloads the order after a change and writes it to the response
+func (h *OrderHandler) respondWithOrder(c *gin.Context, orderID uuid.UUID, status int, message string) {
+	order, err := h.repo.GetOrderByID(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Order updated but failed to fetch details",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    order,
+	})
+}
+
+func parseOrderItemParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order item ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orderID, itemID, true
+}
+
+// writeOrderItemError maps repository error codes from item edits to API responses
+func writeOrderItemError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "order_item_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order item not found",
+			Error:   stringPtr("order_item_not_found"),
+		})
+	case strings.Contains(msg, "no rows"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order not found",
+			Error:   stringPtr("order_not_found"),
+		})
+	case strings.Contains(msg, "product_not_found"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Product not found or not available",
+			Error:   stringPtr("product_not_found"),
+		})
+	case strings.Contains(msg, "invalid_modifiers"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: strings.TrimPrefix(msg, "invalid_modifiers: "),
+			Error:   stringPtr("invalid_modifiers"),
+		})
+	case strings.Contains(msg, "insufficient_stock"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: strings.TrimPrefix(msg, "insufficient_stock: "),
+			Error:   stringPtr("insufficient_stock"),
+		})
+	case strings.Contains(msg, "business_day_closed"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order belongs to a closed business day",
+			Error:   stringPtr("business_day_closed"),
+		})
+	case strings.Contains(msg, "order_not_editable"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order can no longer be edited",
+			Error:   stringPtr("order_not_editable"),
+		})
+	case strings.Contains(msg, "order_has_payments"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order items cannot be changed once payment has started",
+			Error:   stringPtr("order_has_payments"),
+		})
+	case strings.Contains(msg, "order_item_in_progress"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Item is already being prepared",
+			Error:   stringPtr("order_item_in_progress"),
+		})
+	case strings.Contains(msg, "last_order_item"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Cannot remove the last item; cancel the order instead",
+			Error:   stringPtr("last_order_item"),
+		})
+	case strings.Contains(msg, "order_item_already_adjusted"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Item has already been voided or comped",
+			Error:   stringPtr("order_item_already_adjusted"),
+		})
+	case strings.Contains(msg, "hold_not_allowed"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Only dine-in items can be held",
+			Error:   stringPtr("hold_not_allowed"),
+		})
+	case strings.Contains(msg, "course_not_held"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "No held items in this course",
+			Error:   stringPtr("course_not_held"),
+		})
+	case strings.Contains(msg, "order_not_in_kitchen"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order is no longer in the kitchen",
+			Error:   stringPtr("order_not_in_kitchen"),
+		})
+	case strings.Contains(msg, "approval_required"):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Manager approval is required",
+			Error:   stringPtr("approval_required"),
+		})
+	case strings.Contains(msg, "approval_locked"):
+		c.JSON(http.StatusTooManyRequests, models.APIResponse{
+			Success: false,
+			Message: "Manager approval is locked; " + strings.TrimPrefix(msg, "approval_locked: "),
+			Error:   stringPtr("approval_locked"),
+		})
+	case strings.Contains(msg, "invalid_approval"):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Manager approval could not be verified",
+			Error:   stringPtr("invalid_approval"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
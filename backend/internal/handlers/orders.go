@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -139,6 +140,15 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	if req.GuestCount != nil && *req.GuestCount < 1 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Guest count must be at least 1",
+			Error:   stringPtr("invalid_guest_count"),
+		})
+		return
+	}
+
 	// Generate order number
 	orderNumber := h.generateOrderNumber()
 
@@ -153,6 +163,38 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 			})
 			return
 		}
+		if strings.Contains(err.Error(), "insufficient_stock") {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: strings.TrimPrefix(err.Error(), "insufficient_stock: "),
+				Error:   stringPtr("insufficient_stock"),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid_modifiers") {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: strings.TrimPrefix(err.Error(), "invalid_modifiers: "),
+				Error:   stringPtr("invalid_modifiers"),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "hold_not_allowed") {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Only dine-in items can be held",
+				Error:   stringPtr("hold_not_allowed"),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "business_day_closed") {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: "The business day was closed while the order was being taken; please try again",
+				Error:   stringPtr("business_day_closed"),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Message: "Failed to create order",
@@ -190,7 +232,7 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	userID, _, _, ok := middleware.GetUserFromContext(c)
+	userID, _, role, ok := middleware.GetUserFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, models.APIResponse{
 			Success: false,
@@ -211,16 +253,7 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	}
 
 	// Validate status
-	validStatuses := []string{"pending", "confirmed", "preparing", "ready", "served", "completed", "cancelled"}
-	isValidStatus := false
-	for _, status := range validStatuses {
-		if req.Status == status {
-			isValidStatus = true
-			break
-		}
-	}
-
-	if !isValidStatus {
+	if !repository.IsValidOrderStatus(req.Status) {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Message: "Invalid order status",
@@ -229,7 +262,37 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.UpdateOrderStatus(c.Request.Context(), orderID, req.Status, userID, req.Notes); err != nil {
+	if err := h.repo.UpdateOrderStatus(c.Request.Context(), orderID, req.Status, userID, role, req.Notes); err != nil {
+		var transitionErr *repository.TransitionError
+		if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: fmt.Sprintf("Cannot change order status from %s to %s", transitionErr.From, transitionErr.To),
+				Error:   stringPtr("invalid_transition"),
+				Data: gin.H{
+					"current_status":      transitionErr.From,
+					"requested_status":    transitionErr.To,
+					"allowed_transitions": repository.AllowedTransitions(transitionErr.From, role),
+				},
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "stations_pending") {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: "Waiting on kitchen stations: " + strings.TrimPrefix(err.Error(), "stations_pending: "),
+				Error:   stringPtr("stations_pending"),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "business_day_closed") {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Message: "Order belongs to a closed business day",
+				Error:   stringPtr("business_day_closed"),
+			})
+			return
+		}
 		if strings.Contains(err.Error(), "no rows") {
 			c.JSON(http.StatusNotFound, models.APIResponse{
 				Success: false,
@@ -263,6 +326,57 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	})
 }
 
+// GetOrderTransitions lists the statuses the current user may move an order to
+func (h *OrderHandler) GetOrderTransitions(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	_, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	status, err := h.repo.GetOrderStatus(c.Request.Context(), orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Order not found",
+				Error:   stringPtr("order_not_found"),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch order status",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Order transitions retrieved successfully",
+		Data: gin.H{
+			"order_id":            orderID,
+			"current_status":      status,
+			"allowed_transitions": repository.AllowedTransitions(status, role),
+		},
+	})
+}
+
 func (h *OrderHandler) generateOrderNumber() string {
 	timestamp := time.Now().Format("20060102")
 	return fmt.Sprintf("ORD%s%04d", timestamp, time.Now().UnixNano()%10000)
@@ -2,22 +2,28 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"pos-backend/internal/events"
+	"pos-backend/internal/gateway"
 	"pos-backend/internal/middleware"
 	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type PaymentHandler struct {
-	db *sql.DB
+	db      *sql.DB
+	gateway gateway.PaymentGateway
 }
 
-func NewPaymentHandler(db *sql.DB) *PaymentHandler {
-	return &PaymentHandler{db: db}
+func NewPaymentHandler(db *sql.DB, gw gateway.PaymentGateway) *PaymentHandler {
+	return &PaymentHandler{db: db, gateway: gw}
 }
 
 // ProcessPayment processes a payment for an order
@@ -71,7 +77,17 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	if req.Amount <= 0 {
+	if req.TenderedAmount != nil && req.PaymentMethod != "cash" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "A tendered amount is only accepted for cash payments",
+			Error:   stringPtr("tender_not_cash"),
+		})
+		return
+	}
+
+	// Cash with a tendered amount may leave the amount out and apply the whole tender
+	if req.Amount < 0 || (req.Amount == 0 && req.TenderedAmount == nil) {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Message: "Payment amount must be greater than zero",
@@ -80,6 +96,15 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
+	if req.TipAmount < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Tip amount cannot be negative",
+			Error:   stringPtr("invalid_tip"),
+		})
+		return
+	}
+
 	// Start transaction
 	tx, err := h.db.Begin()
 	if err != nil {
@@ -92,11 +117,9 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// Check if order exists and get total amount
-	var orderTotalAmount float64
-	var orderStatus string
-	err = tx.QueryRow("SELECT total_amount, status FROM orders WHERE id = $1", orderID).Scan(&orderTotalAmount, &orderStatus)
-	if err == sql.ErrNoRows {
+	// Lock the order before working out its balance; a card is only charged under the lock
+	orderTotalAmount, orderStatus, err := repository.LockPayableOrder(c.Request.Context(), tx, orderID)
+	if err != nil && strings.HasPrefix(err.Error(), "order_not_found") {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Message: "Order not found",
@@ -123,13 +146,8 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	// Check if order is already fully paid
-	var totalPaid float64
-	err = tx.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) 
-		FROM payments 
-		WHERE order_id = $1 AND status = 'completed'
-	`, orderID).Scan(&totalPaid)
+	// Check if order is already fully paid (refunds reopen the balance)
+	totalPaid, err := repository.OrderNetPaid(c.Request.Context(), tx, orderID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -148,9 +166,26 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	// Check if payment amount doesn't exceed remaining balance
+	// Check if payment amount doesn't exceed remaining balance; tips are taken on top of it.
+	// Cash over the balance is given back as change instead.
 	remainingAmount := orderTotalAmount - totalPaid
-	if req.Amount > remainingAmount {
+	var tender cashTender
+	if req.PaymentMethod == "cash" {
+		settings, err := repository.GetStoreSettings(c.Request.Context(), tx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to fetch store settings",
+				Error:   stringPtr(err.Error()),
+			})
+			return
+		}
+		if tender, err = tenderCash(req, remainingAmount, settings.CashRounding); err != nil {
+			writeTenderError(c, err)
+			return
+		}
+		req.Amount = tender.Amount
+	} else if req.Amount > remainingAmount {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Message: "Payment amount exceeds remaining balance",
@@ -159,25 +194,50 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
+	// Payments are tied to the drawer session open at the time, for reconciliation
+	drawerSessionID, err := repository.ActiveDrawerSession(c.Request.Context(), tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to find drawer session",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
 	// Create payment record
 	paymentID := uuid.New()
 	now := time.Now()
+	committed := false
 
-	paymentQuery := `
-		INSERT INTO payments (id, order_id, payment_method, amount, reference_number, status, processed_by, processed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
+	if req.PaymentMethod == "cash" {
+		_, err = tx.Exec(`
+			INSERT INTO payments (id, order_id, payment_method, amount, tip_amount, tendered_amount, change_given, rounding_amount,
+			                      reference_number, status, drawer_session_id, processed_by, processed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'completed', $10, $11, $12)
+		`, paymentID, orderID, req.PaymentMethod, req.Amount, req.TipAmount, tender.Tendered, tender.Change, tender.Rounding,
+			req.ReferenceNumber, drawerSessionID, userID, now)
+	} else {
+		// Card payments are charged through the gateway before the order is settled
+		reference, chargeErr := h.chargeCard(c.Request.Context(), paymentID, orderID, userID, drawerSessionID, req)
+		if chargeErr != nil {
+			writeGatewayError(c, chargeErr, paymentID)
+			return
+		}
+		// Give the money back if the payment cannot be recorded against the order. The
+		// transaction is rolled back first so it no longer holds the payment row.
+		defer func() {
+			if !committed {
+				tx.Rollback()
+				h.reverseCharge(paymentID, reference, req.Amount+req.TipAmount)
+			}
+		}()
 
-	// Simulate payment processing
-	paymentStatus := "completed"
-	if req.PaymentMethod != "cash" {
-		// For non-cash payments, we simulate processing
-		// In a real system, this would integrate with payment processors
-		paymentStatus = "completed" // Simulating successful processing
+		_, err = tx.Exec(`
+			UPDATE payments SET status = 'completed', reference_number = $1, processed_at = $2
+			WHERE id = $3
+		`, reference, now, paymentID)
 	}
-
-	_, err = tx.Exec(paymentQuery, paymentID, orderID, req.PaymentMethod, req.Amount,
-		req.ReferenceNumber, paymentStatus, userID, now)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -187,15 +247,12 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	// Check if order is now fully paid
+	// A fully paid order is completed when its status allows it; one the kitchen has not
+	// finished stays open and is completed by the usual status change once it is served
 	newTotalPaid := totalPaid + req.Amount
+	completed := false
 	if newTotalPaid >= orderTotalAmount {
-		// Update order status to completed if fully paid
-		_, err = tx.Exec(`
-			UPDATE orders 
-			SET status = 'completed', completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
-			WHERE id = $1
-		`, orderID)
+		completed, err = repository.CompletePaidOrder(c.Request.Context(), tx, orderID, orderStatus, userID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Success: false,
@@ -204,6 +261,17 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 			})
 			return
 		}
+	}
+
+	if completed {
+		if err := repository.ConsumeOrderIngredients(c.Request.Context(), tx, orderID, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Message: "Failed to consume order ingredients",
+				Error:   stringPtr(err.Error()),
+			})
+			return
+		}
 
 		// Free up the table
 		_, err = tx.Exec(`
@@ -215,16 +283,6 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 			// Log error but don't fail the transaction
 			// fmt.Printf("Warning: Failed to update table status: %v\n", err)
 		}
-
-		// Log status change
-		_, err = tx.Exec(`
-			INSERT INTO order_status_history (order_id, previous_status, new_status, changed_by, notes)
-			VALUES ($1, $2, 'completed', $3, 'Order completed after payment')
-		`, orderID, orderStatus, userID)
-		if err != nil {
-			// Log error but don't fail the transaction
-			// fmt.Printf("Warning: Failed to log status change: %v\n", err)
-		}
 	}
 
 	// Commit transaction
@@ -236,6 +294,25 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		})
 		return
 	}
+	committed = true
+
+	fullyPaid := newTotalPaid >= orderTotalAmount
+	events.Publish(events.PaymentCompleted, orderID, nil, gin.H{
+		"payment_id":     paymentID,
+		"payment_method": req.PaymentMethod,
+		"amount":         req.Amount,
+		"tip_amount":     req.TipAmount,
+		"change_given":   tender.Change,
+		"total_paid":     newTotalPaid,
+		"fully_paid":     fullyPaid,
+	})
+	if completed {
+		events.Publish(events.OrderStatusChanged, orderID, nil, gin.H{
+			"previous_status": orderStatus,
+			"status":          "completed",
+			"changed_by":      userID,
+		})
+	}
 
 	// Fetch the created payment
 	payment, err := h.getPaymentByID(paymentID)
@@ -248,9 +325,14 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
+	message := "Payment processed successfully"
+	if tender.Change > 0 {
+		message += fmt.Sprintf("; change due %.2f", tender.Change)
+	}
+
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
-		Message: "Payment processed successfully",
+		Message: message,
 		Data:    payment,
 	})
 }
@@ -290,7 +372,9 @@ func (h *PaymentHandler) GetPayments(c *gin.Context) {
 
 	// Fetch payments
 	query := `
-		SELECT p.id, p.payment_method, p.amount, p.reference_number, p.status, 
+		SELECT p.id, p.payment_method, p.amount, p.tip_amount, p.tendered_amount, p.change_given, p.rounding_amount,
+		       p.reference_number, p.status, p.drawer_session_id,
+		       COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'completed'), 0),
 		       p.processed_by, p.processed_at, p.created_at,
 		       u.username, u.first_name, u.last_name
 		FROM payments p
@@ -316,8 +400,9 @@ func (h *PaymentHandler) GetPayments(c *gin.Context) {
 		var username, firstName, lastName sql.NullString
 
 		err := rows.Scan(
-			&payment.ID, &payment.PaymentMethod, &payment.Amount, &payment.ReferenceNumber,
-			&payment.Status, &payment.ProcessedBy, &payment.ProcessedAt, &payment.CreatedAt,
+			&payment.ID, &payment.PaymentMethod, &payment.Amount, &payment.TipAmount,
+			&payment.TenderedAmount, &payment.ChangeGiven, &payment.RoundingAmount, &payment.ReferenceNumber,
+			&payment.Status, &payment.DrawerSessionID, &payment.RefundedAmount, &payment.ProcessedBy, &payment.ProcessedAt, &payment.CreatedAt,
 			&username, &firstName, &lastName,
 		)
 		if err != nil {
@@ -366,19 +451,21 @@ func (h *PaymentHandler) GetPaymentSummary(c *gin.Context) {
 	query := `
 		SELECT 
 		    o.total_amount,
-		    COALESCE(SUM(CASE WHEN p.status = 'completed' THEN p.amount ELSE 0 END), 0) as total_paid,
+		    COALESCE(SUM(CASE WHEN p.status IN ('completed', 'refunded') THEN p.amount ELSE 0 END), 0) as total_payments,
 		    COALESCE(SUM(CASE WHEN p.status = 'pending' THEN p.amount ELSE 0 END), 0) as pending_amount,
-		    COUNT(p.id) as payment_count
+		    COUNT(p.id) as payment_count,
+		    COALESCE(SUM(CASE WHEN p.status IN ('completed', 'refunded') THEN p.tip_amount ELSE 0 END), 0) as total_tips,
+		    COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.order_id = o.id AND r.status = 'completed'), 0) as total_refunded
 		FROM orders o
 		LEFT JOIN payments p ON o.id = p.order_id
 		WHERE o.id = $1
 		GROUP BY o.id, o.total_amount
 	`
 
-	var totalAmount, totalPaid, pendingAmount float64
+	var totalAmount, totalPayments, pendingAmount, totalTips, totalRefunded float64
 	var paymentCount int
 
-	err = h.db.QueryRow(query, orderID).Scan(&totalAmount, &totalPaid, &pendingAmount, &paymentCount)
+	err = h.db.QueryRow(query, orderID).Scan(&totalAmount, &totalPayments, &pendingAmount, &paymentCount, &totalTips, &totalRefunded)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
@@ -396,6 +483,7 @@ func (h *PaymentHandler) GetPaymentSummary(c *gin.Context) {
 		return
 	}
 
+	totalPaid := totalPayments - totalRefunded
 	remainingAmount := totalAmount - totalPaid
 	isFullyPaid := remainingAmount <= 0
 
@@ -403,6 +491,8 @@ func (h *PaymentHandler) GetPaymentSummary(c *gin.Context) {
 		"order_id":         orderID,
 		"total_amount":     totalAmount,
 		"total_paid":       totalPaid,
+		"total_refunded":   totalRefunded,
+		"total_tips":       totalTips,
 		"pending_amount":   pendingAmount,
 		"remaining_amount": remainingAmount,
 		"is_fully_paid":    isFullyPaid,
@@ -423,7 +513,9 @@ func (h *PaymentHandler) getPaymentByID(paymentID uuid.UUID) (*models.Payment, e
 	var username, firstName, lastName sql.NullString
 
 	query := `
-		SELECT p.id, p.order_id, p.payment_method, p.amount, p.reference_number, p.status, 
+		SELECT p.id, p.order_id, p.payment_method, p.amount, p.tip_amount, p.tendered_amount, p.change_given, p.rounding_amount,
+		       p.reference_number, p.status, p.drawer_session_id,
+		       COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'completed'), 0),
 		       p.processed_by, p.processed_at, p.created_at,
 		       u.username, u.first_name, u.last_name
 		FROM payments p
@@ -432,8 +524,8 @@ func (h *PaymentHandler) getPaymentByID(paymentID uuid.UUID) (*models.Payment, e
 	`
 
 	err := h.db.QueryRow(query, paymentID).Scan(
-		&payment.ID, &payment.OrderID, &payment.PaymentMethod, &payment.Amount,
-		&payment.ReferenceNumber, &payment.Status, &payment.ProcessedBy,
+		&payment.ID, &payment.OrderID, &payment.PaymentMethod, &payment.Amount, &payment.TipAmount,
+		&payment.TenderedAmount, &payment.ChangeGiven, &payment.RoundingAmount, &payment.ReferenceNumber, &payment.Status, &payment.DrawerSessionID, &payment.RefundedAmount, &payment.ProcessedBy,
 		&payment.ProcessedAt, &payment.CreatedAt,
 		&username, &firstName, &lastName,
 	)
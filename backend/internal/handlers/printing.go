@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/printing"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PrintHandler struct {
+	jobs  repository.PrintJobRepository
+	queue *printing.Queue
+}
+
+func NewPrintHandler(jobs repository.PrintJobRepository, queue *printing.Queue) *PrintHandler {
+	return &PrintHandler{jobs: jobs, queue: queue}
+}
+
+// GetPrintJobs lists spooled print jobs, optionally filtered by status or order
+func (h *PrintHandler) GetPrintJobs(c *gin.Context) {
+	page := 1
+	perPage := 50
+	status := c.Query("status")
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if perPageStr := c.Query("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 100 {
+			perPage = pp
+		}
+	}
+
+	var orderID *uuid.UUID
+	if orderStr := c.Query("order_id"); orderStr != "" {
+		id, err := uuid.Parse(orderStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid order ID",
+				Error:   stringPtr("invalid_uuid"),
+			})
+			return
+		}
+		orderID = &id
+	}
+
+	jobs, total, err := h.jobs.ListJobs(c.Request.Context(), status, orderID, perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch print jobs",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Message: "Print jobs retrieved successfully",
+		Data:    jobs,
+		Meta: models.MetaData{
+			CurrentPage: page,
+			PerPage:     perPage,
+			Total:       total,
+			TotalPages:  (total + perPage - 1) / perPage,
+		},
+	})
+}
+
+// RetryPrintJob requeues a job the spool gave up on
+func (h *PrintHandler) RetryPrintJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid print job ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	if err := h.jobs.RetryJob(c.Request.Context(), jobID); err != nil {
+		writePrintError(c, err, "Failed to retry print job")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Print job queued for retry",
+		Data:    gin.H{"job_id": jobID},
+	})
+}
+
+// PrintTestPage queues a test page on a printer
+func (h *PrintHandler) PrintTestPage(c *gin.Context) {
+	printerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid printer ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	jobID, err := h.queue.PrintTest(c.Request.Context(), printerID, currentUserID(c))
+	if err != nil {
+		writePrintError(c, err, "Failed to queue test page")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: "Test page queued",
+		Data:    gin.H{"job_id": jobID},
+	})
+}
+
+// PrintKitchenTickets reprints the kitchen tickets of an order's fired items
+func (h *PrintHandler) PrintKitchenTickets(c *gin.Context) {
+	orderID, ok := parseOrderParam(c)
+	if !ok {
+		return
+	}
+
+	queued, err := h.queue.PrintKitchenTickets(c.Request.Context(), orderID, nil, currentUserID(c))
+	if err != nil {
+		writePrintError(c, err, "Failed to queue kitchen tickets")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: "Kitchen tickets queued",
+		Data:    gin.H{"order_id": orderID, "tickets": queued},
+	})
+}
+
+// PrintReceipt prints a customer receipt for an order
+func (h *PrintHandler) PrintReceipt(c *gin.Context) {
+	orderID, ok := parseOrderParam(c)
+	if !ok {
+		return
+	}
+
+	jobID, err := h.queue.PrintReceipt(c.Request.Context(), orderID, currentUserID(c))
+	if err != nil {
+		writePrintError(c, err, "Failed to queue receipt")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: "Receipt queued",
+		Data:    gin.H{"order_id": orderID, "job_id": jobID},
+	})
+}
+
+func parseOrderParam(c *gin.Context) (uuid.UUID, bool) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return uuid.Nil, false
+	}
+	return orderID, true
+}
+
+// currentUserID returns the authenticated user, if any, for recording who queued a job
+func currentUserID(c *gin.Context) *uuid.UUID {
+	userID, _, _, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return nil
+	}
+	return &userID
+}
+
+// writePrintError maps repository error codes from printing operations to API responses
+func writePrintError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "printer_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Printer not found",
+			Error:   stringPtr("printer_not_found"),
+		})
+	case strings.Contains(msg, "no rows"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order not found",
+			Error:   stringPtr("order_not_found"),
+		})
+	case strings.Contains(msg, "print_job_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Print job not found",
+			Error:   stringPtr("print_job_not_found"),
+		})
+	case strings.Contains(msg, "print_job_not_failed"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Only failed print jobs can be retried",
+			Error:   stringPtr("print_job_not_failed"),
+		})
+	case strings.Contains(msg, "no_receipt_printer"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "No receipt printer is configured",
+			Error:   stringPtr("no_receipt_printer"),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
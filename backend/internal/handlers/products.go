@@ -45,7 +45,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	// Build query with filters
 	queryBuilder := `
 		SELECT p.id, p.category_id, p.name, p.description, p.price, p.image_url, 
-		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order,
+		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order, p.tax_class_id, p.station_id,
 		       p.created_at, p.updated_at,
 		       c.name as category_name, c.color as category_color
 		FROM products p
@@ -117,7 +117,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		err := rows.Scan(
 			&product.ID, &product.CategoryID, &product.Name, &product.Description,
 			&product.Price, &product.ImageURL, &product.Barcode, &product.SKU,
-			&product.IsAvailable, &product.PreparationTime, &product.SortOrder,
+			&product.IsAvailable, &product.PreparationTime, &product.SortOrder, &product.TaxClassID, &product.StationID,
 			&product.CreatedAt, &product.UpdatedAt,
 			&categoryName, &categoryColor,
 		)
@@ -174,7 +174,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	query := `
 		SELECT p.id, p.category_id, p.name, p.description, p.price, p.image_url, 
-		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order,
+		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order, p.tax_class_id, p.station_id,
 		       p.created_at, p.updated_at,
 		       c.name as category_name, c.color as category_color
 		FROM products p
@@ -185,7 +185,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	err = h.db.QueryRow(query, productID).Scan(
 		&product.ID, &product.CategoryID, &product.Name, &product.Description,
 		&product.Price, &product.ImageURL, &product.Barcode, &product.SKU,
-		&product.IsAvailable, &product.PreparationTime, &product.SortOrder,
+		&product.IsAvailable, &product.PreparationTime, &product.SortOrder, &product.TaxClassID, &product.StationID,
 		&product.CreatedAt, &product.UpdatedAt,
 		&categoryName, &categoryColor,
 	)
@@ -217,6 +217,17 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		}
 	}
 
+	modifierGroups, err := h.loadProductModifierGroups(product.ID, product.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch product modifiers",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+	product.ModifierGroups = modifierGroups
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Product retrieved successfully",
@@ -224,12 +235,76 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	})
 }
 
+// loadProductModifierGroups returns the active modifier groups offered on a product,
+// either directly or through its category, with their available modifiers
+func (h *ProductHandler) loadProductModifierGroups(productID uuid.UUID, categoryID *uuid.UUID) ([]models.ModifierGroup, error) {
+	rows, err := h.db.Query(`
+		SELECT g.id, g.name, g.description, g.min_select, g.max_select, g.sort_order, g.is_active,
+		       g.created_at, g.updated_at
+		FROM modifier_groups g
+		LEFT JOIN product_modifier_groups pg ON pg.modifier_group_id = g.id AND pg.product_id = $1
+		LEFT JOIN category_modifier_groups cg ON cg.modifier_group_id = g.id AND cg.category_id = $2
+		WHERE g.is_active = true AND (pg.product_id IS NOT NULL OR cg.category_id IS NOT NULL)
+		ORDER BY COALESCE(pg.sort_order, cg.sort_order), g.sort_order, g.name
+	`, productID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.ModifierGroup{}
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		var group models.ModifierGroup
+		if err := rows.Scan(&group.ID, &group.Name, &group.Description, &group.MinSelect, &group.MaxSelect,
+			&group.SortOrder, &group.IsActive, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, err
+		}
+		group.Modifiers = []models.Modifier{}
+		index[group.ID] = len(groups)
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	modifierRows, err := h.db.Query(`
+		SELECT m.id, m.modifier_group_id, m.name, m.price_delta, m.is_available, m.sort_order,
+		       m.created_at, m.updated_at
+		FROM modifiers m
+		LEFT JOIN product_modifier_groups pg ON pg.modifier_group_id = m.modifier_group_id AND pg.product_id = $1
+		LEFT JOIN category_modifier_groups cg ON cg.modifier_group_id = m.modifier_group_id AND cg.category_id = $2
+		WHERE m.is_available = true AND (pg.product_id IS NOT NULL OR cg.category_id IS NOT NULL)
+		ORDER BY m.sort_order, m.name
+	`, productID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer modifierRows.Close()
+
+	for modifierRows.Next() {
+		var modifier models.Modifier
+		if err := modifierRows.Scan(&modifier.ID, &modifier.ModifierGroupID, &modifier.Name, &modifier.PriceDelta,
+			&modifier.IsAvailable, &modifier.SortOrder, &modifier.CreatedAt, &modifier.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := index[modifier.ModifierGroupID]; ok {
+			groups[i].Modifiers = append(groups[i].Modifiers, modifier)
+		}
+	}
+
+	return groups, modifierRows.Err()
+}
+
 // GetCategories retrieves all categories
 func (h *ProductHandler) GetCategories(c *gin.Context) {
 	activeOnly := c.Query("active_only") == "true"
 	
 	query := `
-		SELECT id, name, description, color, sort_order, is_active, created_at, updated_at
+		SELECT id, name, description, color, sort_order, tax_class_id, station_id, is_active, created_at, updated_at
 		FROM categories
 	`
 	
@@ -256,7 +331,7 @@ func (h *ProductHandler) GetCategories(c *gin.Context) {
 
 		err := rows.Scan(
 			&category.ID, &category.Name, &category.Description, &category.Color,
-			&category.SortOrder, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
+			&category.SortOrder, &category.TaxClassID, &category.StationID, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -293,7 +368,7 @@ func (h *ProductHandler) GetProductsByCategory(c *gin.Context) {
 
 	query := `
 		SELECT p.id, p.category_id, p.name, p.description, p.price, p.image_url, 
-		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order,
+		       p.barcode, p.sku, p.is_available, p.preparation_time, p.sort_order, p.tax_class_id, p.station_id,
 		       p.created_at, p.updated_at,
 		       c.name as category_name, c.color as category_color
 		FROM products p
@@ -326,7 +401,7 @@ func (h *ProductHandler) GetProductsByCategory(c *gin.Context) {
 		err := rows.Scan(
 			&product.ID, &product.CategoryID, &product.Name, &product.Description,
 			&product.Price, &product.ImageURL, &product.Barcode, &product.SKU,
-			&product.IsAvailable, &product.PreparationTime, &product.SortOrder,
+			&product.IsAvailable, &product.PreparationTime, &product.SortOrder, &product.TaxClassID, &product.StationID,
 			&product.CreatedAt, &product.UpdatedAt,
 			&categoryName, &categoryColor,
 		)
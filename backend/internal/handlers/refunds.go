@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"pos-backend/internal/middleware"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RefundPayment refunds all or part of a completed payment after checking manager approval
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("payment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid payment ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	userID, _, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   stringPtr("auth_required"),
+		})
+		return
+	}
+
+	var req models.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "A refund reason is required",
+			Error:   stringPtr("reason_required"),
+		})
+		return
+	}
+
+	if req.Amount != nil && *req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Refund amount must be greater than zero",
+			Error:   stringPtr("invalid_amount"),
+		})
+		return
+	}
+
+	// Managers approve their own refunds; everyone else needs a manager's sign-off
+	approvedBy := userID
+	if !repository.IsApproverRole(role) {
+		if req.Approval == nil {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Manager approval is required",
+				Error:   stringPtr("approval_required"),
+			})
+			return
+		}
+
+		approverID, err := repository.VerifyManagerApproval(c.Request.Context(), h.db, *req.Approval, userID)
+		if err != nil {
+			writeRefundError(c, err, "Failed to verify manager approval")
+			return
+		}
+		approvedBy = approverID
+	}
+
+	refund, orderStatus, err := repository.RefundPayment(c.Request.Context(), h.db, orderID, paymentID,
+		req.Amount, req.Reason, req.Reopen, req.DrawerSessionID, userID, approvedBy, h.settleRefund)
+	if err != nil {
+		writeRefundError(c, err, "Failed to refund payment")
+		return
+	}
+
+	payment, err := h.getPaymentByID(paymentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Refund recorded but failed to fetch payment",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Payment refunded successfully",
+		Data: gin.H{
+			"refund":       refund,
+			"payment":      payment,
+			"order_status": orderStatus,
+		},
+	})
+}
+
+// GetRefunds lists the refunds made on an order
+func (h *PaymentHandler) GetRefunds(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid order ID",
+			Error:   stringPtr("invalid_uuid"),
+		})
+		return
+	}
+
+	refunds, err := repository.ListRefunds(c.Request.Context(), h.db, orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch refunds",
+			Error:   stringPtr(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Refunds retrieved successfully",
+		Data:    refunds,
+	})
+}
+
+// writeRefundError maps repository error codes from refunds to API responses
+func writeRefundError(c *gin.Context, err error, fallbackMessage string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "approval_required"):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Manager approval is required",
+			Error:   stringPtr("approval_required"),
+		})
+	case strings.Contains(msg, "approval_locked"):
+		c.JSON(http.StatusTooManyRequests, models.APIResponse{
+			Success: false,
+			Message: "Manager approval is locked; " + strings.TrimPrefix(msg, "approval_locked: "),
+			Error:   stringPtr("approval_locked"),
+		})
+	case strings.Contains(msg, "invalid_approval"):
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Manager approval could not be verified",
+			Error:   stringPtr("invalid_approval"),
+		})
+	case strings.Contains(msg, "order_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Order not found",
+			Error:   stringPtr("order_not_found"),
+		})
+	case strings.Contains(msg, "payment_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Payment not found",
+			Error:   stringPtr("payment_not_found"),
+		})
+	case strings.Contains(msg, "payment_not_refundable"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Only completed payments can be refunded",
+			Error:   stringPtr("payment_not_refundable"),
+		})
+	case strings.Contains(msg, "business_day_closed"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Order belongs to a closed business day and cannot be reopened",
+			Error:   stringPtr("business_day_closed"),
+		})
+	case strings.Contains(msg, "refund_exceeds_payment"):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Refund exceeds the payment; " + strings.TrimPrefix(msg, "refund_exceeds_payment: "),
+			Error:   stringPtr("refund_exceeds_payment"),
+		})
+	case strings.Contains(msg, "drawer_session_not_found"):
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Drawer session not found",
+			Error:   stringPtr("drawer_session_not_found"),
+		})
+	case strings.Contains(msg, "drawer_not_open"):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Message: "Cash refunds need an open drawer; " + strings.TrimPrefix(msg, "drawer_not_open: "),
+			Error:   stringPtr("drawer_not_open"),
+		})
+	case strings.HasPrefix(msg, "payment_declined"):
+		c.JSON(http.StatusPaymentRequired, models.APIResponse{
+			Success: false,
+			Message: "Refund declined by the payment processor: " + strings.TrimPrefix(msg, "payment_declined: "),
+			Error:   stringPtr("refund_declined"),
+		})
+	case strings.HasPrefix(msg, "gateway_timeout"):
+		c.JSON(http.StatusGatewayTimeout, models.APIResponse{
+			Success: false,
+			Message: "The payment processor did not respond; nothing was refunded",
+			Error:   stringPtr("gateway_timeout"),
+		})
+	case strings.HasPrefix(msg, "gateway_error"):
+		c.JSON(http.StatusBadGateway, models.APIResponse{
+			Success: false,
+			Message: "The payment processor reported an error",
+			Error:   stringPtr(msg),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: fallbackMessage,
+			Error:   stringPtr(msg),
+		})
+	}
+}
@@ -20,9 +20,17 @@ type Claims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
 	Role     string    `json:"role"`
+	Scope    string    `json:"scope,omitempty"` // empty for a full session token
 	jwt.RegisteredClaims
 }
 
+// StreamScope marks a token that may only open the event stream
+const StreamScope = "stream"
+
+// StreamTokenTTL is how long a stream token can be used to connect. The stream stays open
+// past it; a client fetches a new token each time it reconnects.
+const StreamTokenTTL = time.Minute
+
 // GenerateToken generates a JWT token for a user
 func GenerateToken(user *models.User) (string, error) {
 	// Set token expiration time (24 hours)
@@ -52,6 +60,30 @@ func GenerateToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateStreamToken issues a short-lived token that only opens the event stream, for
+// clients such as the browser EventSource that have to pass it in the URL
+func GenerateStreamToken(userID uuid.UUID, username, role string) (string, time.Time, error) {
+	expirationTime := time.Now().Add(StreamTokenTTL)
+
+	claims := &Claims{
+		UserID:   userID,
+		Username: username,
+		Role:     role,
+		Scope:    StreamScope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "pos-system",
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expirationTime, nil
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*Claims, error) {
 	// Parse token
@@ -100,9 +132,9 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Validate token
+		// Validate token; scoped tokens such as stream tokens are not session tokens
 		claims, err := ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || claims.Scope != "" {
 			c.JSON(http.StatusUnauthorized, models.APIResponse{
 				Success: false,
 				Message: "Invalid or expired token",
@@ -121,6 +153,37 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// StreamTokenAuth authenticates the event stream. Clients that cannot set headers, such as
+// the browser EventSource, pass a stream token from GenerateStreamToken as the stream_token
+// query parameter, so a session token never appears in a URL. Requests with an
+// Authorization header go through authMiddleware as usual.
+func StreamTokenAuth(authMiddleware gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.Query("stream_token")
+		if c.GetHeader("Authorization") != "" || tokenString == "" {
+			authMiddleware(c)
+			return
+		}
+
+		claims, err := ValidateToken(tokenString)
+		if err != nil || claims.Scope != StreamScope {
+			c.JSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Message: "Invalid or expired stream token",
+				Error:   stringPtr("invalid_token"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+
+		c.Next()
+	}
+}
+
 // RequireRole returns a middleware that checks if the user has the required role
 func RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
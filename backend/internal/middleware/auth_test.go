@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func streamRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api", AuthMiddleware(), ok)
+	router.GET("/stream", StreamTokenAuth(AuthMiddleware()), ok)
+	return router
+}
+
+func serve(router *gin.Engine, url, bearer string) int {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestStreamTokens(t *testing.T) {
+	router := streamRouter()
+	user := &models.User{ID: uuid.New(), Username: "kds", Role: "kitchen"}
+
+	session, err := GenerateToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, _, err := GenerateStreamToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, url, bearer string
+		want              int
+	}{
+		{"session token in the header", "/api", session, http.StatusOK},
+		{"stream token is not a session token", "/api", stream, http.StatusUnauthorized},
+		{"stream token in the URL", "/stream?stream_token=" + stream, "", http.StatusOK},
+		{"session token in the URL", "/stream?stream_token=" + session, "", http.StatusUnauthorized},
+		{"old access_token parameter", "/stream?access_token=" + session, "", http.StatusUnauthorized},
+		{"session token in the header on the stream", "/stream", session, http.StatusOK},
+		{"no token", "/stream", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := serve(router, tt.url, tt.bearer); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
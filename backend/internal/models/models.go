@@ -22,32 +22,132 @@ type User struct {
 
 // Category represents a product category
 type Category struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	Color       *string    `json:"color"`
+	SortOrder   int        `json:"sort_order"`
+	TaxClassID  *uuid.UUID `json:"tax_class_id"`
+	StationID   *uuid.UUID `json:"station_id"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Product represents a menu item/product
+type Product struct {
+	ID              uuid.UUID       `json:"id"`
+	CategoryID      *uuid.UUID      `json:"category_id"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description"`
+	Price           float64         `json:"price"`
+	ImageURL        *string         `json:"image_url"`
+	Barcode         *string         `json:"barcode"`
+	SKU             *string         `json:"sku"`
+	IsAvailable     bool            `json:"is_available"`
+	PreparationTime int             `json:"preparation_time"` // in minutes
+	SortOrder       int             `json:"sort_order"`
+	TaxClassID      *uuid.UUID      `json:"tax_class_id"`
+	StationID       *uuid.UUID      `json:"station_id"` // overrides the category station
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Category        *Category       `json:"category,omitempty"`
+	ModifierGroups  []ModifierGroup `json:"modifier_groups,omitempty"`
+}
+
+// ModifierGroup is a set of options offered on products, such as size or cooking temperature
+type ModifierGroup struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	MinSelect   int        `json:"min_select"`
+	MaxSelect   *int       `json:"max_select"` // nil means no limit
+	SortOrder   int        `json:"sort_order"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Modifiers   []Modifier `json:"modifiers"`
+}
+
+// Modifier is a single option within a modifier group
+type Modifier struct {
+	ID              uuid.UUID `json:"id"`
+	ModifierGroupID uuid.UUID `json:"modifier_group_id"`
+	Name            string    `json:"name"`
+	PriceDelta      float64   `json:"price_delta"`
+	IsAvailable     bool      `json:"is_available"`
+	SortOrder       int       `json:"sort_order"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TaxClass groups the tax rules applied to a set of categories or products
+type TaxClass struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	IsDefault   bool      `json:"is_default"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Rules       []TaxRule `json:"rules"`
+}
+
+// TaxRule is a single tax rate within a tax class
+type TaxRule struct {
+	ID          uuid.UUID `json:"id"`
+	TaxClassID  uuid.UUID `json:"tax_class_id"`
+	Name        string    `json:"name"`
+	Rate        float64   `json:"rate"`
+	OrderType   *string   `json:"order_type"` // nil applies to all order types
+	IsInclusive bool      `json:"is_inclusive"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// KitchenStation is a prep area that receives its own tickets, such as the grill or bar
+type KitchenStation struct {
 	ID          uuid.UUID `json:"id"`
 	Name        string    `json:"name"`
 	Description *string   `json:"description"`
-	Color       *string   `json:"color"`
 	SortOrder   int       `json:"sort_order"`
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// Product represents a menu item/product
-type Product struct {
-	ID              uuid.UUID  `json:"id"`
-	CategoryID      *uuid.UUID `json:"category_id"`
-	Name            string     `json:"name"`
-	Description     *string    `json:"description"`
-	Price           float64    `json:"price"`
-	ImageURL        *string    `json:"image_url"`
-	Barcode         *string    `json:"barcode"`
-	SKU             *string    `json:"sku"`
-	IsAvailable     bool       `json:"is_available"`
-	PreparationTime int        `json:"preparation_time"` // in minutes
-	SortOrder       int        `json:"sort_order"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	Category        *Category  `json:"category,omitempty"`
+// Printer is an ESC/POS printer that receives kitchen tickets or receipts
+type Printer struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	PrinterType    string     `json:"printer_type"` // kitchen, receipt
+	StationID      *uuid.UUID `json:"station_id"`   // kitchen printers without a station print every station
+	StationName    *string    `json:"station_name,omitempty"`
+	ConnectionType string     `json:"connection_type"` // network, file, loopback
+	Address        string     `json:"address"`         // host[:port] for network printers, path under the spool directory for file printers
+	CharsPerLine   int        `json:"chars_per_line"`
+	IsActive       bool       `json:"is_active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// PrintJob is a rendered ESC/POS document in the print spool
+type PrintJob struct {
+	ID            uuid.UUID  `json:"id"`
+	PrinterID     uuid.UUID  `json:"printer_id"`
+	JobType       string     `json:"job_type"` // kitchen_ticket, receipt, test
+	OrderID       *uuid.UUID `json:"order_id"`
+	Payload       []byte     `json:"-"`
+	Status        string     `json:"status"` // pending, printing, printed, failed
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	LastError     *string    `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	PrintedAt     *time.Time `json:"printed_at"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Printer       *Printer   `json:"printer,omitempty"`
 }
 
 // DiningTable represents a table or dining area
@@ -63,41 +163,167 @@ type DiningTable struct {
 
 // Order represents a customer order
 type Order struct {
-	ID             uuid.UUID    `json:"id"`
-	OrderNumber    string       `json:"order_number"`
-	TableID        *uuid.UUID   `json:"table_id"`
-	UserID         *uuid.UUID   `json:"user_id"`
-	CustomerName   *string      `json:"customer_name"`
-	OrderType      string       `json:"order_type"` // dine_in, takeout, delivery
-	Status         string       `json:"status"`     // pending, confirmed, preparing, ready, served, completed, cancelled
-	Subtotal       float64      `json:"subtotal"`
-	TaxAmount      float64      `json:"tax_amount"`
-	DiscountAmount float64      `json:"discount_amount"`
-	TotalAmount    float64      `json:"total_amount"`
-	Notes          *string      `json:"notes"`
-	CreatedAt      time.Time    `json:"created_at"`
-	UpdatedAt      time.Time    `json:"updated_at"`
-	ServedAt       *time.Time   `json:"served_at"`
-	CompletedAt    *time.Time   `json:"completed_at"`
-	Table          *DiningTable `json:"table,omitempty"`
-	User           *User        `json:"user,omitempty"`
-	Items          []OrderItem  `json:"items,omitempty"`
-	Payments       []Payment    `json:"payments,omitempty"`
+	ID             uuid.UUID       `json:"id"`
+	OrderNumber    string          `json:"order_number"`
+	TableID        *uuid.UUID      `json:"table_id"`
+	UserID         *uuid.UUID      `json:"user_id"`
+	CustomerName   *string         `json:"customer_name"`
+	OrderType      string          `json:"order_type"` // dine_in, takeout, delivery
+	Status         string          `json:"status"`     // pending, confirmed, preparing, ready, served, completed, cancelled
+	Subtotal       float64         `json:"subtotal"`
+	TaxAmount      float64         `json:"tax_amount"`
+	DiscountAmount float64         `json:"discount_amount"`
+	TotalAmount    float64         `json:"total_amount"`
+	GuestCount     *int            `json:"guest_count"`
+	ServiceCharge  *ServiceCharge  `json:"service_charge,omitempty"`
+	Notes          *string         `json:"notes"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	ServedAt       *time.Time      `json:"served_at"`
+	CompletedAt    *time.Time      `json:"completed_at"`
+	Table          *DiningTable    `json:"table,omitempty"`
+	User           *User           `json:"user,omitempty"`
+	Items          []OrderItem     `json:"items,omitempty"`
+	Payments       []Payment       `json:"payments,omitempty"`
+	Taxes          []OrderTax      `json:"taxes,omitempty"`
+	Discounts      []OrderDiscount `json:"discounts,omitempty"`
+}
+
+// ServiceCharge is the automatic charge applied to an order, copied from its rule
+type ServiceCharge struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// ServiceChargeRule adds a percentage charge to orders from parties of at least MinPartySize
+type ServiceChargeRule struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Rate         float64   `json:"rate"` // fraction, e.g. 0.18 for 18%
+	MinPartySize int       `json:"min_party_size"`
+	OrderType    *string   `json:"order_type"` // nil applies to all order types
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Promotion is an admin-defined discount that can be applied to orders
+type Promotion struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Description   *string    `json:"description"`
+	PromotionType string     `json:"promotion_type"` // percent, fixed, buy_x_get_y
+	Scope         string     `json:"scope"`          // order, item, category
+	Value         float64    `json:"value"`
+	ProductID     *uuid.UUID `json:"product_id"`
+	CategoryID    *uuid.UUID `json:"category_id"`
+	BuyQuantity   *int       `json:"buy_quantity"`
+	GetQuantity   *int       `json:"get_quantity"`
+	MinSpend      float64    `json:"min_spend"`
+	IsStackable   bool       `json:"is_stackable"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	DaysOfWeek    []int64    `json:"days_of_week"` // 0 = Sunday; empty means every day
+	StartTime     *string    `json:"start_time"`   // HH:MM:SS
+	EndTime       *string    `json:"end_time"`
+	IsActive      bool       `json:"is_active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// OrderDiscount is a promotion applied to an order
+type OrderDiscount struct {
+	ID            uuid.UUID  `json:"id"`
+	OrderID       uuid.UUID  `json:"order_id"`
+	PromotionID   *uuid.UUID `json:"promotion_id"`
+	PromotionName string     `json:"promotion_name"`
+	Amount        float64    `json:"amount"`
+	AppliedBy     *uuid.UUID `json:"applied_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OrderTax is the amount collected for one tax rule on an order
+type OrderTax struct {
+	ID            uuid.UUID  `json:"id"`
+	OrderID       uuid.UUID  `json:"order_id"`
+	TaxRuleID     *uuid.UUID `json:"tax_rule_id"`
+	TaxName       string     `json:"tax_name"`
+	Rate          float64    `json:"rate"`
+	IsInclusive   bool       `json:"is_inclusive"`
+	TaxableAmount float64    `json:"taxable_amount"`
+	TaxAmount     float64    `json:"tax_amount"`
 }
 
 // OrderItem represents an item within an order
 type OrderItem struct {
-	ID                  uuid.UUID `json:"id"`
-	OrderID             uuid.UUID `json:"order_id"`
-	ProductID           uuid.UUID `json:"product_id"`
-	Quantity            int       `json:"quantity"`
-	UnitPrice           float64   `json:"unit_price"`
-	TotalPrice          float64   `json:"total_price"`
-	SpecialInstructions *string   `json:"special_instructions"`
-	Status              string    `json:"status"` // pending, preparing, ready, served
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	Product             *Product  `json:"product,omitempty"`
+	ID                  uuid.UUID            `json:"id"`
+	OrderID             uuid.UUID            `json:"order_id"`
+	ProductID           uuid.UUID            `json:"product_id"`
+	Quantity            int                  `json:"quantity"`
+	UnitPrice           float64              `json:"unit_price"`
+	TotalPrice          float64              `json:"total_price"`
+	SpecialInstructions *string              `json:"special_instructions"`
+	Status              string               `json:"status"` // pending, preparing, ready, served
+	StationID           *uuid.UUID           `json:"station_id"`
+	Course              int                  `json:"course"`
+	IsHeld              bool                 `json:"is_held"`
+	FiredAt             *time.Time           `json:"fired_at"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+	Product             *Product             `json:"product,omitempty"`
+	Adjustment          *OrderItemAdjustment `json:"adjustment,omitempty"`
+	Modifiers           []OrderItemModifier  `json:"modifiers,omitempty"`
+}
+
+// StationTicket is the part of an order one kitchen station has to prepare
+type StationTicket struct {
+	OrderID      uuid.UUID      `json:"order_id"`
+	OrderNumber  string         `json:"order_number"`
+	OrderType    string         `json:"order_type"`
+	OrderStatus  string         `json:"order_status"`
+	TableNumber  *string        `json:"table_number"`
+	CustomerName *string        `json:"customer_name"`
+	Notes        *string        `json:"notes"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Courses      []CourseTiming `json:"courses"`
+	Items        []OrderItem    `json:"items"`
+}
+
+// CourseTiming shows how long a fired course has been waiting in the kitchen
+type CourseTiming struct {
+	Course         int       `json:"course"`
+	FiredAt        time.Time `json:"fired_at"`
+	WaitingSeconds int       `json:"waiting_seconds"`
+	OpenItems      int       `json:"open_items"`
+}
+
+// OrderItemModifier is a modifier chosen for an order item, priced at the time of ordering
+type OrderItemModifier struct {
+	ID              uuid.UUID  `json:"id"`
+	OrderItemID     uuid.UUID  `json:"order_item_id"`
+	ModifierID      *uuid.UUID `json:"modifier_id"`
+	ModifierGroupID *uuid.UUID `json:"modifier_group_id"`
+	GroupName       string     `json:"group_name"`
+	ModifierName    string     `json:"modifier_name"`
+	PriceDelta      float64    `json:"price_delta"`
+}
+
+// OrderItemAdjustment records a void or comp applied to an order item
+type OrderItemAdjustment struct {
+	ID             uuid.UUID  `json:"id"`
+	OrderID        uuid.UUID  `json:"order_id"`
+	OrderItemID    uuid.UUID  `json:"order_item_id"`
+	ProductID      *uuid.UUID `json:"product_id"`
+	AdjustmentType string     `json:"adjustment_type"` // void, comp
+	ReasonCode     string     `json:"reason_code"`
+	Quantity       int        `json:"quantity"`
+	Amount         float64    `json:"amount"`
+	RequestedBy    *uuid.UUID `json:"requested_by"`
+	ApprovedBy     *uuid.UUID `json:"approved_by"`
+	Notes          *string    `json:"notes"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ApprovedByUser *User      `json:"approved_by_user,omitempty"`
 }
 
 // Payment represents a payment transaction
@@ -106,14 +332,149 @@ type Payment struct {
 	OrderID         uuid.UUID  `json:"order_id"`
 	PaymentMethod   string     `json:"payment_method"` // cash, credit_card, debit_card, digital_wallet
 	Amount          float64    `json:"amount"`
+	TipAmount       float64    `json:"tip_amount"`
+	TenderedAmount  *float64   `json:"tendered_amount,omitempty"` // cash handed over
+	ChangeGiven     float64    `json:"change_given"`
+	RoundingAmount  float64    `json:"rounding_amount"` // cash rounding of the balance
+	DrawerSessionID *uuid.UUID `json:"drawer_session_id"`
 	ReferenceNumber *string    `json:"reference_number"`
 	Status          string     `json:"status"` // pending, completed, failed, refunded
 	ProcessedBy     *uuid.UUID `json:"processed_by"`
+	RefundedAmount  float64    `json:"refunded_amount"`
 	ProcessedAt     *time.Time `json:"processed_at"`
 	CreatedAt       time.Time  `json:"created_at"`
 	ProcessedByUser *User      `json:"processed_by_user,omitempty"`
 }
 
+// DrawerSession is a cash drawer shift, from the opening float to the counted close
+type DrawerSession struct {
+	ID             uuid.UUID        `json:"id"`
+	DrawerName     string           `json:"drawer_name"`
+	Status         string           `json:"status"` // open, closed, reviewed
+	OpeningFloat   float64          `json:"opening_float"`
+	OpenedBy       *uuid.UUID       `json:"opened_by"`
+	OpenedAt       time.Time        `json:"opened_at"`
+	CountedAmount  *float64         `json:"counted_amount"`
+	ExpectedAmount *float64         `json:"expected_amount,omitempty"` // hidden from the counting user until review
+	OverShort      *float64         `json:"over_short,omitempty"`
+	CloseNotes     *string          `json:"close_notes"`
+	ClosedBy       *uuid.UUID       `json:"closed_by"`
+	ClosedAt       *time.Time       `json:"closed_at"`
+	ReviewNotes    *string          `json:"review_notes"`
+	ReviewedBy     *uuid.UUID       `json:"reviewed_by"`
+	ReviewedAt     *time.Time       `json:"reviewed_at"`
+	OpenedByUser   *User            `json:"opened_by_user,omitempty"`
+	Movements      []DrawerMovement `json:"movements,omitempty"`
+}
+
+// DrawerMovement is a pay-in, pay-out or no-sale open of a drawer
+type DrawerMovement struct {
+	ID           uuid.UUID  `json:"id"`
+	SessionID    uuid.UUID  `json:"session_id"`
+	MovementType string     `json:"movement_type"` // pay_in, pay_out, no_sale
+	Amount       float64    `json:"amount"`
+	Reason       *string    `json:"reason"`
+	CreatedBy    *uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// DrawerReport reconciles a drawer session's cash with its count
+type DrawerReport struct {
+	Session        DrawerSession `json:"session"`
+	OpeningFloat   float64       `json:"opening_float"`
+	CashSales      float64       `json:"cash_sales"` // payment amounts plus cash rounding
+	CashTips       float64       `json:"cash_tips"`
+	CashRefunds    float64       `json:"cash_refunds"`
+	PayIns         float64       `json:"pay_ins"`
+	PayOuts        float64       `json:"pay_outs"`
+	NoSaleCount    int           `json:"no_sale_count"`
+	CardSales      float64       `json:"card_sales"` // taken during the session; not in the drawer
+	PaymentCount   int           `json:"payment_count"`
+	ExpectedAmount float64       `json:"expected_amount"`
+	CountedAmount  *float64      `json:"counted_amount"`
+	OverShort      *float64      `json:"over_short"`
+}
+
+// DayReport totals a business day. A Z report freezes them when the day is closed; an
+// X report is the same figures for the day still open.
+type DayReport struct {
+	ID                 *uuid.UUID         `json:"id,omitempty"`
+	ReportType         string             `json:"report_type"` // x, z
+	BusinessDate       *string            `json:"business_date"`
+	PeriodStart        time.Time          `json:"period_start"`
+	PeriodEnd          time.Time          `json:"period_end"`
+	OrderCount         int                `json:"order_count"`
+	CancelledCount     int                `json:"cancelled_count"`
+	GrossSales         float64            `json:"gross_sales"`
+	DiscountTotal      float64            `json:"discount_total"`
+	TaxTotal           float64            `json:"tax_total"`
+	ServiceChargeTotal float64            `json:"service_charge_total"`
+	NetSales           float64            `json:"net_sales"`
+	RefundCount        int                `json:"refund_count"`
+	RefundTotal        float64            `json:"refund_total"`
+	TipTotal           float64            `json:"tip_total"`
+	VoidCount          int                `json:"void_count"`
+	VoidTotal          float64            `json:"void_total"`
+	CompCount          int                `json:"comp_count"`
+	CompTotal          float64            `json:"comp_total"`
+	Payments           []DayPaymentTotal  `json:"payments,omitempty"`
+	Taxes              []DayTaxTotal      `json:"taxes,omitempty"`
+	Discounts          []DayDiscountTotal `json:"discounts,omitempty"`
+	ClosedBy           *uuid.UUID         `json:"closed_by,omitempty"`
+	ClosedAt           *time.Time         `json:"closed_at,omitempty"`
+	ClosedByUser       *User              `json:"closed_by_user,omitempty"`
+}
+
+// DayPaymentTotal is a business day's takings for one payment method
+type DayPaymentTotal struct {
+	PaymentMethod string  `json:"payment_method"`
+	PaymentCount  int     `json:"payment_count"`
+	Amount        float64 `json:"amount"`
+	TipAmount     float64 `json:"tip_amount"`
+	RefundAmount  float64 `json:"refund_amount"`
+	NetAmount     float64 `json:"net_amount"` // amount + tips - refunds
+}
+
+// DayTaxTotal is a business day's tax collected at one rate
+type DayTaxTotal struct {
+	TaxName       string  `json:"tax_name"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"is_inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// DayDiscountTotal is a business day's discounts from one promotion
+type DayDiscountTotal struct {
+	PromotionName string  `json:"promotion_name"`
+	OrderCount    int     `json:"order_count"`
+	Amount        float64 `json:"amount"`
+}
+
+// StoreSettings holds store-wide configuration
+type StoreSettings struct {
+	CashRounding float64   `json:"cash_rounding"` // smallest coin, e.g. 0.05
+	TimeZone     string    `json:"time_zone"`     // IANA name, e.g. Europe/London
+	DayCutoff    string    `json:"day_cutoff"`    // HH:MM the business day starts
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Refund is money returned against a completed payment
+type Refund struct {
+	ID              uuid.UUID  `json:"id"`
+	OrderID         uuid.UUID  `json:"order_id"`
+	PaymentID       uuid.UUID  `json:"payment_id"`
+	Amount          float64    `json:"amount"`
+	Reason          string     `json:"reason"`
+	Status          string     `json:"status"`           // pending, completed, failed
+	ReferenceNumber *string    `json:"reference_number"` // processor refund reference for card payments
+	DrawerSessionID *uuid.UUID `json:"drawer_session_id"`
+	RefundedBy      *uuid.UUID `json:"refunded_by"`
+	ApprovedBy      *uuid.UUID `json:"approved_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	RefundedByUser  *User      `json:"refunded_by_user,omitempty"`
+}
+
 // Inventory represents product inventory
 type Inventory struct {
 	ID              uuid.UUID  `json:"id"`
@@ -123,11 +484,85 @@ type Inventory struct {
 	MaximumStock    int        `json:"maximum_stock"`
 	UnitCost        *float64   `json:"unit_cost"`
 	LastRestockedAt *time.Time `json:"last_restocked_at"`
+	AutoDisabled    bool       `json:"auto_disabled"`
+	IsLowStock      bool       `json:"is_low_stock"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 	Product         *Product   `json:"product,omitempty"`
 }
 
+// InventoryMovement is a single entry in the stock ledger
+type InventoryMovement struct {
+	ID             uuid.UUID  `json:"id"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	ProductName    string     `json:"product_name"`
+	OrderID        *uuid.UUID `json:"order_id"`
+	MovementType   string     `json:"movement_type"` // initial, restock, adjustment, sale, return
+	QuantityChange int        `json:"quantity_change"`
+	StockAfter     int        `json:"stock_after"`
+	UnitCost       *float64   `json:"unit_cost"`
+	Reason         *string    `json:"reason"`
+	Notes          *string    `json:"notes"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Ingredient is a raw material tracked in its own unit
+type Ingredient struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Unit         string    `json:"unit"` // g, ml, each, ...
+	CurrentStock float64   `json:"current_stock"`
+	MinimumStock float64   `json:"minimum_stock"`
+	UnitCost     *float64  `json:"unit_cost"`
+	IsActive     bool      `json:"is_active"`
+	IsLowStock   bool      `json:"is_low_stock"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RecipeItem is one ingredient line of a product or modifier recipe
+type RecipeItem struct {
+	ID             uuid.UUID  `json:"id"`
+	ProductID      *uuid.UUID `json:"product_id,omitempty"`
+	ModifierID     *uuid.UUID `json:"modifier_id,omitempty"`
+	IngredientID   uuid.UUID  `json:"ingredient_id"`
+	IngredientName string     `json:"ingredient_name"`
+	Unit           string     `json:"unit"`
+	Quantity       float64    `json:"quantity"`
+	UnitCost       *float64   `json:"unit_cost"`
+}
+
+// IngredientMovement is a single entry in the ingredient stock ledger
+type IngredientMovement struct {
+	ID             uuid.UUID  `json:"id"`
+	IngredientID   uuid.UUID  `json:"ingredient_id"`
+	OrderID        *uuid.UUID `json:"order_id"`
+	MovementType   string     `json:"movement_type"` // initial, purchase, consumption, waste, count, adjustment
+	QuantityChange float64    `json:"quantity_change"`
+	StockAfter     float64    `json:"stock_after"`
+	UnitCost       *float64   `json:"unit_cost"`
+	Reason         *string    `json:"reason"`
+	Notes          *string    `json:"notes"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IngredientUsage compares theoretical usage from sales with what stock counts show
+type IngredientUsage struct {
+	IngredientID     uuid.UUID `json:"ingredient_id"`
+	Name             string    `json:"name"`
+	Unit             string    `json:"unit"`
+	UnitCost         float64   `json:"unit_cost"`
+	TheoreticalUsage float64   `json:"theoretical_usage"` // consumed by completed orders
+	RecordedWaste    float64   `json:"recorded_waste"`
+	Adjustments      float64   `json:"adjustments"`
+	CountVariance    float64   `json:"count_variance"` // negative when counts found less than expected
+	ActualUsage      float64   `json:"actual_usage"`
+	VariancePercent  float64   `json:"variance_percent"`
+	VarianceCost     float64   `json:"variance_cost"`
+}
+
 // OrderStatusHistory tracks order status changes
 type OrderStatusHistory struct {
 	ID             uuid.UUID  `json:"id"`
@@ -140,6 +575,22 @@ type OrderStatusHistory struct {
 	ChangedByUser  *User      `json:"changed_by_user,omitempty"`
 }
 
+// OrderItemChange records an item edit made after the order was created
+type OrderItemChange struct {
+	ID               uuid.UUID  `json:"id"`
+	OrderID          uuid.UUID  `json:"order_id"`
+	OrderItemID      *uuid.UUID `json:"order_item_id"`
+	ProductID        uuid.UUID  `json:"product_id"`
+	Action           string     `json:"action"` // added, quantity_changed, removed
+	PreviousQuantity *int       `json:"previous_quantity"`
+	NewQuantity      *int       `json:"new_quantity"`
+	ChangedBy        *uuid.UUID `json:"changed_by"`
+	Notes            *string    `json:"notes"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ChangedByUser    *User      `json:"changed_by_user,omitempty"`
+	Product          *Product   `json:"product,omitempty"`
+}
+
 // Request/Response DTOs
 
 // CreateOrderRequest represents the request to create a new order
@@ -147,15 +598,109 @@ type CreateOrderRequest struct {
 	TableID      *uuid.UUID        `json:"table_id"`
 	CustomerName *string           `json:"customer_name"`
 	OrderType    string            `json:"order_type"`
+	GuestCount   *int              `json:"guest_count"`
 	Items        []CreateOrderItem `json:"items"`
 	Notes        *string           `json:"notes"`
 }
 
 // CreateOrderItem represents an item in the order creation request
 type CreateOrderItem struct {
-	ProductID           uuid.UUID `json:"product_id"`
-	Quantity            int       `json:"quantity"`
-	SpecialInstructions *string   `json:"special_instructions"`
+	ProductID           uuid.UUID   `json:"product_id"`
+	Quantity            int         `json:"quantity"`
+	SpecialInstructions *string     `json:"special_instructions"`
+	ModifierIDs         []uuid.UUID `json:"modifier_ids"`
+	Course              int         `json:"course"` // defaults to 1
+	Hold                bool        `json:"hold"`   // dine-in only; kept from the kitchen until the course is fired
+}
+
+// UpdateGuestCountRequest represents the request to change how many guests an order is for
+type UpdateGuestCountRequest struct {
+	GuestCount int `json:"guest_count"`
+}
+
+// UpdateOrderItemRequest represents the request to change an existing order item
+type UpdateOrderItemRequest struct {
+	Quantity            *int    `json:"quantity"`
+	SpecialInstructions *string `json:"special_instructions"`
+	Notes               *string `json:"notes"`
+}
+
+// RemoveOrderItemRequest represents the optional body when removing an order item
+type RemoveOrderItemRequest struct {
+	Notes *string `json:"notes"`
+}
+
+// ManagerApproval carries a manager's credentials or PIN for actions that need sign-off
+type ManagerApproval struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	PIN      string `json:"pin"`
+}
+
+// AdjustOrderItemRequest represents the request to void or comp an order item
+type AdjustOrderItemRequest struct {
+	ReasonCode string           `json:"reason_code"`
+	Notes      *string          `json:"notes"`
+	Approval   *ManagerApproval `json:"approval"`
+}
+
+// ApplyDiscountRequest represents the request to apply a promotion to an order
+type ApplyDiscountRequest struct {
+	PromotionID uuid.UUID `json:"promotion_id"`
+}
+
+// UpsertInventoryRequest starts or updates stock tracking for a product
+type UpsertInventoryRequest struct {
+	CurrentStock *int     `json:"current_stock"` // only used when tracking starts
+	MinimumStock *int     `json:"minimum_stock"`
+	MaximumStock *int     `json:"maximum_stock"`
+	UnitCost     *float64 `json:"unit_cost"`
+}
+
+// RestockRequest represents a stock delivery
+type RestockRequest struct {
+	Quantity int      `json:"quantity"`
+	UnitCost *float64 `json:"unit_cost"`
+	Notes    *string  `json:"notes"`
+}
+
+// StockAdjustmentRequest corrects stock by a relative change or to a counted level
+type StockAdjustmentRequest struct {
+	QuantityChange *int    `json:"quantity_change"`
+	CountedStock   *int    `json:"counted_stock"`
+	Reason         string  `json:"reason"`
+	Notes          *string `json:"notes"`
+}
+
+// IngredientRequest represents the request to create or update an ingredient
+type IngredientRequest struct {
+	Name         *string  `json:"name"`
+	Unit         *string  `json:"unit"`
+	CurrentStock *float64 `json:"current_stock"` // opening stock, only used on create
+	MinimumStock *float64 `json:"minimum_stock"`
+	UnitCost     *float64 `json:"unit_cost"`
+	IsActive     *bool    `json:"is_active"`
+}
+
+// IngredientStockRequest records a purchase, waste, count or adjustment of an ingredient.
+// For counts Quantity is the counted stock; for adjustments it is a signed change.
+type IngredientStockRequest struct {
+	MovementType string   `json:"movement_type"`
+	Quantity     float64  `json:"quantity"`
+	UnitCost     *float64 `json:"unit_cost"`
+	Reason       *string  `json:"reason"`
+	Notes        *string  `json:"notes"`
+}
+
+// RecipeLine is one ingredient quantity in a recipe request
+type RecipeLine struct {
+	IngredientID uuid.UUID `json:"ingredient_id"`
+	Quantity     float64   `json:"quantity"`
+}
+
+// SetRecipeRequest replaces the recipe of a product or modifier
+type SetRecipeRequest struct {
+	Items []RecipeLine `json:"items"`
 }
 
 // UpdateOrderStatusRequest represents the request to update order status
@@ -166,9 +711,51 @@ type UpdateOrderStatusRequest struct {
 
 // ProcessPaymentRequest represents the request to process a payment
 type ProcessPaymentRequest struct {
-	PaymentMethod   string  `json:"payment_method"`
-	Amount          float64 `json:"amount"`
-	ReferenceNumber *string `json:"reference_number"`
+	PaymentMethod   string   `json:"payment_method"`
+	Amount          float64  `json:"amount"`          // cash with a tendered amount may leave it 0 to apply all of the tender
+	TipAmount       float64  `json:"tip_amount"`      // paid on top of amount; does not count toward the balance
+	TenderedAmount  *float64 `json:"tendered_amount"` // cash only; anything over amount and tip is given back as change
+	ReferenceNumber *string  `json:"reference_number"`
+	CardToken       *string  `json:"card_token"` // card payments only; passed to the payment gateway
+}
+
+// OpenDrawerRequest represents the request to start a drawer session
+type OpenDrawerRequest struct {
+	DrawerName   string   `json:"drawer_name"` // defaults to Main
+	OpeningFloat *float64 `json:"opening_float" binding:"required"`
+}
+
+// DrawerMovementRequest represents a pay-in, pay-out or no-sale open
+type DrawerMovementRequest struct {
+	MovementType string  `json:"movement_type" binding:"required"`
+	Amount       float64 `json:"amount"`
+	Reason       *string `json:"reason"`
+}
+
+// CloseDrawerRequest represents the blind count entered when closing a drawer
+type CloseDrawerRequest struct {
+	CountedAmount *float64 `json:"counted_amount" binding:"required"`
+	Notes         *string  `json:"notes"`
+}
+
+// ReviewDrawerRequest represents a manager signing off a closed drawer
+type ReviewDrawerRequest struct {
+	Notes *string `json:"notes"`
+}
+
+// CloseBusinessDayRequest represents closing the business day with a Z report
+type CloseBusinessDayRequest struct {
+	BusinessDate *string `json:"business_date"` // YYYY-MM-DD; defaults to the current business date
+}
+
+// RefundPaymentRequest represents the request to refund all or part of a payment
+type RefundPaymentRequest struct {
+	Amount   *float64         `json:"amount"` // omitted refunds whatever is left of the payment
+	Reason   string           `json:"reason" binding:"required"`
+	Reopen   bool             `json:"reopen"` // puts a completed order back to served so the balance can be collected again
+	Approval *ManagerApproval `json:"approval"`
+	// DrawerSessionID is the drawer a cash refund is paid out of; the refunding user's own open session when omitted
+	DrawerSessionID *uuid.UUID `json:"drawer_session_id"`
 }
 
 // LoginRequest represents the login request
@@ -0,0 +1,169 @@
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ESC/POS control sequences
+var (
+	cmdInit        = []byte{0x1B, 0x40}
+	cmdAlignLeft   = []byte{0x1B, 0x61, 0x00}
+	cmdAlignCenter = []byte{0x1B, 0x61, 0x01}
+	cmdAlignRight  = []byte{0x1B, 0x61, 0x02}
+	cmdBoldOn      = []byte{0x1B, 0x45, 0x01}
+	cmdBoldOff     = []byte{0x1B, 0x45, 0x00}
+	cmdSizeNormal  = []byte{0x1D, 0x21, 0x00}
+	cmdSizeDouble  = []byte{0x1D, 0x21, 0x11}
+	cmdFeedCut     = []byte{0x1D, 0x56, 0x42, 0x03} // feed 3 lines, then partial cut
+)
+
+// Builder assembles an ESC/POS document for a printer with a fixed line width
+type Builder struct {
+	buf    bytes.Buffer
+	width  int
+	double bool
+}
+
+// NewBuilder starts a document; width is the number of characters per line in normal size
+func NewBuilder(width int) *Builder {
+	if width <= 0 {
+		width = 42
+	}
+	b := &Builder{width: width}
+	b.buf.Write(cmdInit)
+	return b
+}
+
+func (b *Builder) Left() *Builder   { b.buf.Write(cmdAlignLeft); return b }
+func (b *Builder) Center() *Builder { b.buf.Write(cmdAlignCenter); return b }
+func (b *Builder) Right() *Builder  { b.buf.Write(cmdAlignRight); return b }
+
+// Bold switches emphasised printing on or off
+func (b *Builder) Bold(on bool) *Builder {
+	if on {
+		b.buf.Write(cmdBoldOn)
+	} else {
+		b.buf.Write(cmdBoldOff)
+	}
+	return b
+}
+
+// Double switches double width and height on or off; lines hold half as many characters
+func (b *Builder) Double(on bool) *Builder {
+	if on {
+		b.buf.Write(cmdSizeDouble)
+	} else {
+		b.buf.Write(cmdSizeNormal)
+	}
+	b.double = on
+	return b
+}
+
+// lineWidth is the number of characters that fit on a line at the current size
+func (b *Builder) lineWidth() int {
+	if b.double {
+		return b.width / 2
+	}
+	return b.width
+}
+
+// Line prints text followed by a line feed
+func (b *Builder) Line(text string) *Builder {
+	b.buf.WriteString(sanitize(text))
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Linef prints formatted text followed by a line feed
+func (b *Builder) Linef(format string, args ...interface{}) *Builder {
+	return b.Line(fmt.Sprintf(format, args...))
+}
+
+// Columns prints left and right text on one line, padding between them. Long left
+// text wraps so the right column stays on the last line.
+func (b *Builder) Columns(left, right string) *Builder {
+	left, right = sanitize(left), sanitize(right)
+	width := b.lineWidth()
+	space := width - len(right) - 1
+	if space < 1 {
+		return b.Line(left).Right().Line(right).Left()
+	}
+	trimmed := strings.TrimLeft(left, " ")
+	indent := left[:len(left)-len(trimmed)]
+	lines := wrap(trimmed, space-len(indent))
+	for _, line := range lines[:len(lines)-1] {
+		b.Line(indent + line)
+	}
+	last := indent + lines[len(lines)-1]
+	return b.Line(last + strings.Repeat(" ", width-len(last)-len(right)) + right)
+}
+
+// Wrapped prints text wrapped to the line width, with indent before every line
+func (b *Builder) Wrapped(indent, text string) *Builder {
+	for _, line := range wrap(sanitize(text), b.lineWidth()-len(indent)) {
+		b.Line(indent + line)
+	}
+	return b
+}
+
+// Rule prints a full-width separator
+func (b *Builder) Rule() *Builder {
+	return b.Line(strings.Repeat("-", b.lineWidth()))
+}
+
+// Feed prints n empty lines
+func (b *Builder) Feed(n int) *Builder {
+	for i := 0; i < n; i++ {
+		b.buf.WriteByte('\n')
+	}
+	return b
+}
+
+// Cut feeds the paper past the cutter and cuts it, finishing the document
+func (b *Builder) Cut() []byte {
+	b.buf.Write(cmdFeedCut)
+	return b.buf.Bytes()
+}
+
+// sanitize keeps printable ASCII only; the printers' default code page does not
+// match UTF-8 and stray control bytes would be read as commands
+func sanitize(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r < 0x7F:
+			sb.WriteRune(r)
+		case r == '\t':
+			sb.WriteByte(' ')
+		case r == utf8.RuneError || r >= 0x80:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// wrap splits text into lines of at most width characters, breaking on spaces where possible
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		if n := len(lines); n > 0 && len(lines[n-1])+1+len(word) <= width {
+			lines[n-1] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	return lines
+}
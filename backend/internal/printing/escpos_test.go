@@ -0,0 +1,187 @@
+package printing
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pos-backend/internal/models"
+)
+
+// plainText strips the ESC/POS commands from a document, leaving the printed lines
+func plainText(doc []byte) string {
+	for _, cmd := range [][]byte{cmdInit, cmdAlignLeft, cmdAlignCenter, cmdAlignRight,
+		cmdBoldOn, cmdBoldOff, cmdSizeNormal, cmdSizeDouble, cmdFeedCut} {
+		doc = bytes.ReplaceAll(doc, cmd, nil)
+	}
+	return string(doc)
+}
+
+func stringPtr(s string) *string { return &s }
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestBuilderFramesTheDocument(t *testing.T) {
+	doc := NewBuilder(32).Line("hello").Cut()
+	if !bytes.HasPrefix(doc, cmdInit) {
+		t.Error("document does not start by resetting the printer")
+	}
+	if !bytes.HasSuffix(doc, cmdFeedCut) {
+		t.Error("document does not end with a cut")
+	}
+	if got := plainText(doc); got != "hello\n" {
+		t.Errorf("text = %q, want %q", got, "hello\n")
+	}
+}
+
+func TestBuilderColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		double      bool
+		left, right string
+		want        string
+	}{
+		{"padded to the width", false, "Subtotal", "12.50", "Subtotal           12.50\n"},
+		{"half width when doubled", true, "TOTAL", "9.00", "TOTAL   9.00\n"},
+		{
+			"long left text wraps above the amount", false,
+			"2 Extra large pepperoni pizza", "31.00",
+			"2 Extra large\npepperoni pizza    31.00\n",
+		},
+		{"indent is kept", false, "   Extra cheese", "1.50", "   Extra cheese     1.50\n"},
+	}
+	for _, tt := range tests {
+		got := plainText(NewBuilder(24).Double(tt.double).Columns(tt.left, tt.right).Cut())
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		width := 24
+		if tt.double {
+			width = 12
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+			if len(line) > width {
+				t.Errorf("%s: line %q is over %d characters", tt.name, line, width)
+			}
+		}
+	}
+}
+
+func TestBuilderRule(t *testing.T) {
+	if got := plainText(NewBuilder(10).Rule().Double(true).Rule().Cut()); got != "----------\n-----\n" {
+		t.Errorf("rules = %q", got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Plain text 123", "Plain text 123"},
+		{"Caf\u00e9 cr\u00e8me", "Caf? cr?me"},
+		{"tab\there", "tab here"},
+		{"cut\x1b\x69 now\n", "cuti now"},
+	}
+	for _, tt := range tests {
+		if got := sanitize(tt.in); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 10, []string{""}},
+		{"one two three", 7, []string{"one two", "three"}},
+		{"  spaced   out  ", 20, []string{"spaced out"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"a b", 0, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := wrap(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderKitchenTicket(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "1042",
+		OrderType:   "dine_in",
+		Table:       &models.DiningTable{TableNumber: "7"},
+		Notes:       stringPtr("Birthday \x1b\x70 table"),
+	}
+	items := []models.OrderItem{
+		{Quantity: 2, Course: 1, Product: &models.Product{Name: "Soup"}},
+		{Quantity: 1, Course: 2, Product: &models.Product{Name: "Steak"},
+			Modifiers:           []models.OrderItemModifier{{ModifierName: "Medium rare"}},
+			SpecialInstructions: stringPtr("No salt")},
+	}
+	now := time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC)
+
+	got := plainText(RenderKitchenTicket(order, items, "Grill", 32, now))
+	want := strings.Join([]string{
+		"GRILL",
+		"#1042",
+		"DINE IN - Table 7",
+		"2026-10-16 19:30",
+		strings.Repeat("-", 32),
+		"-- Course 1 --",
+		"2 x Soup",
+		"-- Course 2 --",
+		"1 x Steak",
+		"   > Medium rare",
+		"   * No salt",
+		strings.Repeat("-", 32),
+		"NOTE: Birthday p table",
+		"",
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("ticket =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderReceipt(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "1042",
+		OrderType:   "takeout",
+		CreatedAt:   time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Items: []models.OrderItem{
+			{Quantity: 2, TotalPrice: 9, Product: &models.Product{Name: "Burger"}},
+			{Quantity: 1, TotalPrice: 3, Product: &models.Product{Name: "Fries"},
+				Adjustment: &models.OrderItemAdjustment{AdjustmentType: "comp"}},
+		},
+		Subtotal:    9,
+		Taxes:       []models.OrderTax{{TaxName: "VAT", Rate: 0.2, IsInclusive: true, TaxAmount: 1.5}},
+		TotalAmount: 9,
+		Payments: []models.Payment{
+			{PaymentMethod: "cash", Status: "completed", Amount: 5, TenderedAmount: floatPtr(10), ChangeGiven: 5},
+			{PaymentMethod: "credit_card", Status: "failed", Amount: 4},
+		},
+	}
+
+	got := plainText(RenderReceipt(order, "Corner Cafe", 32, order.CreatedAt))
+	for _, line := range []string{
+		"2 Burger                    9.00\n",
+		"1 Fries                     COMP\n",
+		"VAT 20.00% (incl.)          1.50\n",
+		"TOTAL       9.00\n",
+		"Cash                        5.00\n",
+		"   Change                   5.00\n",
+		"Paid                        5.00\n",
+		"Balance due                 4.00\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("receipt is missing %q:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "Credit Card") {
+		t.Errorf("receipt lists a failed payment:\n%s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package printing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Print job types
+const (
+	JobKitchenTicket = "kitchen_ticket"
+	JobReceipt       = "receipt"
+	JobTest          = "test"
+)
+
+// Printer types
+const (
+	PrinterKitchen = "kitchen"
+	PrinterReceipt = "receipt"
+)
+
+// receiptHeader is printed at the top of every customer receipt
+const receiptHeader = "POS RESTAURANT"
+
+// Queue renders orders into print jobs and stores them in the spool
+type Queue struct {
+	jobs   repository.PrintJobRepository
+	orders repository.OrderRepository
+}
+
+func NewQueue(jobs repository.PrintJobRepository, orders repository.OrderRepository) *Queue {
+	return &Queue{jobs: jobs, orders: orders}
+}
+
+// PrintKitchenTickets queues a ticket on every kitchen printer that has items to make.
+// With itemIDs only those items are printed, otherwise every fired item is. Station
+// printers get their station's items, printers without a station get all of them.
+func (q *Queue) PrintKitchenTickets(ctx context.Context, orderID uuid.UUID, itemIDs []uuid.UUID, createdBy *uuid.UUID) (int, error) {
+	order, err := q.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	printers, err := q.jobs.ListPrinters(ctx, PrinterKitchen)
+	if err != nil {
+		return 0, err
+	}
+
+	only := map[uuid.UUID]bool{}
+	for _, id := range itemIDs {
+		only[id] = true
+	}
+	var items []models.OrderItem
+	for _, item := range order.Items {
+		if item.IsHeld || (item.Adjustment != nil && item.Adjustment.AdjustmentType == "void") {
+			continue
+		}
+		if len(only) > 0 && !only[item.ID] {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	now := time.Now()
+	queued := 0
+	for _, printer := range printers {
+		title := "KITCHEN"
+		printed := items
+		if printer.StationID != nil {
+			title = *printer.StationName
+			printed = nil
+			for _, item := range items {
+				if item.StationID != nil && *item.StationID == *printer.StationID {
+					printed = append(printed, item)
+				}
+			}
+		}
+		if len(printed) == 0 {
+			continue
+		}
+
+		payload := RenderKitchenTicket(order, printed, title, printer.CharsPerLine, now)
+		if _, err := q.enqueue(ctx, printer.ID, JobKitchenTicket, &order.ID, payload, createdBy); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// PrintReceipt queues a customer receipt on the first active receipt printer
+func (q *Queue) PrintReceipt(ctx context.Context, orderID uuid.UUID, createdBy *uuid.UUID) (uuid.UUID, error) {
+	order, err := q.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	printers, err := q.jobs.ListPrinters(ctx, PrinterReceipt)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(printers) == 0 {
+		return uuid.Nil, fmt.Errorf("no_receipt_printer: no active receipt printer is configured")
+	}
+
+	printer := printers[0]
+	payload := RenderReceipt(order, receiptHeader, printer.CharsPerLine, time.Now())
+	return q.enqueue(ctx, printer.ID, JobReceipt, &order.ID, payload, createdBy)
+}
+
+// PrintTest queues a test page on a printer
+func (q *Queue) PrintTest(ctx context.Context, printerID uuid.UUID, createdBy *uuid.UUID) (uuid.UUID, error) {
+	printer, err := q.jobs.GetPrinter(ctx, printerID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return q.enqueue(ctx, printer.ID, JobTest, nil, RenderTestPage(printer, time.Now()), createdBy)
+}
+
+func (q *Queue) enqueue(ctx context.Context, printerID uuid.UUID, jobType string, orderID *uuid.UUID, payload []byte, createdBy *uuid.UUID) (uuid.UUID, error) {
+	return q.jobs.EnqueueJob(ctx, models.PrintJob{
+		PrinterID: printerID,
+		JobType:   jobType,
+		OrderID:   orderID,
+		Payload:   payload,
+		CreatedBy: createdBy,
+	})
+}
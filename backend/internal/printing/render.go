@@ -0,0 +1,186 @@
+package printing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pos-backend/internal/models"
+)
+
+// timeLayout is used for the timestamps printed on tickets and receipts
+const timeLayout = "2006-01-02 15:04"
+
+var orderTypeLabels = map[string]string{
+	"dine_in":  "DINE IN",
+	"takeout":  "TAKEOUT",
+	"delivery": "DELIVERY",
+}
+
+// RenderKitchenTicket renders the given items of an order as a kitchen ticket.
+// title names the station (or "KITCHEN" for printers that take every station).
+func RenderKitchenTicket(order *models.Order, items []models.OrderItem, title string, width int, now time.Time) []byte {
+	b := NewBuilder(width)
+
+	b.Center().Bold(true).Double(true).Line(strings.ToUpper(title))
+	b.Line("#" + order.OrderNumber).Double(false).Bold(false)
+	b.Line(orderHeadline(order))
+	b.Line(now.Format(timeLayout)).Left()
+	b.Rule()
+
+	course := 0
+	for _, item := range items {
+		if item.Course != course {
+			course = item.Course
+			b.Bold(true).Linef("-- Course %d --", course).Bold(false)
+		}
+		b.Bold(true).Double(true)
+		b.Wrapped("", fmt.Sprintf("%d x %s", item.Quantity, itemName(item)))
+		b.Double(false).Bold(false)
+		for _, modifier := range item.Modifiers {
+			b.Wrapped("   > ", modifier.ModifierName)
+		}
+		if item.SpecialInstructions != nil && *item.SpecialInstructions != "" {
+			b.Bold(true).Wrapped("   * ", *item.SpecialInstructions).Bold(false)
+		}
+	}
+
+	if order.Notes != nil && *order.Notes != "" {
+		b.Rule()
+		b.Wrapped("", "NOTE: "+*order.Notes)
+	}
+
+	return b.Feed(1).Cut()
+}
+
+// RenderReceipt renders a customer receipt with totals, taxes and payments
+func RenderReceipt(order *models.Order, header string, width int, now time.Time) []byte {
+	b := NewBuilder(width)
+
+	b.Center()
+	if header != "" {
+		b.Bold(true).Double(true).Line(header).Double(false).Bold(false)
+	}
+	b.Line("Order #" + order.OrderNumber)
+	b.Line(orderHeadline(order))
+	b.Line(order.CreatedAt.Format(timeLayout)).Left()
+	if order.User != nil {
+		b.Line("Served by " + strings.TrimSpace(order.User.FirstName+" "+order.User.LastName))
+	}
+	b.Rule()
+
+	for _, item := range order.Items {
+		amount := money(item.TotalPrice)
+		if item.Adjustment != nil {
+			amount = strings.ToUpper(item.Adjustment.AdjustmentType)
+		}
+		b.Columns(fmt.Sprintf("%d %s", item.Quantity, itemName(item)), amount)
+		for _, modifier := range item.Modifiers {
+			if modifier.PriceDelta != 0 {
+				b.Columns("   "+modifier.ModifierName, money(modifier.PriceDelta*float64(item.Quantity)))
+			} else {
+				b.Wrapped("   ", modifier.ModifierName)
+			}
+		}
+	}
+	b.Rule()
+
+	b.Columns("Subtotal", money(order.Subtotal))
+	for _, discount := range order.Discounts {
+		b.Columns(discount.PromotionName, "-"+money(discount.Amount))
+	}
+	for _, tax := range order.Taxes {
+		label := fmt.Sprintf("%s %.2f%%", tax.TaxName, tax.Rate*100)
+		if tax.IsInclusive {
+			label += " (incl.)"
+		}
+		b.Columns(label, money(tax.TaxAmount))
+	}
+	if order.ServiceCharge != nil {
+		b.Columns(fmt.Sprintf("%s %.0f%%", order.ServiceCharge.Name, order.ServiceCharge.Rate*100), money(order.ServiceCharge.Amount))
+	}
+	b.Bold(true).Double(true)
+	b.Columns("TOTAL", money(order.TotalAmount))
+	b.Double(false).Bold(false)
+
+	var paid, tips float64
+	if len(order.Payments) > 0 {
+		b.Rule()
+		for _, payment := range order.Payments {
+			if payment.Status != "completed" && payment.Status != "refunded" {
+				continue
+			}
+			paid += payment.Amount - payment.RefundedAmount
+			b.Columns(paymentLabel(payment.PaymentMethod), money(payment.Amount))
+			if payment.TenderedAmount != nil && payment.ChangeGiven > 0 {
+				b.Columns("   Tendered", money(*payment.TenderedAmount))
+				b.Columns("   Change", money(payment.ChangeGiven))
+			}
+			if payment.RefundedAmount > 0 {
+				b.Columns("   Refunded", "-"+money(payment.RefundedAmount))
+			}
+			tips += payment.TipAmount
+		}
+		b.Columns("Paid", money(paid))
+		if tips > 0 {
+			b.Columns("Tip", money(tips))
+		}
+		if balance := order.TotalAmount - paid; balance > 0.005 {
+			b.Bold(true).Columns("Balance due", money(balance)).Bold(false)
+		}
+	}
+
+	b.Feed(1).Center().Line("Thank you!").Line(now.Format(timeLayout)).Left()
+	return b.Cut()
+}
+
+// RenderTestPage renders a short page to check a printer's connection and width
+func RenderTestPage(printer *models.Printer, now time.Time) []byte {
+	b := NewBuilder(printer.CharsPerLine)
+	b.Center().Bold(true).Double(true).Line("TEST PRINT").Double(false).Bold(false)
+	b.Line(printer.Name).Line(now.Format(timeLayout)).Left()
+	b.Rule()
+	b.Columns("Type", printer.PrinterType)
+	b.Columns("Connection", printer.ConnectionType)
+	if printer.StationName != nil {
+		b.Columns("Station", *printer.StationName)
+	}
+	b.Columns("Width", fmt.Sprintf("%d chars", printer.CharsPerLine))
+	b.Line(strings.Repeat("0123456789", printer.CharsPerLine/10+1)[:printer.CharsPerLine])
+	return b.Feed(1).Cut()
+}
+
+func orderHeadline(order *models.Order) string {
+	label, ok := orderTypeLabels[order.OrderType]
+	if !ok {
+		label = strings.ToUpper(order.OrderType)
+	}
+	if order.Table != nil && order.Table.TableNumber != "" {
+		label += " - Table " + order.Table.TableNumber
+	}
+	if order.CustomerName != nil && *order.CustomerName != "" {
+		label += " - " + *order.CustomerName
+	}
+	return label
+}
+
+func itemName(item models.OrderItem) string {
+	if item.Product != nil && item.Product.Name != "" {
+		return item.Product.Name
+	}
+	return "Item"
+}
+
+func paymentLabel(method string) string {
+	words := strings.Split(method, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,149 @@
+package printing
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	batchSize    = 10
+	retryBase    = 5 * time.Second
+	retryMax     = 5 * time.Minute
+)
+
+// Spool delivers queued print jobs to their printers, retrying failed deliveries
+// with exponential backoff until the job runs out of attempts
+type Spool struct {
+	jobs repository.PrintJobRepository
+}
+
+func NewSpool(jobs repository.PrintJobRepository) *Spool {
+	return &Spool{jobs: jobs}
+}
+
+// Run polls the spool until ctx is cancelled. Jobs left printing by a previous
+// process are requeued first so nothing is lost across restarts.
+func (s *Spool) Run(ctx context.Context) {
+	if n, err := s.jobs.ResetInterruptedJobs(ctx); err != nil {
+		log.Printf("print spool: failed to requeue interrupted jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("print spool: requeued %d interrupted jobs", n)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		s.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Spool) deliverDue(ctx context.Context) {
+	jobs, err := s.jobs.ClaimDueJobs(ctx, batchSize)
+	if err != nil {
+		log.Printf("print spool: failed to claim jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		sendErr := Send(ctx, job.Printer, job.Payload)
+		if sendErr == nil {
+			err = s.jobs.CompleteJob(ctx, job.ID)
+		} else {
+			var retryAt *time.Time
+			if job.Attempts < job.MaxAttempts {
+				at := time.Now().Add(retryDelay(job.Attempts))
+				retryAt = &at
+			}
+			log.Printf("print spool: job %s on %s failed (attempt %d/%d): %v",
+				job.ID, job.Printer.Name, job.Attempts, job.MaxAttempts, sendErr)
+			err = s.jobs.FailJob(ctx, job.ID, sendErr.Error(), retryAt)
+		}
+		if err != nil {
+			log.Printf("print spool: failed to record result of job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// retryDelay doubles the wait after every failed attempt, up to retryMax
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	if delay > retryMax {
+		delay = retryMax
+	}
+	return delay
+}
+
+// Listen prints kitchen tickets when orders are created, items added or courses fired,
+// and a receipt once an order is fully paid. It follows the default event broker and
+// resumes from the last seen event if it is dropped for falling behind.
+func Listen(ctx context.Context, queue *Queue) {
+	filter, _ := events.NewFilter("admin", events.OrderCreated+","+events.ItemAdded+","+events.CourseFired+","+events.PaymentCompleted, "")
+
+	var lastID uint64
+	for {
+		sub, replay, _ := events.Default.Subscribe(filter, lastID)
+		for _, event := range replay {
+			lastID = event.ID
+			handleEvent(ctx, queue, event)
+		}
+
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					break receive
+				}
+				lastID = event.ID
+				handleEvent(ctx, queue, event)
+			}
+		}
+	}
+}
+
+func handleEvent(ctx context.Context, queue *Queue, event events.Event) {
+	// event data is built by several packages with different map types; decode it uniformly
+	var data struct {
+		ItemIDs   []uuid.UUID `json:"item_ids"`
+		FullyPaid bool        `json:"fully_paid"`
+	}
+	if raw, err := json.Marshal(event.Data); err == nil {
+		json.Unmarshal(raw, &data)
+	}
+
+	var err error
+	switch event.Type {
+	case events.OrderCreated:
+		_, err = queue.PrintKitchenTickets(ctx, event.OrderID, nil, nil)
+	case events.ItemAdded, events.CourseFired:
+		if len(data.ItemIDs) > 0 {
+			_, err = queue.PrintKitchenTickets(ctx, event.OrderID, data.ItemIDs, nil)
+		}
+	case events.PaymentCompleted:
+		if data.FullyPaid {
+			_, err = queue.PrintReceipt(ctx, event.OrderID, nil)
+		}
+	}
+	if err != nil {
+		log.Printf("print spool: failed to queue %s print for order %s: %v", event.Type, event.OrderID, err)
+	}
+}
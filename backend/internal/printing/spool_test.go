@@ -0,0 +1,103 @@
+package printing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/models"
+	"pos-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeOrders serves one order; the other OrderRepository methods are not used by the queue
+type fakeOrders struct {
+	repository.OrderRepository
+	order *models.Order
+}
+
+func (f *fakeOrders) GetOrderByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	return f.order, nil
+}
+
+// fakeJobs records the jobs queued on a fixed set of printers
+type fakeJobs struct {
+	repository.PrintJobRepository
+	printers []models.Printer
+	queued   []models.PrintJob
+}
+
+func (f *fakeJobs) ListPrinters(ctx context.Context, printerType string) ([]models.Printer, error) {
+	var printers []models.Printer
+	for _, printer := range f.printers {
+		if printer.PrinterType == printerType {
+			printers = append(printers, printer)
+		}
+	}
+	return printers, nil
+}
+
+func (f *fakeJobs) EnqueueJob(ctx context.Context, job models.PrintJob) (uuid.UUID, error) {
+	job.ID = uuid.New()
+	f.queued = append(f.queued, job)
+	return job.ID, nil
+}
+
+func TestItemAddedPrintsTicketForNewItems(t *testing.T) {
+	grill, bar := uuid.New(), uuid.New()
+	grillName, barName := "Grill", "Bar"
+	grillPrinter := models.Printer{ID: uuid.New(), PrinterType: PrinterKitchen, StationID: &grill, StationName: &grillName, CharsPerLine: 32}
+	barPrinter := models.Printer{ID: uuid.New(), PrinterType: PrinterKitchen, StationID: &bar, StationName: &barName, CharsPerLine: 32}
+
+	// An open order that was already sent to the kitchen, with a burger just added
+	steak := models.OrderItem{ID: uuid.New(), Quantity: 1, Course: 1, StationID: &grill, Product: &models.Product{Name: "Steak"}}
+	burger := models.OrderItem{ID: uuid.New(), Quantity: 2, Course: 1, StationID: &grill, Product: &models.Product{Name: "Burger"}}
+	beer := models.OrderItem{ID: uuid.New(), Quantity: 1, Course: 1, StationID: &bar, Product: &models.Product{Name: "Beer"}}
+	order := &models.Order{ID: uuid.New(), OrderNumber: "1042", OrderType: "dine_in", Status: "preparing",
+		Items: []models.OrderItem{steak, burger, beer}}
+
+	jobs := &fakeJobs{printers: []models.Printer{grillPrinter, barPrinter}}
+	queue := NewQueue(jobs, &fakeOrders{order: order})
+
+	// The event AddOrderItem publishes once the item is committed
+	event := events.NewBroker(10).Publish(events.ItemAdded, order.ID, []string{grill.String()}, map[string]interface{}{
+		"item_ids": []uuid.UUID{burger.ID},
+		"status":   "pending",
+	})
+	handleEvent(context.Background(), queue, event)
+
+	if len(jobs.queued) != 1 {
+		t.Fatalf("queued %d jobs, want one ticket for the grill", len(jobs.queued))
+	}
+	job := jobs.queued[0]
+	if job.PrinterID != grillPrinter.ID || job.JobType != JobKitchenTicket || job.OrderID == nil || *job.OrderID != order.ID {
+		t.Errorf("queued %+v, want a kitchen ticket for the order on the grill printer", job)
+	}
+	text := plainText(job.Payload)
+	if !strings.Contains(text, "2 x Burger") {
+		t.Errorf("ticket does not list the added burger:\n%s", text)
+	}
+	if strings.Contains(text, "Steak") {
+		t.Errorf("ticket reprints an item the kitchen already has:\n%s", text)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, retryBase},
+		{2, 2 * retryBase},
+		{4, 8 * retryBase},
+		{20, retryMax},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
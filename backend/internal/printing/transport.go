@@ -0,0 +1,100 @@
+package printing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pos-backend/internal/models"
+)
+
+// Printer connection types
+const (
+	ConnectionNetwork  = "network"
+	ConnectionFile     = "file"
+	ConnectionLoopback = "loopback"
+)
+
+// defaultPort is the raw printing port used by ESC/POS network printers
+const defaultPort = "9100"
+
+// writeTimeout bounds how long a single delivery may take
+const writeTimeout = 10 * time.Second
+
+// SpoolDir is the directory file printers write into. A file printer's address is a
+// path relative to it; link a device node into it to print to local hardware.
+var SpoolDir = "spool"
+
+// FilePath resolves a file printer's address inside SpoolDir, rejecting absolute
+// paths and parent directory segments so an address cannot point elsewhere.
+func FilePath(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("printer has no file path")
+	}
+	if filepath.IsAbs(address) || strings.HasPrefix(address, "/") {
+		return "", fmt.Errorf("file printer path %q must be relative to the spool directory", address)
+	}
+	for _, segment := range strings.FieldsFunc(address, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return "", fmt.Errorf("file printer path %q must stay inside the spool directory", address)
+		}
+	}
+	return filepath.Join(SpoolDir, address), nil
+}
+
+// Send delivers an ESC/POS document to a printer. Network printers receive it over a raw
+// TCP connection, file printers append it to a file in SpoolDir, and loopback
+// printers accept it without output so the flow can be exercised without hardware.
+func Send(ctx context.Context, printer *models.Printer, payload []byte) error {
+	switch printer.ConnectionType {
+	case ConnectionNetwork:
+		return sendNetwork(ctx, printer.Address, payload)
+	case ConnectionFile:
+		return sendFile(printer.Address, payload)
+	case ConnectionLoopback:
+		return nil
+	}
+	return fmt.Errorf("unsupported printer connection type %q", printer.ConnectionType)
+}
+
+func sendNetwork(ctx context.Context, address string, payload []byte) error {
+	if address == "" {
+		return fmt.Errorf("printer has no address")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultPort)
+	}
+
+	dialer := net.Dialer{Timeout: writeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(payload)
+	return err
+}
+
+func sendFile(address string, payload []byte) error {
+	path, err := FilePath(address)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(payload); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,72 @@
+package printing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pos-backend/internal/models"
+)
+
+func TestFilePath(t *testing.T) {
+	dir := t.TempDir()
+	SpoolDir = dir
+	defer func() { SpoolDir = "spool" }()
+
+	tests := []struct {
+		address string
+		want    string // empty when the address is rejected
+	}{
+		{"grill.prn", filepath.Join(dir, "grill.prn")},
+		{"kitchen/grill.prn", filepath.Join(dir, "kitchen", "grill.prn")},
+		{"./grill.prn", filepath.Join(dir, "grill.prn")},
+		{"", ""},
+		{"/etc/passwd", ""},
+		{"../grill.prn", ""},
+		{"kitchen/../../grill.prn", ""},
+		{"kitchen/..", ""},
+		{`..\grill.prn`, ""},
+	}
+	for _, tt := range tests {
+		got, err := FilePath(tt.address)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("FilePath(%q) = %q, want an error", tt.address, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("FilePath(%q) = %q, %v, want %q", tt.address, got, err, tt.want)
+		}
+	}
+}
+
+func TestSendFileWritesInsideSpoolDir(t *testing.T) {
+	dir := t.TempDir()
+	SpoolDir = dir
+	defer func() { SpoolDir = "spool" }()
+
+	printer := &models.Printer{ConnectionType: ConnectionFile, Address: "grill.prn"}
+	for i := 0; i < 2; i++ {
+		if err := Send(context.Background(), printer, []byte("ticket\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "grill.prn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "ticket\nticket\n" {
+		t.Errorf("spool file = %q, want both tickets appended", got)
+	}
+
+	outside := filepath.Join(dir, "..", "outside.prn")
+	printer.Address = "../outside.prn"
+	if err := Send(context.Background(), printer, []byte("ticket\n")); err == nil {
+		t.Error("sent a job outside the spool directory")
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("created %s outside the spool directory", outside)
+	}
+}
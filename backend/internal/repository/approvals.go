@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// approverRoles are the roles allowed to sign off on voids, comps and refunds
+var approverRoles = []string{"admin", "manager"}
+
+// IsApproverRole reports whether role may approve manager-only actions
+func IsApproverRole(role string) bool {
+	for _, r := range approverRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PINs are 4 to 8 digits
+const (
+	minPINLength = 4
+	maxPINLength = 8
+)
+
+// A user who fails this many approvals within the window is locked out of asking for more
+// until the window has passed, so a PIN cannot be guessed from a till
+const (
+	maxFailedApprovals    = 5
+	failedApprovalsWindow = 15 * time.Minute
+)
+
+// approvalFailures tracks failed approvals per requesting user
+var approvalFailures = struct {
+	sync.Mutex
+	attempts map[uuid.UUID][]time.Time
+}{attempts: map[uuid.UUID][]time.Time{}}
+
+// recentApprovalFailures drops failures older than the window and returns those left
+func recentApprovalFailures(requestedBy uuid.UUID, now time.Time) []time.Time {
+	recent := approvalFailures.attempts[requestedBy][:0]
+	for _, at := range approvalFailures.attempts[requestedBy] {
+		if now.Sub(at) < failedApprovalsWindow {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) == 0 {
+		delete(approvalFailures.attempts, requestedBy)
+		return nil
+	}
+	approvalFailures.attempts[requestedBy] = recent
+	return recent
+}
+
+// checkApprovalLockout returns approval_locked while requestedBy has too many recent failures
+func checkApprovalLockout(requestedBy uuid.UUID, now time.Time) error {
+	approvalFailures.Lock()
+	defer approvalFailures.Unlock()
+
+	recent := recentApprovalFailures(requestedBy, now)
+	if len(recent) < maxFailedApprovals {
+		return nil
+	}
+	wait := recent[0].Add(failedApprovalsWindow).Sub(now)
+	return fmt.Errorf("approval_locked: too many failed approvals; try again in %d minutes", int(wait.Minutes())+1)
+}
+
+// recordApprovalFailure counts a failed approval against requestedBy
+func recordApprovalFailure(requestedBy uuid.UUID, now time.Time) {
+	approvalFailures.Lock()
+	defer approvalFailures.Unlock()
+
+	approvalFailures.attempts[requestedBy] = append(recentApprovalFailures(requestedBy, now), now)
+}
+
+// ValidatePIN returns invalid_pin unless pin is 4 to 8 digits
+func ValidatePIN(pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return fmt.Errorf("invalid_pin: PIN must be %d to %d digits", minPINLength, maxPINLength)
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid_pin: PIN must be %d to %d digits", minPINLength, maxPINLength)
+		}
+	}
+	return nil
+}
+
+// CheckPINAvailable returns pin_in_use when another user already has pin. An approval PIN
+// identifies the approver on its own, so no two users may share one.
+func CheckPINAvailable(ctx context.Context, db *sql.DB, pin string, userID string) error {
+	rows, err := db.QueryContext(ctx, `
+        SELECT pin_hash FROM users
+        WHERE pin_hash IS NOT NULL AND id::text <> $1
+    `, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pinHash string
+		if err := rows.Scan(&pinHash); err != nil {
+			return err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(pinHash), []byte(pin)) == nil {
+			return fmt.Errorf("pin_in_use: choose a different PIN")
+		}
+	}
+	return rows.Err()
+}
+
+// VerifyManagerApproval checks a manager's credentials or PIN and returns the approver's ID.
+// requestedBy is the user asking for approval; too many failures lock them out for a while.
+func VerifyManagerApproval(ctx context.Context, db *sql.DB, approval models.ManagerApproval, requestedBy uuid.UUID) (uuid.UUID, error) {
+	if err := checkApprovalLockout(requestedBy, time.Now()); err != nil {
+		return uuid.Nil, err
+	}
+
+	approverID, err := verifyManagerApproval(ctx, db, approval)
+	if err != nil && strings.HasPrefix(err.Error(), "invalid_approval") {
+		recordApprovalFailure(requestedBy, time.Now())
+	}
+	return approverID, err
+}
+
+func verifyManagerApproval(ctx context.Context, db *sql.DB, approval models.ManagerApproval) (uuid.UUID, error) {
+	if approval.Username != "" {
+		var id uuid.UUID
+		var passwordHash string
+		err := db.QueryRowContext(ctx, `
+            SELECT id, password_hash FROM users
+            WHERE username = $1 AND is_active = true AND role IN ('admin', 'manager')
+        `, approval.Username).Scan(&id, &passwordHash)
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("invalid_approval: approver not found")
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(approval.Password)) != nil {
+			return uuid.Nil, fmt.Errorf("invalid_approval: wrong credentials")
+		}
+		return id, nil
+	}
+
+	if approval.PIN != "" {
+		if ValidatePIN(approval.PIN) != nil {
+			return uuid.Nil, fmt.Errorf("invalid_approval: wrong PIN")
+		}
+
+		// PINs are salted, so each manager PIN has to be compared in turn
+		rows, err := db.QueryContext(ctx, `
+            SELECT id, pin_hash FROM users
+            WHERE is_active = true AND role IN ('admin', 'manager') AND pin_hash IS NOT NULL
+        `)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id uuid.UUID
+			var pinHash string
+			if err := rows.Scan(&id, &pinHash); err != nil {
+				return uuid.Nil, err
+			}
+			if bcrypt.CompareHashAndPassword([]byte(pinHash), []byte(approval.PIN)) == nil {
+				return id, nil
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, fmt.Errorf("invalid_approval: wrong PIN")
+	}
+
+	return uuid.Nil, fmt.Errorf("approval_required: manager credentials or PIN required")
+}
+
+// VerifyManagerApproval checks a manager's credentials or PIN using the repository connection
+func (r *PostgresOrderRepository) VerifyManagerApproval(ctx context.Context, approval models.ManagerApproval, requestedBy uuid.UUID) (uuid.UUID, error) {
+	return VerifyManagerApproval(ctx, r.db, approval, requestedBy)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidatePIN(t *testing.T) {
+	tests := map[string]bool{
+		"1234":      true,
+		"12345678":  true,
+		"123":       false,
+		"123456789": false,
+		"12a4":      false,
+		"12 34":     false,
+		"":          false,
+	}
+	for pin, valid := range tests {
+		if err := ValidatePIN(pin); (err == nil) != valid {
+			t.Errorf("ValidatePIN(%q) = %v, want valid %v", pin, err, valid)
+		}
+	}
+}
+
+func TestApprovalLockout(t *testing.T) {
+	requestedBy := uuid.New()
+	other := uuid.New()
+	start := time.Now()
+
+	for i := 0; i < maxFailedApprovals; i++ {
+		if err := checkApprovalLockout(requestedBy, start); err != nil {
+			t.Fatalf("locked out after %d failures", i)
+		}
+		recordApprovalFailure(requestedBy, start)
+	}
+
+	err := checkApprovalLockout(requestedBy, start.Add(time.Minute))
+	if err == nil || !strings.HasPrefix(err.Error(), "approval_locked") {
+		t.Fatalf("error = %v, want approval_locked", err)
+	}
+	if err := checkApprovalLockout(other, start); err != nil {
+		t.Errorf("another user was locked out: %v", err)
+	}
+
+	if err := checkApprovalLockout(requestedBy, start.Add(failedApprovalsWindow)); err != nil {
+		t.Errorf("still locked out once the window passed: %v", err)
+	}
+}
@@ -0,0 +1,480 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Day report types
+const (
+	DayReportX = "x"
+	DayReportZ = "z"
+)
+
+// querier runs queries on a *sql.DB or inside a *sql.Tx
+type querier interface {
+	rowQuerier
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// BusinessDayRepository defines X reports and the Z report close of a business day
+type BusinessDayRepository interface {
+	CurrentReport(ctx context.Context) (*models.DayReport, error)
+	CloseDay(ctx context.Context, businessDate *string, closedBy uuid.UUID) (uuid.UUID, error)
+	GetZReport(ctx context.Context, reportID uuid.UUID) (*models.DayReport, error)
+	ListZReports(ctx context.Context, limit, offset int) ([]models.DayReport, int, error)
+}
+
+// PostgresBusinessDayRepository is an implementation of BusinessDayRepository using *sql.DB
+type PostgresBusinessDayRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresBusinessDayRepository(db *sql.DB) *PostgresBusinessDayRepository {
+	return &PostgresBusinessDayRepository{db: db}
+}
+
+// checkBusinessDayOpen returns business_day_closed when orderID was created in a business
+// day that has already been closed with a Z report
+func checkBusinessDayOpen(ctx context.Context, q rowQuerier, orderID uuid.UUID) error {
+	var closed bool
+	if err := q.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM z_reports z WHERE z.period_end > o.created_at)
+        FROM orders o
+        WHERE o.id = $1
+    `, orderID).Scan(&closed); err != nil {
+		return err
+	}
+	if closed {
+		return fmt.Errorf("business_day_closed: order belongs to a closed business day")
+	}
+	return nil
+}
+
+// businessDayPeriod returns the bounds of the open business day: from the end of the last
+// Z report (or the first order ever taken) up to now
+func businessDayPeriod(ctx context.Context, q rowQuerier) (time.Time, time.Time, error) {
+	var start, end time.Time
+	err := q.QueryRowContext(ctx, `
+        SELECT COALESCE((SELECT MAX(period_end) FROM z_reports),
+                        (SELECT MIN(created_at) FROM orders),
+                        CURRENT_TIMESTAMP),
+               CURRENT_TIMESTAMP
+    `).Scan(&start, &end)
+	return start, end, err
+}
+
+// buildDayReport totals the orders created, payments taken, refunds made and items
+// voided or comped between start and end
+func buildDayReport(ctx context.Context, q querier, start, end time.Time) (*models.DayReport, error) {
+	report := models.DayReport{PeriodStart: start, PeriodEnd: end}
+
+	// Sales come from the orders created in the period; only completed orders count
+	if err := q.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE status = 'completed'),
+               COUNT(*) FILTER (WHERE status = 'cancelled'),
+               COALESCE(SUM(total_amount) FILTER (WHERE status = 'completed'), 0),
+               COALESCE(SUM(discount_amount) FILTER (WHERE status = 'completed'), 0),
+               COALESCE(SUM(tax_amount) FILTER (WHERE status = 'completed'), 0),
+               COALESCE(SUM(service_charge_amount) FILTER (WHERE status = 'completed'), 0)
+        FROM orders
+        WHERE created_at >= $1 AND created_at < $2
+    `, start, end).Scan(&report.OrderCount, &report.CancelledCount, &report.GrossSales, &report.DiscountTotal,
+		&report.TaxTotal, &report.ServiceChargeTotal); err != nil {
+		return nil, err
+	}
+	report.NetSales = roundMoney(report.GrossSales - report.TaxTotal)
+
+	// Refunds land on the day they were made, whichever day the order was from
+	if err := q.QueryRowContext(ctx, `
+        SELECT COUNT(*), COALESCE(SUM(amount), 0)
+        FROM refunds
+        WHERE status = 'completed' AND created_at >= $1 AND created_at < $2
+    `, start, end).Scan(&report.RefundCount, &report.RefundTotal); err != nil {
+		return nil, err
+	}
+
+	if err := q.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE adjustment_type = 'void'),
+               COALESCE(SUM(amount) FILTER (WHERE adjustment_type = 'void'), 0),
+               COUNT(*) FILTER (WHERE adjustment_type = 'comp'),
+               COALESCE(SUM(amount) FILTER (WHERE adjustment_type = 'comp'), 0)
+        FROM order_item_adjustments
+        WHERE created_at >= $1 AND created_at < $2
+    `, start, end).Scan(&report.VoidCount, &report.VoidTotal, &report.CompCount, &report.CompTotal); err != nil {
+		return nil, err
+	}
+
+	paymentRows, err := q.QueryContext(ctx, `
+        WITH taken AS (
+            SELECT payment_method, COUNT(*) AS payment_count, SUM(amount) AS amount, SUM(tip_amount) AS tips
+            FROM payments
+            WHERE status IN ('completed', 'refunded') AND processed_at >= $1 AND processed_at < $2
+            GROUP BY payment_method
+        ), returned AS (
+            SELECT p.payment_method, SUM(rf.amount) AS amount
+            FROM refunds rf
+            JOIN payments p ON rf.payment_id = p.id
+            WHERE rf.status = 'completed' AND rf.created_at >= $1 AND rf.created_at < $2
+            GROUP BY p.payment_method
+        )
+        SELECT COALESCE(t.payment_method, rt.payment_method), COALESCE(t.payment_count, 0),
+               COALESCE(t.amount, 0), COALESCE(t.tips, 0), COALESCE(rt.amount, 0)
+        FROM taken t
+        FULL OUTER JOIN returned rt ON rt.payment_method = t.payment_method
+        ORDER BY 1
+    `, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer paymentRows.Close()
+
+	report.Payments = []models.DayPaymentTotal{}
+	for paymentRows.Next() {
+		var total models.DayPaymentTotal
+		if err := paymentRows.Scan(&total.PaymentMethod, &total.PaymentCount, &total.Amount, &total.TipAmount, &total.RefundAmount); err != nil {
+			return nil, err
+		}
+		total.NetAmount = roundMoney(total.Amount + total.TipAmount - total.RefundAmount)
+		report.TipTotal += total.TipAmount
+		report.Payments = append(report.Payments, total)
+	}
+	if err := paymentRows.Err(); err != nil {
+		return nil, err
+	}
+	report.TipTotal = roundMoney(report.TipTotal)
+
+	taxRows, err := q.QueryContext(ctx, `
+        SELECT ot.tax_name, ot.rate, ot.is_inclusive, SUM(ot.taxable_amount), SUM(ot.tax_amount)
+        FROM order_taxes ot
+        JOIN orders o ON ot.order_id = o.id
+        WHERE o.status = 'completed' AND o.created_at >= $1 AND o.created_at < $2
+        GROUP BY ot.tax_name, ot.rate, ot.is_inclusive
+        ORDER BY ot.tax_name, ot.rate
+    `, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer taxRows.Close()
+
+	report.Taxes = []models.DayTaxTotal{}
+	for taxRows.Next() {
+		var total models.DayTaxTotal
+		if err := taxRows.Scan(&total.TaxName, &total.Rate, &total.IsInclusive, &total.TaxableAmount, &total.TaxAmount); err != nil {
+			return nil, err
+		}
+		report.Taxes = append(report.Taxes, total)
+	}
+	if err := taxRows.Err(); err != nil {
+		return nil, err
+	}
+
+	discountRows, err := q.QueryContext(ctx, `
+        SELECT d.promotion_name, COUNT(DISTINCT d.order_id), SUM(d.amount)
+        FROM order_discounts d
+        JOIN orders o ON d.order_id = o.id
+        WHERE o.status = 'completed' AND o.created_at >= $1 AND o.created_at < $2
+        GROUP BY d.promotion_name
+        ORDER BY SUM(d.amount) DESC
+    `, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer discountRows.Close()
+
+	report.Discounts = []models.DayDiscountTotal{}
+	for discountRows.Next() {
+		var total models.DayDiscountTotal
+		if err := discountRows.Scan(&total.PromotionName, &total.OrderCount, &total.Amount); err != nil {
+			return nil, err
+		}
+		report.Discounts = append(report.Discounts, total)
+	}
+	if err := discountRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// CurrentReport returns an X report: the open business day's figures so far. Nothing is saved.
+func (r *PostgresBusinessDayRepository) CurrentReport(ctx context.Context) (*models.DayReport, error) {
+	start, end, err := businessDayPeriod(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := buildDayReport(ctx, r.db, start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.ReportType = DayReportX
+	return report, nil
+}
+
+// CloseDay writes the Z report for the open business day and closes it. businessDate
+// defaults to the current business date in the store's time zone and must come after the
+// last closed day. Every order in the period has to be completed or cancelled first.
+func (r *PostgresBusinessDayRepository) CloseDay(ctx context.Context, businessDate *string, closedBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	// One close at a time, so two closes cannot claim the same period
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE z_reports IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return uuid.Nil, err
+	}
+
+	var date string
+	if businessDate != nil {
+		date = *businessDate
+	} else {
+		settings, err := GetStoreSettings(ctx, tx)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if date, err = CurrentBusinessDate(ctx, tx, settings); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	var lastDate sql.NullString
+	if err := tx.QueryRowContext(ctx, "SELECT MAX(business_date)::text FROM z_reports").Scan(&lastDate); err != nil {
+		return uuid.Nil, err
+	}
+	if lastDate.Valid {
+		// Dates are YYYY-MM-DD, so they compare as strings
+		if date == lastDate.String {
+			return uuid.Nil, fmt.Errorf("business_day_already_closed: %s is already closed", date)
+		}
+		if date < lastDate.String {
+			return uuid.Nil, fmt.Errorf("invalid_business_date: must be after %s", lastDate.String)
+		}
+	}
+
+	start, end, err := businessDayPeriod(ctx, tx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Wait for anyone still working on the period's orders, then hold off every change to
+	// orders until the report is written. An order whose transaction began before end but
+	// had not committed yet becomes visible once the table lock is granted; one inserted
+	// after this commits is refused by checkBusinessDayOpen.
+	if _, err := tx.ExecContext(ctx, `
+        SELECT 1 FROM orders
+        WHERE created_at >= $1 AND created_at < $2
+        FOR UPDATE
+    `, start, end); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE orders IN SHARE MODE"); err != nil {
+		return uuid.Nil, err
+	}
+
+	var openOrders int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM orders
+        WHERE created_at >= $1 AND created_at < $2 AND status NOT IN ($3, $4)
+    `, start, end, OrderStatusCompleted, OrderStatusCancelled).Scan(&openOrders); err != nil {
+		return uuid.Nil, err
+	}
+	if openOrders > 0 {
+		return uuid.Nil, fmt.Errorf("open_orders: %d orders must be completed or cancelled first", openOrders)
+	}
+
+	report, err := buildDayReport(ctx, tx, start, end)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var reportID uuid.UUID
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO z_reports (business_date, period_start, period_end, order_count, cancelled_count,
+            gross_sales, discount_total, tax_total, service_charge_total, net_sales,
+            refund_count, refund_total, tip_total, void_count, void_total, comp_count, comp_total, closed_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+        RETURNING id
+    `, date, start, end, report.OrderCount, report.CancelledCount,
+		report.GrossSales, report.DiscountTotal, report.TaxTotal, report.ServiceChargeTotal, report.NetSales,
+		report.RefundCount, report.RefundTotal, report.TipTotal, report.VoidCount, report.VoidTotal,
+		report.CompCount, report.CompTotal, closedBy).Scan(&reportID); err != nil {
+		return uuid.Nil, err
+	}
+
+	for _, total := range report.Payments {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO z_report_payments (z_report_id, payment_method, payment_count, amount, tip_amount, refund_amount)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, reportID, total.PaymentMethod, total.PaymentCount, total.Amount, total.TipAmount, total.RefundAmount); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	for _, total := range report.Taxes {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO z_report_taxes (z_report_id, tax_name, rate, is_inclusive, taxable_amount, tax_amount)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, reportID, total.TaxName, total.Rate, total.IsInclusive, total.TaxableAmount, total.TaxAmount); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	for _, total := range report.Discounts {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO z_report_discounts (z_report_id, promotion_name, order_count, amount)
+            VALUES ($1, $2, $3, $4)
+        `, reportID, total.PromotionName, total.OrderCount, total.Amount); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	return reportID, nil
+}
+
+const zReportSelect = `
+        SELECT z.id, z.business_date::text, z.period_start, z.period_end, z.order_count, z.cancelled_count,
+               z.gross_sales, z.discount_total, z.tax_total, z.service_charge_total, z.net_sales,
+               z.refund_count, z.refund_total, z.tip_total, z.void_count, z.void_total, z.comp_count, z.comp_total,
+               z.closed_by, z.created_at,
+               u.username, u.first_name, u.last_name
+        FROM z_reports z
+        LEFT JOIN users u ON z.closed_by = u.id
+`
+
+func scanZReport(row rowScanner) (models.DayReport, error) {
+	report := models.DayReport{ReportType: DayReportZ}
+	var username, firstName, lastName sql.NullString
+	err := row.Scan(&report.ID, &report.BusinessDate, &report.PeriodStart, &report.PeriodEnd, &report.OrderCount, &report.CancelledCount,
+		&report.GrossSales, &report.DiscountTotal, &report.TaxTotal, &report.ServiceChargeTotal, &report.NetSales,
+		&report.RefundCount, &report.RefundTotal, &report.TipTotal, &report.VoidCount, &report.VoidTotal, &report.CompCount, &report.CompTotal,
+		&report.ClosedBy, &report.ClosedAt,
+		&username, &firstName, &lastName)
+	if err != nil {
+		return report, err
+	}
+	if username.Valid {
+		report.ClosedByUser = &models.User{
+			Username:  username.String,
+			FirstName: firstName.String,
+			LastName:  lastName.String,
+		}
+	}
+	return report, nil
+}
+
+// GetZReport returns a closed business day with its payment, tax and discount totals
+func (r *PostgresBusinessDayRepository) GetZReport(ctx context.Context, reportID uuid.UUID) (*models.DayReport, error) {
+	report, err := scanZReport(r.db.QueryRowContext(ctx, zReportSelect+" WHERE z.id = $1", reportID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("z_report_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paymentRows, err := r.db.QueryContext(ctx, `
+        SELECT payment_method, payment_count, amount, tip_amount, refund_amount
+        FROM z_report_payments
+        WHERE z_report_id = $1
+        ORDER BY payment_method
+    `, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer paymentRows.Close()
+
+	report.Payments = []models.DayPaymentTotal{}
+	for paymentRows.Next() {
+		var total models.DayPaymentTotal
+		if err := paymentRows.Scan(&total.PaymentMethod, &total.PaymentCount, &total.Amount, &total.TipAmount, &total.RefundAmount); err != nil {
+			return nil, err
+		}
+		total.NetAmount = roundMoney(total.Amount + total.TipAmount - total.RefundAmount)
+		report.Payments = append(report.Payments, total)
+	}
+	if err := paymentRows.Err(); err != nil {
+		return nil, err
+	}
+
+	taxRows, err := r.db.QueryContext(ctx, `
+        SELECT tax_name, rate, is_inclusive, taxable_amount, tax_amount
+        FROM z_report_taxes
+        WHERE z_report_id = $1
+        ORDER BY tax_name, rate
+    `, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer taxRows.Close()
+
+	report.Taxes = []models.DayTaxTotal{}
+	for taxRows.Next() {
+		var total models.DayTaxTotal
+		if err := taxRows.Scan(&total.TaxName, &total.Rate, &total.IsInclusive, &total.TaxableAmount, &total.TaxAmount); err != nil {
+			return nil, err
+		}
+		report.Taxes = append(report.Taxes, total)
+	}
+	if err := taxRows.Err(); err != nil {
+		return nil, err
+	}
+
+	discountRows, err := r.db.QueryContext(ctx, `
+        SELECT promotion_name, order_count, amount
+        FROM z_report_discounts
+        WHERE z_report_id = $1
+        ORDER BY amount DESC
+    `, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer discountRows.Close()
+
+	report.Discounts = []models.DayDiscountTotal{}
+	for discountRows.Next() {
+		var total models.DayDiscountTotal
+		if err := discountRows.Scan(&total.PromotionName, &total.OrderCount, &total.Amount); err != nil {
+			return nil, err
+		}
+		report.Discounts = append(report.Discounts, total)
+	}
+	if err := discountRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// ListZReports returns closed business days, newest first, without their breakdowns
+func (r *PostgresBusinessDayRepository) ListZReports(ctx context.Context, limit, offset int) ([]models.DayReport, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM z_reports").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, zReportSelect+" ORDER BY z.business_date DESC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reports := []models.DayReport{}
+	for rows.Next() {
+		report, err := scanZReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, total, rows.Err()
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// itemFiredAt leaves fired_at empty for an order item insert whose is_held ($9) is set
+const itemFiredAt = `CASE WHEN $9 THEN NULL ELSE CURRENT_TIMESTAMP END`
+
+// itemCourse returns the course of a requested item; items without one go with the first course
+func itemCourse(item models.CreateOrderItem) int {
+	if item.Course < 1 {
+		return 1
+	}
+	return item.Course
+}
+
+// FireCourse releases the held items of a course to the kitchen and returns how many were fired
+func (r *PostgresOrderRepository) FireCourse(ctx context.Context, orderID uuid.UUID, course int, firedBy uuid.UUID) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	status, err := lockKitchenOrder(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkBusinessDayOpen(ctx, tx, orderID); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        UPDATE order_items
+        SET is_held = false, fired_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE order_id = $1 AND course = $2 AND is_held = true
+        RETURNING id, station_id
+    `, orderID, course)
+	if err != nil {
+		return 0, err
+	}
+	var fired []uuid.UUID
+	stations := map[string]bool{}
+	unrouted := false // an item without a station, which any station may have to pick up
+	for rows.Next() {
+		var id uuid.UUID
+		var stationID uuid.NullUUID
+		if err := rows.Scan(&id, &stationID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		fired = append(fired, id)
+		if stationID.Valid {
+			stations[stationID.UUID.String()] = true
+		} else {
+			unrouted = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(fired) == 0 {
+		return 0, fmt.Errorf("course_not_held: no held items in course %d", course)
+	}
+
+	// A ready order has open kitchen work again
+	changes, err := rollUpOrderStatus(ctx, tx, orderID, status, firedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Events without stations reach every station, so the unrouted items are not missed
+	routeTo := stationList(stations)
+	if unrouted {
+		routeTo = nil
+	}
+	events.Publish(events.CourseFired, orderID, routeTo, map[string]interface{}{
+		"course":   course,
+		"item_ids": fired,
+		"fired_by": firedBy,
+	})
+	publishStatusChanges(orderID, changes, firedBy)
+
+	return len(fired), nil
+}
+
+// CourseTimings summarises the fired courses among items: when each was fired, how long
+// it has been waiting and how many of its items the kitchen still has to finish
+func CourseTimings(items []models.OrderItem, now time.Time) []models.CourseTiming {
+	index := map[int]int{}
+	timings := []models.CourseTiming{}
+	for _, item := range items {
+		if item.IsHeld || item.FiredAt == nil {
+			continue
+		}
+		i, ok := index[item.Course]
+		if !ok {
+			i = len(timings)
+			index[item.Course] = i
+			timings = append(timings, models.CourseTiming{Course: item.Course, FiredAt: *item.FiredAt})
+		}
+		if item.FiredAt.Before(timings[i].FiredAt) {
+			timings[i].FiredAt = *item.FiredAt
+		}
+		if item.Status == ItemStatusPending || item.Status == ItemStatusPreparing {
+			timings[i].OpenItems++
+		}
+	}
+
+	for i := range timings {
+		timings[i].WaitingSeconds = int(now.Sub(timings[i].FiredAt).Seconds())
+	}
+	sort.Slice(timings, func(a, b int) bool { return timings[a].Course < timings[b].Course })
+	return timings
+}
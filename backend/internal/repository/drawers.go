@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Drawer session statuses
+const (
+	DrawerOpen     = "open"
+	DrawerClosed   = "closed"
+	DrawerReviewed = "reviewed"
+)
+
+// Drawer movement types
+const (
+	DrawerPayIn  = "pay_in"
+	DrawerPayOut = "pay_out"
+	DrawerNoSale = "no_sale"
+)
+
+// DefaultDrawerName is used when a session is opened without naming a drawer
+const DefaultDrawerName = "Main"
+
+// DrawerRepository defines cash drawer sessions and their reconciliation
+type DrawerRepository interface {
+	OpenSession(ctx context.Context, drawerName string, openingFloat float64, openedBy uuid.UUID) (uuid.UUID, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*models.DrawerSession, error)
+	CurrentSession(ctx context.Context, userID uuid.UUID) (*models.DrawerSession, error)
+	ListSessions(ctx context.Context, status string, limit, offset int) ([]models.DrawerSession, int, error)
+	RecordMovement(ctx context.Context, sessionID uuid.UUID, movementType string, amount float64, reason *string, createdBy uuid.UUID) (uuid.UUID, error)
+	CloseSession(ctx context.Context, sessionID uuid.UUID, countedAmount float64, notes *string, closedBy uuid.UUID) error
+	ReviewSession(ctx context.Context, sessionID uuid.UUID, notes *string, reviewedBy uuid.UUID) error
+	SessionReport(ctx context.Context, sessionID uuid.UUID) (*models.DrawerReport, error)
+}
+
+// PostgresDrawerRepository is an implementation of DrawerRepository using *sql.DB
+type PostgresDrawerRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDrawerRepository(db *sql.DB) *PostgresDrawerRepository {
+	return &PostgresDrawerRepository{db: db}
+}
+
+// ActiveDrawerSession returns the open drawer session cash taken by userID goes into: their
+// own session, or else the most recently opened one. It is nil when no drawer is open.
+func ActiveDrawerSession(ctx context.Context, q rowQuerier, userID uuid.UUID) (*uuid.UUID, error) {
+	var sessionID uuid.UUID
+	err := q.QueryRowContext(ctx, `
+        SELECT id FROM drawer_sessions
+        WHERE status = 'open'
+        ORDER BY (opened_by = $1) DESC, opened_at DESC
+        LIMIT 1
+    `, userID).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sessionID, nil
+}
+
+const drawerSessionSelect = `
+        SELECT ds.id, ds.drawer_name, ds.status, ds.opening_float, ds.opened_by, ds.opened_at,
+               ds.counted_amount, ds.expected_amount, ds.over_short, ds.close_notes, ds.closed_by, ds.closed_at,
+               ds.review_notes, ds.reviewed_by, ds.reviewed_at,
+               u.username, u.first_name, u.last_name
+        FROM drawer_sessions ds
+        LEFT JOIN users u ON ds.opened_by = u.id
+`
+
+func scanDrawerSession(row rowScanner) (models.DrawerSession, error) {
+	var session models.DrawerSession
+	var username, firstName, lastName sql.NullString
+	err := row.Scan(&session.ID, &session.DrawerName, &session.Status, &session.OpeningFloat, &session.OpenedBy, &session.OpenedAt,
+		&session.CountedAmount, &session.ExpectedAmount, &session.OverShort, &session.CloseNotes, &session.ClosedBy, &session.ClosedAt,
+		&session.ReviewNotes, &session.ReviewedBy, &session.ReviewedAt,
+		&username, &firstName, &lastName)
+	if err != nil {
+		return session, err
+	}
+	if username.Valid {
+		session.OpenedByUser = &models.User{
+			Username:  username.String,
+			FirstName: firstName.String,
+			LastName:  lastName.String,
+		}
+	}
+	return session, nil
+}
+
+// OpenSession starts a session on a drawer that is not already open
+func (r *PostgresDrawerRepository) OpenSession(ctx context.Context, drawerName string, openingFloat float64, openedBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	var userOpen int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM drawer_sessions WHERE status = 'open' AND opened_by = $1
+    `, openedBy).Scan(&userOpen); err != nil {
+		return uuid.Nil, err
+	}
+	if userOpen > 0 {
+		return uuid.Nil, fmt.Errorf("session_already_open: close your current drawer first")
+	}
+
+	var sessionID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO drawer_sessions (drawer_name, opening_float, opened_by)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `, drawerName, openingFloat, openedBy).Scan(&sessionID)
+	if err != nil {
+		// idx_drawer_sessions_open_drawer allows one open session per drawer
+		if strings.Contains(err.Error(), "idx_drawer_sessions_open_drawer") {
+			return uuid.Nil, fmt.Errorf("drawer_in_use: drawer %s is already open", drawerName)
+		}
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	return sessionID, nil
+}
+
+// GetSession returns a drawer session with its pay-ins, pay-outs and no-sale opens
+func (r *PostgresDrawerRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.DrawerSession, error) {
+	session, err := scanDrawerSession(r.db.QueryRowContext(ctx, drawerSessionSelect+" WHERE ds.id = $1", sessionID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, session_id, movement_type, amount, reason, created_by, created_at
+        FROM drawer_movements
+        WHERE session_id = $1
+        ORDER BY created_at
+    `, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	session.Movements = []models.DrawerMovement{}
+	for rows.Next() {
+		var movement models.DrawerMovement
+		if err := rows.Scan(&movement.ID, &movement.SessionID, &movement.MovementType, &movement.Amount,
+			&movement.Reason, &movement.CreatedBy, &movement.CreatedAt); err != nil {
+			return nil, err
+		}
+		session.Movements = append(session.Movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CurrentSession returns the session userID has open
+func (r *PostgresDrawerRepository) CurrentSession(ctx context.Context, userID uuid.UUID) (*models.DrawerSession, error) {
+	var sessionID uuid.UUID
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id FROM drawer_sessions WHERE status = 'open' AND opened_by = $1
+    `, userID).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session_not_found: no open drawer")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetSession(ctx, sessionID)
+}
+
+// ListSessions returns drawer sessions, newest first, and the total count
+func (r *PostgresDrawerRepository) ListSessions(ctx context.Context, status string, limit, offset int) ([]models.DrawerSession, int, error) {
+	where := ""
+	var args []interface{}
+	if status != "" {
+		where = " WHERE ds.status = $1"
+		args = append(args, status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM drawer_sessions ds"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := drawerSessionSelect + where + fmt.Sprintf(" ORDER BY ds.opened_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []models.DrawerSession{}
+	for rows.Next() {
+		session, err := scanDrawerSession(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, total, rows.Err()
+}
+
+// lockOpenSession locks a drawer session and checks it is still open
+func lockOpenSession(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID) error {
+	var status string
+	err := tx.QueryRowContext(ctx, "SELECT status FROM drawer_sessions WHERE id = $1 FOR UPDATE", sessionID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("session_not_found: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	if status != DrawerOpen {
+		return fmt.Errorf("session_not_open: drawer session is %s", status)
+	}
+	return nil
+}
+
+// RecordMovement logs a pay-in, pay-out or no-sale open on an open session
+func (r *PostgresDrawerRepository) RecordMovement(ctx context.Context, sessionID uuid.UUID, movementType string, amount float64, reason *string, createdBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenSession(ctx, tx, sessionID); err != nil {
+		return uuid.Nil, err
+	}
+
+	var movementID uuid.UUID
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO drawer_movements (session_id, movement_type, amount, reason, created_by)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `, sessionID, movementType, amount, reason, createdBy).Scan(&movementID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	return movementID, nil
+}
+
+// drawerTotals adds up the cash a session should hold
+func drawerTotals(ctx context.Context, q rowQuerier, sessionID uuid.UUID) (*models.DrawerReport, error) {
+	report := models.DrawerReport{}
+	err := q.QueryRowContext(ctx, `
+        SELECT ds.opening_float,
+               COALESCE((SELECT SUM(p.amount + p.rounding_amount) FROM payments p
+                         WHERE p.drawer_session_id = ds.id AND p.payment_method = 'cash' AND p.status IN ('completed', 'refunded')), 0),
+               COALESCE((SELECT SUM(p.tip_amount) FROM payments p
+                         WHERE p.drawer_session_id = ds.id AND p.payment_method = 'cash' AND p.status IN ('completed', 'refunded')), 0),
+               COALESCE((SELECT SUM(p.amount) FROM payments p
+                         WHERE p.drawer_session_id = ds.id AND p.payment_method <> 'cash' AND p.status IN ('completed', 'refunded')), 0),
+               (SELECT COUNT(*) FROM payments p WHERE p.drawer_session_id = ds.id AND p.status IN ('completed', 'refunded')),
+               COALESCE((SELECT SUM(rf.amount) FROM refunds rf JOIN payments p ON rf.payment_id = p.id
+                         WHERE rf.drawer_session_id = ds.id AND rf.status = 'completed' AND p.payment_method = 'cash'), 0),
+               COALESCE((SELECT SUM(amount) FROM drawer_movements WHERE session_id = ds.id AND movement_type = 'pay_in'), 0),
+               COALESCE((SELECT SUM(amount) FROM drawer_movements WHERE session_id = ds.id AND movement_type = 'pay_out'), 0),
+               (SELECT COUNT(*) FROM drawer_movements WHERE session_id = ds.id AND movement_type = 'no_sale')
+        FROM drawer_sessions ds
+        WHERE ds.id = $1
+    `, sessionID).Scan(&report.OpeningFloat, &report.CashSales, &report.CashTips, &report.CardSales, &report.PaymentCount,
+		&report.CashRefunds, &report.PayIns, &report.PayOuts, &report.NoSaleCount)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.ExpectedAmount = roundMoney(report.OpeningFloat + report.CashSales + report.CashTips -
+		report.CashRefunds + report.PayIns - report.PayOuts)
+	return &report, nil
+}
+
+// CloseSession records the blind count and fixes the expected amount and over/short
+func (r *PostgresDrawerRepository) CloseSession(ctx context.Context, sessionID uuid.UUID, countedAmount float64, notes *string, closedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenSession(ctx, tx, sessionID); err != nil {
+		return err
+	}
+
+	totals, err := drawerTotals(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE drawer_sessions
+        SET status = 'closed', counted_amount = $1, expected_amount = $2, over_short = $3,
+            close_notes = $4, closed_by = $5, closed_at = CURRENT_TIMESTAMP
+        WHERE id = $6
+    `, countedAmount, totals.ExpectedAmount, roundMoney(countedAmount-totals.ExpectedAmount), notes, closedBy, sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReviewSession signs off a closed session
+func (r *PostgresDrawerRepository) ReviewSession(ctx context.Context, sessionID uuid.UUID, notes *string, reviewedBy uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE drawer_sessions
+        SET status = 'reviewed', review_notes = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP
+        WHERE id = $3 AND status = 'closed'
+    `, notes, reviewedBy, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		var status string
+		err := r.db.QueryRowContext(ctx, "SELECT status FROM drawer_sessions WHERE id = $1", sessionID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("session_not_found: %w", err)
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("session_not_closed: drawer session is %s", status)
+	}
+	return nil
+}
+
+// SessionReport reconciles a session: what it should hold against what was counted. For an
+// open session the expected amount is a running figure.
+func (r *PostgresDrawerRepository) SessionReport(ctx context.Context, sessionID uuid.UUID) (*models.DrawerReport, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := drawerTotals(ctx, r.db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	report.Session = *session
+	report.CountedAmount = session.CountedAmount
+	if session.ExpectedAmount != nil {
+		// A closed session reports the amount fixed when it was counted
+		report.ExpectedAmount = *session.ExpectedAmount
+	}
+	report.OverShort = session.OverShort
+	return report, nil
+}
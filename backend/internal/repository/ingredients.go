@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Ingredient movement types
+const (
+	IngredientInitial     = "initial"
+	IngredientPurchase    = "purchase"
+	IngredientConsumption = "consumption"
+	IngredientWaste       = "waste"
+	IngredientCount       = "count"
+	IngredientAdjustment  = "adjustment"
+)
+
+// IngredientRepository defines ingredient stock, recipe and usage operations
+type IngredientRepository interface {
+	ListIngredients(ctx context.Context, lowStockOnly bool) ([]models.Ingredient, error)
+	GetIngredient(ctx context.Context, id uuid.UUID) (*models.Ingredient, error)
+	CreateIngredient(ctx context.Context, req models.IngredientRequest, createdBy uuid.UUID) (uuid.UUID, error)
+	UpdateIngredient(ctx context.Context, id uuid.UUID, req models.IngredientRequest) error
+	DeleteIngredient(ctx context.Context, id uuid.UUID) error
+	RecordIngredientStock(ctx context.Context, id uuid.UUID, req models.IngredientStockRequest, createdBy uuid.UUID) error
+	ListIngredientMovements(ctx context.Context, id uuid.UUID, limit, offset int) ([]models.IngredientMovement, int, error)
+	GetProductRecipe(ctx context.Context, productID uuid.UUID) ([]models.RecipeItem, error)
+	SetProductRecipe(ctx context.Context, productID uuid.UUID, lines []models.RecipeLine) error
+	GetModifierRecipe(ctx context.Context, modifierID uuid.UUID) ([]models.RecipeItem, error)
+	SetModifierRecipe(ctx context.Context, modifierID uuid.UUID, lines []models.RecipeLine) error
+	IngredientUsage(ctx context.Context, from, to time.Time) ([]models.IngredientUsage, error)
+}
+
+// PostgresIngredientRepository is an implementation of IngredientRepository using *sql.DB
+type PostgresIngredientRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresIngredientRepository(db *sql.DB) *PostgresIngredientRepository {
+	return &PostgresIngredientRepository{db: db}
+}
+
+const ingredientSelect = `
+        SELECT id, name, unit, current_stock, minimum_stock, unit_cost, is_active, created_at, updated_at
+        FROM ingredients
+`
+
+func scanIngredient(row rowScanner) (models.Ingredient, error) {
+	var ingredient models.Ingredient
+	var minimumStock sql.NullFloat64
+	if err := row.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Unit, &ingredient.CurrentStock, &minimumStock,
+		&ingredient.UnitCost, &ingredient.IsActive, &ingredient.CreatedAt, &ingredient.UpdatedAt); err != nil {
+		return ingredient, err
+	}
+	ingredient.MinimumStock = minimumStock.Float64
+	ingredient.IsLowStock = ingredient.CurrentStock <= ingredient.MinimumStock
+	return ingredient, nil
+}
+
+func (r *PostgresIngredientRepository) ListIngredients(ctx context.Context, lowStockOnly bool) ([]models.Ingredient, error) {
+	query := ingredientSelect
+	if lowStockOnly {
+		query += " WHERE current_stock <= COALESCE(minimum_stock, 0)"
+	}
+	query += " ORDER BY name"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := []models.Ingredient{}
+	for rows.Next() {
+		ingredient, err := scanIngredient(rows)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, rows.Err()
+}
+
+func (r *PostgresIngredientRepository) GetIngredient(ctx context.Context, id uuid.UUID) (*models.Ingredient, error) {
+	ingredient, err := scanIngredient(r.db.QueryRowContext(ctx, ingredientSelect+" WHERE id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// CreateIngredient adds an ingredient and records its opening stock in the ledger
+func (r *PostgresIngredientRepository) CreateIngredient(ctx context.Context, req models.IngredientRequest, createdBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	minimumStock := 0.0
+	if req.MinimumStock != nil {
+		minimumStock = *req.MinimumStock
+	}
+
+	id := uuid.New()
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO ingredients (id, name, unit, current_stock, minimum_stock, unit_cost)
+        VALUES ($1, $2, $3, 0, $4, $5)
+    `, id, *req.Name, *req.Unit, minimumStock, req.UnitCost); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return uuid.Nil, fmt.Errorf("ingredient_exists: %w", err)
+		}
+		return uuid.Nil, err
+	}
+
+	openingStock := 0.0
+	if req.CurrentStock != nil {
+		openingStock = *req.CurrentStock
+	}
+	notes := "Opening stock"
+	if err := moveIngredient(ctx, tx, id, openingStock, IngredientInitial, nil, nil, &notes, &createdBy, nil); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+// UpdateIngredient changes ingredient details; stock changes go through RecordIngredientStock
+func (r *PostgresIngredientRepository) UpdateIngredient(ctx context.Context, id uuid.UUID, req models.IngredientRequest) error {
+	updates := []string{}
+	args := []interface{}{}
+	argCount := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argCount))
+		args = append(args, *req.Name)
+		argCount++
+	}
+	if req.Unit != nil {
+		updates = append(updates, fmt.Sprintf("unit = $%d", argCount))
+		args = append(args, *req.Unit)
+		argCount++
+	}
+	if req.MinimumStock != nil {
+		updates = append(updates, fmt.Sprintf("minimum_stock = $%d", argCount))
+		args = append(args, *req.MinimumStock)
+		argCount++
+	}
+	if req.UnitCost != nil {
+		updates = append(updates, fmt.Sprintf("unit_cost = $%d", argCount))
+		args = append(args, *req.UnitCost)
+		argCount++
+	}
+	if req.IsActive != nil {
+		updates = append(updates, fmt.Sprintf("is_active = $%d", argCount))
+		args = append(args, *req.IsActive)
+		argCount++
+	}
+
+	if len(updates) == 0 {
+		return fmt.Errorf("no_fields: nothing to update")
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE ingredients SET %s, updated_at = CURRENT_TIMESTAMP WHERE id = $%d", strings.Join(updates, ", "), argCount)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return fmt.Errorf("ingredient_exists: %w", err)
+		}
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteIngredient removes an ingredient that no recipe uses
+func (r *PostgresIngredientRepository) DeleteIngredient(ctx context.Context, id uuid.UUID) error {
+	var recipeCount int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM recipe_items WHERE ingredient_id = $1", id).Scan(&recipeCount); err != nil {
+		return err
+	}
+	if recipeCount > 0 {
+		return fmt.Errorf("ingredient_in_use: used by %d recipe line(s)", recipeCount)
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM ingredients WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordIngredientStock applies a purchase, waste, count or manual adjustment
+func (r *PostgresIngredientRepository) RecordIngredientStock(ctx context.Context, id uuid.UUID, req models.IngredientStockRequest, createdBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentStock float64
+	err = tx.QueryRowContext(ctx, "SELECT current_stock FROM ingredients WHERE id = $1 FOR UPDATE", id).Scan(&currentStock)
+	if err != nil {
+		return err
+	}
+
+	var change float64
+	switch req.MovementType {
+	case IngredientPurchase:
+		change = req.Quantity
+		if req.UnitCost != nil {
+			if _, err := tx.ExecContext(ctx, "UPDATE ingredients SET unit_cost = $1 WHERE id = $2", *req.UnitCost, id); err != nil {
+				return err
+			}
+		}
+	case IngredientWaste:
+		change = -req.Quantity
+	case IngredientCount:
+		// the change recorded for a count is the variance against book stock
+		change = req.Quantity - currentStock
+	case IngredientAdjustment:
+		change = req.Quantity
+	default:
+		return fmt.Errorf("invalid_movement_type: %s", req.MovementType)
+	}
+
+	if err := moveIngredient(ctx, tx, id, change, req.MovementType, nil, req.Reason, req.Notes, &createdBy, req.UnitCost); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresIngredientRepository) ListIngredientMovements(ctx context.Context, id uuid.UUID, limit, offset int) ([]models.IngredientMovement, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ingredient_movements WHERE ingredient_id = $1", id).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, ingredient_id, order_id, movement_type, quantity_change, stock_after,
+               unit_cost, reason, notes, created_by, created_at
+        FROM ingredient_movements
+        WHERE ingredient_id = $1
+        ORDER BY created_at DESC
+        LIMIT $2 OFFSET $3
+    `, id, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.IngredientMovement{}
+	for rows.Next() {
+		var movement models.IngredientMovement
+		if err := rows.Scan(&movement.ID, &movement.IngredientID, &movement.OrderID, &movement.MovementType,
+			&movement.QuantityChange, &movement.StockAfter, &movement.UnitCost, &movement.Reason, &movement.Notes,
+			&movement.CreatedBy, &movement.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+func (r *PostgresIngredientRepository) GetProductRecipe(ctx context.Context, productID uuid.UUID) ([]models.RecipeItem, error) {
+	return r.getRecipe(ctx, "product_id", productID)
+}
+
+func (r *PostgresIngredientRepository) SetProductRecipe(ctx context.Context, productID uuid.UUID, lines []models.RecipeLine) error {
+	return r.setRecipe(ctx, "products", "product_id", productID, lines)
+}
+
+func (r *PostgresIngredientRepository) GetModifierRecipe(ctx context.Context, modifierID uuid.UUID) ([]models.RecipeItem, error) {
+	return r.getRecipe(ctx, "modifier_id", modifierID)
+}
+
+func (r *PostgresIngredientRepository) SetModifierRecipe(ctx context.Context, modifierID uuid.UUID, lines []models.RecipeLine) error {
+	return r.setRecipe(ctx, "modifiers", "modifier_id", modifierID, lines)
+}
+
+func (r *PostgresIngredientRepository) getRecipe(ctx context.Context, ownerColumn string, ownerID uuid.UUID) ([]models.RecipeItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT ri.id, ri.product_id, ri.modifier_id, ri.ingredient_id, g.name, g.unit, ri.quantity, g.unit_cost
+        FROM recipe_items ri
+        JOIN ingredients g ON ri.ingredient_id = g.id
+        WHERE ri.`+ownerColumn+` = $1
+        ORDER BY g.name
+    `, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.RecipeItem{}
+	for rows.Next() {
+		var item models.RecipeItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.ModifierID, &item.IngredientID, &item.IngredientName,
+			&item.Unit, &item.Quantity, &item.UnitCost); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// setRecipe replaces every recipe line of a product or modifier
+func (r *PostgresIngredientRepository) setRecipe(ctx context.Context, ownerTable, ownerColumn string, ownerID uuid.UUID, lines []models.RecipeLine) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM "+ownerTable+" WHERE id = $1)", ownerID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recipe_items WHERE "+ownerColumn+" = $1", ownerID); err != nil {
+		return err
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, line := range lines {
+		if seen[line.IngredientID] {
+			return fmt.Errorf("invalid_recipe: ingredient %s listed more than once", line.IngredientID)
+		}
+		seen[line.IngredientID] = true
+
+		var ingredientExists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM ingredients WHERE id = $1)", line.IngredientID).Scan(&ingredientExists); err != nil {
+			return err
+		}
+		if !ingredientExists {
+			return fmt.Errorf("invalid_recipe: ingredient %s not found", line.IngredientID)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO recipe_items ("+ownerColumn+", ingredient_id, quantity) VALUES ($1, $2, $3)",
+			ownerID, line.IngredientID, line.Quantity,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IngredientUsage compares what completed orders should have used with what waste,
+// adjustments and stock counts say was actually used between from and to
+func (r *PostgresIngredientRepository) IngredientUsage(ctx context.Context, from, to time.Time) ([]models.IngredientUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT g.id, g.name, g.unit, COALESCE(g.unit_cost, 0),
+               COALESCE(SUM(-m.quantity_change) FILTER (WHERE m.movement_type = 'consumption'), 0),
+               COALESCE(SUM(-m.quantity_change) FILTER (WHERE m.movement_type = 'waste'), 0),
+               COALESCE(SUM(m.quantity_change) FILTER (WHERE m.movement_type = 'adjustment'), 0),
+               COALESCE(SUM(m.quantity_change) FILTER (WHERE m.movement_type = 'count'), 0)
+        FROM ingredients g
+        LEFT JOIN ingredient_movements m ON m.ingredient_id = g.id AND m.created_at >= $1 AND m.created_at < $2
+        GROUP BY g.id, g.name, g.unit, g.unit_cost
+        ORDER BY g.name
+    `, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []models.IngredientUsage{}
+	for rows.Next() {
+		var u models.IngredientUsage
+		if err := rows.Scan(&u.IngredientID, &u.Name, &u.Unit, &u.UnitCost,
+			&u.TheoreticalUsage, &u.RecordedWaste, &u.Adjustments, &u.CountVariance); err != nil {
+			return nil, err
+		}
+
+		u.ActualUsage = u.TheoreticalUsage + u.RecordedWaste - u.Adjustments - u.CountVariance
+		variance := u.ActualUsage - u.TheoreticalUsage
+		if u.TheoreticalUsage > 0 {
+			u.VariancePercent = math.Round(variance/u.TheoreticalUsage*10000) / 100
+		}
+		u.VarianceCost = roundMoney(variance * u.UnitCost)
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// moveIngredient changes the book stock of an ingredient and records the movement.
+// Book stock may go negative; the next count brings it back in line.
+func moveIngredient(ctx context.Context, tx *sql.Tx, ingredientID uuid.UUID, change float64, movementType string, orderID *uuid.UUID, reason, notes *string, createdBy *uuid.UUID, unitCost *float64) error {
+	var stockAfter float64
+	var currentCost sql.NullFloat64
+	if err := tx.QueryRowContext(ctx, `
+        UPDATE ingredients
+        SET current_stock = current_stock + $1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $2
+        RETURNING current_stock, unit_cost
+    `, change, ingredientID).Scan(&stockAfter, &currentCost); err != nil {
+		return err
+	}
+
+	if unitCost == nil && currentCost.Valid {
+		unitCost = &currentCost.Float64
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO ingredient_movements (ingredient_id, order_id, movement_type, quantity_change, stock_after, unit_cost, reason, notes, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `, ingredientID, orderID, movementType, change, stockAfter, unitCost, reason, notes, createdBy)
+	return err
+}
+
+// ConsumeOrderIngredients takes the recipe ingredients of a completed order out of stock.
+// It runs inside the transaction that completes the order, wherever that happens.
+// Voided items were never made and are skipped; comped items were. An order reopened
+// after a refund and completed again was already taken out of stock the first time.
+func ConsumeOrderIngredients(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, changedBy uuid.UUID) error {
+	var alreadyConsumed bool
+	if err := tx.QueryRowContext(ctx, `
+        SELECT EXISTS(SELECT 1 FROM ingredient_movements WHERE order_id = $1 AND movement_type = 'consumption')
+    `, orderID).Scan(&alreadyConsumed); err != nil {
+		return err
+	}
+	if alreadyConsumed {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT ingredient_id, SUM(quantity)
+        FROM (
+            SELECT r.ingredient_id, r.quantity * oi.quantity AS quantity
+            FROM order_items oi
+            JOIN recipe_items r ON r.product_id = oi.product_id
+            WHERE oi.order_id = $1 AND oi.adjustment IS DISTINCT FROM 'void'
+            UNION ALL
+            SELECT r.ingredient_id, r.quantity * oi.quantity
+            FROM order_items oi
+            JOIN order_item_modifiers m ON m.order_item_id = oi.id
+            JOIN recipe_items r ON r.modifier_id = m.modifier_id
+            WHERE oi.order_id = $1 AND oi.adjustment IS DISTINCT FROM 'void'
+        ) usage
+        GROUP BY ingredient_id
+    `, orderID)
+	if err != nil {
+		return err
+	}
+
+	type consumption struct {
+		ingredientID uuid.UUID
+		quantity     float64
+	}
+	var consumed []consumption
+	for rows.Next() {
+		var c consumption
+		if err := rows.Scan(&c.ingredientID, &c.quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		consumed = append(consumed, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range consumed {
+		if err := moveIngredient(ctx, tx, c.ingredientID, -c.quantity, IngredientConsumption, &orderID, nil, nil, &changedBy, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
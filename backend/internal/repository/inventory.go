@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Inventory movement types
+const (
+	MovementInitial    = "initial"
+	MovementRestock    = "restock"
+	MovementAdjustment = "adjustment"
+	MovementSale       = "sale"
+	MovementReturn     = "return"
+)
+
+// stockAdjustmentReasons lists the reason codes accepted for manual stock adjustments
+var stockAdjustmentReasons = []string{
+	"count_correction",
+	"waste",
+	"damage",
+	"expired",
+	"theft",
+	"staff_meal",
+	"other",
+}
+
+// IsValidStockAdjustmentReason reports whether code is an accepted stock adjustment reason
+func IsValidStockAdjustmentReason(code string) bool {
+	for _, c := range stockAdjustmentReasons {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// StockAdjustmentReasons returns the accepted stock adjustment reasons
+func StockAdjustmentReasons() []string {
+	return append([]string(nil), stockAdjustmentReasons...)
+}
+
+// InventoryRepository defines stock level and movement ledger operations
+type InventoryRepository interface {
+	ListInventory(ctx context.Context, lowStockOnly bool) ([]models.Inventory, error)
+	GetInventory(ctx context.Context, productID uuid.UUID) (*models.Inventory, error)
+	UpsertInventory(ctx context.Context, productID uuid.UUID, req models.UpsertInventoryRequest, changedBy uuid.UUID) error
+	DeleteInventory(ctx context.Context, productID uuid.UUID) error
+	Restock(ctx context.Context, productID uuid.UUID, req models.RestockRequest, changedBy uuid.UUID) error
+	AdjustStock(ctx context.Context, productID uuid.UUID, req models.StockAdjustmentRequest, changedBy uuid.UUID) error
+	ListMovements(ctx context.Context, productID *uuid.UUID, movementType string, limit, offset int) ([]models.InventoryMovement, int, error)
+}
+
+// PostgresInventoryRepository is an implementation of InventoryRepository using *sql.DB
+type PostgresInventoryRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresInventoryRepository(db *sql.DB) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{db: db}
+}
+
+const inventorySelect = `
+        SELECT i.id, i.product_id, i.current_stock, i.minimum_stock, i.maximum_stock, i.unit_cost,
+               i.last_restocked_at, i.auto_disabled, i.created_at, i.updated_at,
+               p.name, p.sku, p.price, p.is_available
+        FROM inventory i
+        JOIN products p ON i.product_id = p.id
+`
+
+func scanInventory(row rowScanner) (models.Inventory, error) {
+	var item models.Inventory
+	var minimumStock, maximumStock sql.NullInt32
+	var product models.Product
+	if err := row.Scan(&item.ID, &item.ProductID, &item.CurrentStock, &minimumStock, &maximumStock, &item.UnitCost,
+		&item.LastRestockedAt, &item.AutoDisabled, &item.CreatedAt, &item.UpdatedAt,
+		&product.Name, &product.SKU, &product.Price, &product.IsAvailable); err != nil {
+		return item, err
+	}
+	item.MinimumStock = int(minimumStock.Int32)
+	item.MaximumStock = int(maximumStock.Int32)
+	item.IsLowStock = item.CurrentStock <= item.MinimumStock
+	product.ID = item.ProductID
+	item.Product = &product
+	return item, nil
+}
+
+func (r *PostgresInventoryRepository) ListInventory(ctx context.Context, lowStockOnly bool) ([]models.Inventory, error) {
+	query := inventorySelect
+	if lowStockOnly {
+		query += " WHERE i.current_stock <= COALESCE(i.minimum_stock, 0)"
+	}
+	query += " ORDER BY p.name"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.Inventory{}
+	for rows.Next() {
+		item, err := scanInventory(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *PostgresInventoryRepository) GetInventory(ctx context.Context, productID uuid.UUID) (*models.Inventory, error) {
+	item, err := scanInventory(r.db.QueryRowContext(ctx, inventorySelect+" WHERE i.product_id = $1", productID))
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpsertInventory starts tracking a product or updates its stock limits and cost.
+// The opening stock is only taken when tracking starts; later changes go through restock or adjust.
+func (r *PostgresInventoryRepository) UpsertInventory(ctx context.Context, productID uuid.UUID, req models.UpsertInventoryRequest, changedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("product_not_found: %w", sql.ErrNoRows)
+	}
+
+	var inventoryID uuid.UUID
+	err = tx.QueryRowContext(ctx, "SELECT id FROM inventory WHERE product_id = $1 FOR UPDATE", productID).Scan(&inventoryID)
+	if err == sql.ErrNoRows {
+		openingStock := 0
+		if req.CurrentStock != nil {
+			openingStock = *req.CurrentStock
+		}
+		minimumStock, maximumStock := 0, 0
+		if req.MinimumStock != nil {
+			minimumStock = *req.MinimumStock
+		}
+		if req.MaximumStock != nil {
+			maximumStock = *req.MaximumStock
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO inventory (product_id, current_stock, minimum_stock, maximum_stock, unit_cost)
+            VALUES ($1, 0, $2, $3, $4)
+        `, productID, minimumStock, maximumStock, req.UnitCost); err != nil {
+			return err
+		}
+
+		notes := "Started tracking stock"
+		if err := moveStock(ctx, tx, productID, openingStock, MovementInitial, nil, nil, &notes, &changedBy, nil); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+
+	updates := []string{}
+	args := []interface{}{}
+	argCount := 1
+	if req.MinimumStock != nil {
+		updates = append(updates, fmt.Sprintf("minimum_stock = $%d", argCount))
+		args = append(args, *req.MinimumStock)
+		argCount++
+	}
+	if req.MaximumStock != nil {
+		updates = append(updates, fmt.Sprintf("maximum_stock = $%d", argCount))
+		args = append(args, *req.MaximumStock)
+		argCount++
+	}
+	if req.UnitCost != nil {
+		updates = append(updates, fmt.Sprintf("unit_cost = $%d", argCount))
+		args = append(args, *req.UnitCost)
+		argCount++
+	}
+	if len(updates) > 0 {
+		args = append(args, inventoryID)
+		query := fmt.Sprintf("UPDATE inventory SET %s, updated_at = CURRENT_TIMESTAMP WHERE id = $%d", strings.Join(updates, ", "), argCount)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteInventory stops tracking stock for a product; its movement history is kept
+func (r *PostgresInventoryRepository) DeleteInventory(ctx context.Context, productID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM inventory WHERE product_id = $1", productID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Restock records a delivery, updating the unit cost when one is given
+func (r *PostgresInventoryRepository) Restock(ctx context.Context, productID uuid.UUID, req models.RestockRequest, changedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE inventory
+        SET last_restocked_at = CURRENT_TIMESTAMP, unit_cost = COALESCE($1, unit_cost)
+        WHERE product_id = $2
+    `, req.UnitCost, productID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return fmt.Errorf("inventory_not_found: %w", sql.ErrNoRows)
+	}
+
+	if err := moveStock(ctx, tx, productID, req.Quantity, MovementRestock, nil, nil, req.Notes, &changedBy, req.UnitCost); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AdjustStock applies a manual correction, either relative or to a counted level
+func (r *PostgresInventoryRepository) AdjustStock(ctx context.Context, productID uuid.UUID, req models.StockAdjustmentRequest, changedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentStock int
+	err = tx.QueryRowContext(ctx, "SELECT current_stock FROM inventory WHERE product_id = $1 FOR UPDATE", productID).Scan(&currentStock)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("inventory_not_found: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	change := 0
+	if req.CountedStock != nil {
+		change = *req.CountedStock - currentStock
+	} else if req.QuantityChange != nil {
+		change = *req.QuantityChange
+	}
+	if change == 0 {
+		return tx.Commit()
+	}
+
+	reason := req.Reason
+	if err := moveStock(ctx, tx, productID, change, MovementAdjustment, nil, &reason, req.Notes, &changedBy, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresInventoryRepository) ListMovements(ctx context.Context, productID *uuid.UUID, movementType string, limit, offset int) ([]models.InventoryMovement, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argCount := 1
+	if productID != nil {
+		where = append(where, fmt.Sprintf("m.product_id = $%d", argCount))
+		args = append(args, *productID)
+		argCount++
+	}
+	if movementType != "" {
+		where = append(where, fmt.Sprintf("m.movement_type = $%d", argCount))
+		args = append(args, movementType)
+		argCount++
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM inventory_movements m "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT m.id, m.product_id, p.name, m.order_id, m.movement_type, m.quantity_change, m.stock_after,
+               m.unit_cost, m.reason, m.notes, m.created_by, m.created_at
+        FROM inventory_movements m
+        JOIN products p ON m.product_id = p.id
+        %s
+        ORDER BY m.created_at DESC
+        LIMIT $%d OFFSET $%d
+    `, whereClause, argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.InventoryMovement{}
+	for rows.Next() {
+		var movement models.InventoryMovement
+		if err := rows.Scan(&movement.ID, &movement.ProductID, &movement.ProductName, &movement.OrderID,
+			&movement.MovementType, &movement.QuantityChange, &movement.StockAfter, &movement.UnitCost,
+			&movement.Reason, &movement.Notes, &movement.CreatedBy, &movement.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// moveStock changes the stock of a tracked product and records the movement in the ledger.
+// Products without an inventory row are not tracked and are left alone. Stock never goes
+// below zero; at zero the product is marked unavailable, and it is made available again
+// once stock returns if it was disabled that way.
+// The movement is costed at unitCost, or the current inventory unit cost when nil.
+func moveStock(ctx context.Context, tx *sql.Tx, productID uuid.UUID, change int, movementType string, orderID *uuid.UUID, reason, notes *string, createdBy *uuid.UUID, unitCost *float64) error {
+	var currentStock int
+	var autoDisabled bool
+	var inventoryCost sql.NullFloat64
+	err := tx.QueryRowContext(ctx, `
+        SELECT current_stock, auto_disabled, unit_cost FROM inventory WHERE product_id = $1 FOR UPDATE
+    `, productID).Scan(&currentStock, &autoDisabled, &inventoryCost)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	newStock := currentStock + change
+	if newStock < 0 {
+		var productName string
+		tx.QueryRowContext(ctx, "SELECT name FROM products WHERE id = $1", productID).Scan(&productName)
+		return fmt.Errorf("insufficient_stock: only %d %s left", currentStock, productName)
+	}
+
+	if unitCost == nil && inventoryCost.Valid {
+		unitCost = &inventoryCost.Float64
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO inventory_movements (product_id, order_id, movement_type, quantity_change, stock_after, unit_cost, reason, notes, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `, productID, orderID, movementType, change, newStock, unitCost, reason, notes, createdBy); err != nil {
+		return err
+	}
+
+	switch {
+	case newStock == 0:
+		// Only flag products we disable ourselves so a manual "86" is not undone by a restock
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE inventory
+            SET current_stock = 0,
+                auto_disabled = auto_disabled OR (SELECT is_available FROM products WHERE id = $1),
+                updated_at = CURRENT_TIMESTAMP
+            WHERE product_id = $1
+        `, productID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET is_available = false WHERE id = $1", productID); err != nil {
+			return err
+		}
+	case autoDisabled:
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE inventory SET current_stock = $1, auto_disabled = false, updated_at = CURRENT_TIMESTAMP
+            WHERE product_id = $2
+        `, newStock, productID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET is_available = true WHERE id = $1", productID); err != nil {
+			return err
+		}
+	default:
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE inventory SET current_stock = $1, updated_at = CURRENT_TIMESTAMP WHERE product_id = $2
+        `, newStock, productID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returnOrderStock puts the items of a cancelled order back into stock
+func returnOrderStock(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, changedBy uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, quantity FROM order_items WHERE order_id = $1", orderID)
+	if err != nil {
+		return err
+	}
+	type line struct {
+		productID uuid.UUID
+		quantity  int
+	}
+	var lines []line
+	for rows.Next() {
+		var l line
+		if err := rows.Scan(&l.productID, &l.quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		lines = append(lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	notes := "Order cancelled"
+	for _, l := range lines {
+		if err := moveStock(ctx, tx, l.productID, l.quantity, MovementReturn, &orderID, nil, &notes, &changedBy, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// priceOrderItem looks up the current product price and validates the chosen modifiers.
+// The returned unit price includes the modifier price deltas.
+func priceOrderItem(ctx context.Context, tx *sql.Tx, item models.CreateOrderItem) (float64, []models.OrderItemModifier, error) {
+	var price float64
+	var categoryID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, "SELECT price, category_id FROM products WHERE id = $1 AND is_available = true", item.ProductID).Scan(&price, &categoryID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, fmt.Errorf("product_not_found: %w", err)
+		}
+		return 0, nil, err
+	}
+
+	modifiers, err := resolveItemModifiers(ctx, tx, item.ProductID, categoryID, item.ModifierIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return modifiedUnitPrice(price, modifiers), modifiers, nil
+}
+
+// modifiedUnitPrice adds the price deltas of the chosen modifiers to a product's price
+func modifiedUnitPrice(price float64, modifiers []models.OrderItemModifier) float64 {
+	for _, modifier := range modifiers {
+		price += modifier.PriceDelta
+	}
+	return roundMoney(price)
+}
+
+// modifierGroupRule is a modifier group offered on a product with its selection limits
+type modifierGroupRule struct {
+	name      string
+	minSelect int
+	maxSelect sql.NullInt32
+}
+
+// modifierChoice is a chosen modifier as it is stored
+type modifierChoice struct {
+	id          uuid.UUID
+	groupID     uuid.UUID
+	name        string
+	priceDelta  float64
+	isAvailable bool
+}
+
+// resolveItemModifiers checks the chosen modifiers against the groups offered on the
+// product (directly or through its category) and their min/max selection rules
+func resolveItemModifiers(ctx context.Context, tx *sql.Tx, productID uuid.UUID, categoryID uuid.NullUUID, modifierIDs []uuid.UUID) ([]models.OrderItemModifier, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT g.id, g.name, g.min_select, g.max_select
+        FROM modifier_groups g
+        WHERE g.is_active = true
+          AND (g.id IN (SELECT modifier_group_id FROM product_modifier_groups WHERE product_id = $1)
+               OR g.id IN (SELECT modifier_group_id FROM category_modifier_groups WHERE category_id = $2))
+    `, productID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[uuid.UUID]modifierGroupRule{}
+	for rows.Next() {
+		var id uuid.UUID
+		var g modifierGroupRule
+		if err := rows.Scan(&id, &g.name, &g.minSelect, &g.maxSelect); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		groups[id] = g
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var choices []modifierChoice
+	if len(modifierIDs) > 0 {
+		ids := make([]string, 0, len(modifierIDs))
+		for _, id := range modifierIDs {
+			ids = append(ids, id.String())
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+            SELECT m.id, m.modifier_group_id, m.name, m.price_delta, m.is_available
+            FROM modifiers m
+            WHERE m.id = ANY($1::uuid[])
+        `, pq.Array(ids))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var choice modifierChoice
+			if err := rows.Scan(&choice.id, &choice.groupID, &choice.name, &choice.priceDelta, &choice.isAvailable); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			choices = append(choices, choice)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return selectModifiers(groups, choices, modifierIDs)
+}
+
+// selectModifiers validates the modifiers chosen for an item against the groups offered on
+// its product and returns them as order item modifiers. choices are the stored modifiers
+// found for modifierIDs.
+func selectModifiers(groups map[uuid.UUID]modifierGroupRule, choices []modifierChoice, modifierIDs []uuid.UUID) ([]models.OrderItemModifier, error) {
+	seen := map[uuid.UUID]bool{}
+	for _, id := range modifierIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("invalid_modifiers: modifier %s selected more than once", id)
+		}
+		seen[id] = true
+	}
+
+	var modifiers []models.OrderItemModifier
+	selected := map[uuid.UUID]int{}
+	found := map[uuid.UUID]bool{}
+	for _, choice := range choices {
+		found[choice.id] = true
+
+		g, ok := groups[choice.groupID]
+		if !ok {
+			return nil, fmt.Errorf("invalid_modifiers: %s is not offered on this product", choice.name)
+		}
+		if !choice.isAvailable {
+			return nil, fmt.Errorf("invalid_modifiers: %s is not available", choice.name)
+		}
+		selected[choice.groupID]++
+
+		id, gid := choice.id, choice.groupID
+		modifiers = append(modifiers, models.OrderItemModifier{
+			ModifierID:      &id,
+			ModifierGroupID: &gid,
+			GroupName:       g.name,
+			ModifierName:    choice.name,
+			PriceDelta:      choice.priceDelta,
+		})
+	}
+
+	for _, id := range modifierIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("invalid_modifiers: modifier %s not found", id)
+		}
+	}
+
+	for id, g := range groups {
+		if selected[id] < g.minSelect {
+			return nil, fmt.Errorf("invalid_modifiers: %s requires at least %d selection(s)", g.name, g.minSelect)
+		}
+		if g.maxSelect.Valid && selected[id] > int(g.maxSelect.Int32) {
+			return nil, fmt.Errorf("invalid_modifiers: %s allows at most %d selection(s)", g.name, g.maxSelect.Int32)
+		}
+	}
+
+	return modifiers, nil
+}
+
+// saveOrderItemModifiers stores the modifiers chosen for an order item
+func saveOrderItemModifiers(ctx context.Context, tx *sql.Tx, itemID uuid.UUID, modifiers []models.OrderItemModifier) error {
+	for _, modifier := range modifiers {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO order_item_modifiers (order_item_id, modifier_id, modifier_group_id, group_name, modifier_name, price_delta)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, itemID, modifier.ModifierID, modifier.ModifierGroupID, modifier.GroupName, modifier.ModifierName, modifier.PriceDelta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadOrderItemModifiers attaches the chosen modifiers to each item of an order
+func (r *PostgresOrderRepository) loadOrderItemModifiers(ctx context.Context, order *models.Order) error {
+	items := make([]*models.OrderItem, len(order.Items))
+	for i := range order.Items {
+		items[i] = &order.Items[i]
+	}
+	return LoadItemModifiers(ctx, r.db, items)
+}
+
+// LoadItemModifiers attaches the chosen modifiers to the given order items so whoever
+// prepares or prints them sees how each one should be made
+func LoadItemModifiers(ctx context.Context, q querier, items []*models.OrderItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	index := map[uuid.UUID]*models.OrderItem{}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		index[item.ID] = item
+		ids = append(ids, item.ID.String())
+	}
+
+	rows, err := q.QueryContext(ctx, `
+        SELECT id, order_item_id, modifier_id, modifier_group_id, group_name, modifier_name, price_delta
+        FROM order_item_modifiers
+        WHERE order_item_id = ANY($1::uuid[])
+        ORDER BY group_name, modifier_name
+    `, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var modifier models.OrderItemModifier
+		if err := rows.Scan(&modifier.ID, &modifier.OrderItemID, &modifier.ModifierID, &modifier.ModifierGroupID,
+			&modifier.GroupName, &modifier.ModifierName, &modifier.PriceDelta); err != nil {
+			return err
+		}
+		if item, ok := index[modifier.OrderItemID]; ok {
+			item.Modifiers = append(item.Modifiers, modifier)
+		}
+	}
+
+	return rows.Err()
+}
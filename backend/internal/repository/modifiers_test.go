@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSelectModifiers(t *testing.T) {
+	size, extras, sauces := uuid.New(), uuid.New(), uuid.New()
+	groups := map[uuid.UUID]modifierGroupRule{
+		size:   {name: "Size", minSelect: 1, maxSelect: sql.NullInt32{Int32: 1, Valid: true}},
+		extras: {name: "Extras", maxSelect: sql.NullInt32{Int32: 2, Valid: true}},
+		sauces: {name: "Sauces"}, // optional, no limit
+	}
+
+	large := modifierChoice{id: uuid.New(), groupID: size, name: "Large", priceDelta: 1.50, isAvailable: true}
+	small := modifierChoice{id: uuid.New(), groupID: size, name: "Small", priceDelta: -0.50, isAvailable: true}
+	bacon := modifierChoice{id: uuid.New(), groupID: extras, name: "Bacon", priceDelta: 2, isAvailable: true}
+	cheese := modifierChoice{id: uuid.New(), groupID: extras, name: "Cheese", priceDelta: 1, isAvailable: true}
+	egg := modifierChoice{id: uuid.New(), groupID: extras, name: "Egg", priceDelta: 1, isAvailable: true}
+	aioli := modifierChoice{id: uuid.New(), groupID: sauces, name: "Aioli", isAvailable: true}
+	truffle := modifierChoice{id: uuid.New(), groupID: sauces, name: "Truffle", priceDelta: 3}
+	offMenu := modifierChoice{id: uuid.New(), groupID: uuid.New(), name: "Anchovies", isAvailable: true}
+
+	ids := func(choices ...modifierChoice) []uuid.UUID {
+		list := make([]uuid.UUID, len(choices))
+		for i, c := range choices {
+			list[i] = c.id
+		}
+		return list
+	}
+
+	tests := []struct {
+		name        string
+		choices     []modifierChoice
+		modifierIDs []uuid.UUID
+		wantErr     string  // substring of the error, empty when valid
+		wantPrice   float64 // unit price of a 10.00 product
+	}{
+		{"required group chosen", []modifierChoice{large}, ids(large), "", 11.50},
+		{"negative delta", []modifierChoice{small}, ids(small), "", 9.50},
+		{"deltas add up", []modifierChoice{large, bacon, cheese, aioli}, ids(large, bacon, cheese, aioli), "", 14.50},
+		{"required group missing", []modifierChoice{bacon}, ids(bacon), "Size requires at least 1", 0},
+		{"nothing chosen", nil, nil, "Size requires at least 1", 0},
+		{"too many in a single-choice group", []modifierChoice{large, small}, ids(large, small), "Size allows at most 1", 0},
+		{"over the group maximum", []modifierChoice{large, bacon, cheese, egg}, ids(large, bacon, cheese, egg), "Extras allows at most 2", 0},
+		{"unlimited group", []modifierChoice{small, aioli}, ids(small, aioli), "", 9.50},
+		{"chosen twice", []modifierChoice{large}, ids(large, large), "selected more than once", 0},
+		{"not offered on the product", []modifierChoice{large, offMenu}, ids(large, offMenu), "Anchovies is not offered", 0},
+		{"unavailable", []modifierChoice{large, truffle}, ids(large, truffle), "Truffle is not available", 0},
+		{"unknown modifier", []modifierChoice{large}, append(ids(large), uuid.New()), "not found", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := selectModifiers(groups, tt.choices, tt.modifierIDs)
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid_modifiers:") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want invalid_modifiers containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.choices) {
+			t.Errorf("%s: got %d modifiers, want %d", tt.name, len(got), len(tt.choices))
+			continue
+		}
+		for i, modifier := range got {
+			choice := tt.choices[i]
+			if *modifier.ModifierID != choice.id || modifier.ModifierName != choice.name ||
+				modifier.GroupName != groups[choice.groupID].name || modifier.PriceDelta != choice.priceDelta {
+				t.Errorf("%s: modifier %d = %+v, want %s", tt.name, i, modifier, choice.name)
+			}
+		}
+		if price := modifiedUnitPrice(10, got); price != tt.wantPrice {
+			t.Errorf("%s: unit price = %v, want %v", tt.name, price, tt.wantPrice)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pos-backend/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// Order item adjustment types
+const (
+	AdjustmentVoid = "void"
+	AdjustmentComp = "comp"
+)
+
+// adjustmentReasonCodes lists the reason codes accepted for voids and comps
+var adjustmentReasonCodes = []string{
+	"customer_complaint",
+	"wrong_item",
+	"quality_issue",
+	"kitchen_error",
+	"server_error",
+	"long_wait",
+	"walkout",
+	"manager_comp",
+	"employee_meal",
+	"other",
+}
+
+// IsValidAdjustmentReason reports whether code is an accepted void/comp reason code
+func IsValidAdjustmentReason(code string) bool {
+	for _, c := range adjustmentReasonCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AdjustmentReasonCodes returns the accepted void/comp reason codes
+func AdjustmentReasonCodes() []string {
+	return append([]string(nil), adjustmentReasonCodes...)
+}
+
+// AdjustOrderItem voids or comps an order item, keeping the original line and recording the approver
+func (r *PostgresOrderRepository) AdjustOrderItem(ctx context.Context, orderID, itemID uuid.UUID, adjustmentType, reasonCode string, notes *string, requestedBy, approvedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	var productID uuid.UUID
+	var stationID uuid.NullUUID
+	var quantity int
+	var totalPrice float64
+	var status string
+	var adjustment sql.NullString
+	err = tx.QueryRowContext(ctx, `
+        SELECT product_id, station_id, quantity, total_price, status, adjustment
+        FROM order_items
+        WHERE id = $1 AND order_id = $2
+        FOR UPDATE
+    `, itemID, orderID).Scan(&productID, &stationID, &quantity, &totalPrice, &status, &adjustment)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("order_item_not_found: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	if adjustment.Valid {
+		return fmt.Errorf("order_item_already_adjusted: item is already %sed", adjustment.String)
+	}
+
+	// The line stays on the order with its quantity and unit price; only its charge is removed
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE order_items SET adjustment = $1, total_price = 0, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $2
+    `, adjustmentType, itemID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO order_item_adjustments (order_id, order_item_id, product_id, adjustment_type, reason_code,
+                                            quantity, amount, requested_by, approved_by, notes)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `, orderID, itemID, productID, adjustmentType, reasonCode, quantity, totalPrice, requestedBy, approvedBy, notes); err != nil {
+		return err
+	}
+
+	// A voided item the kitchen has not started was never made, so its stock goes back like a cancel
+	if adjustmentType == AdjustmentVoid && status == ItemStatusPending {
+		stockNotes := "Item voided"
+		if err := moveStock(ctx, tx, productID, quantity, MovementReturn, &orderID, nil, &stockNotes, &requestedBy, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	changes, err := rollUpEditedOrder(ctx, tx, orderID, requestedBy)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	var stations []string
+	if stationID.Valid {
+		stations = []string{stationID.UUID.String()}
+	}
+	events.Publish(events.ItemChanged, orderID, stations, map[string]interface{}{
+		"item_id": itemID,
+		"action":  adjustmentType,
+	})
+	publishStatusChanges(orderID, changes, requestedBy)
+
+	return nil
+}
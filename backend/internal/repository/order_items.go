@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AddOrderItem appends a new item to an open order and recalculates its totals. A ready or
+// served order goes back to preparing so the new item reaches its station.
+func (r *PostgresOrderRepository) AddOrderItem(ctx context.Context, orderID uuid.UUID, item models.CreateOrderItem, changedBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if item.Hold {
+		var orderType string
+		if err := tx.QueryRowContext(ctx, "SELECT order_type FROM orders WHERE id = $1", orderID).Scan(&orderType); err != nil {
+			return uuid.Nil, err
+		}
+		if orderType != "dine_in" {
+			return uuid.Nil, fmt.Errorf("hold_not_allowed: only dine-in items can be held")
+		}
+	}
+
+	price, modifiers, err := priceOrderItem(ctx, tx, item)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	itemID := uuid.New()
+	var stationID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price, special_instructions,
+                                 station_id, course, is_held, fired_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, `+itemStationQuery+`, $8, $9, `+itemFiredAt+`)
+        RETURNING station_id
+    `, itemID, orderID, item.ProductID, item.Quantity, price, price*float64(item.Quantity), item.SpecialInstructions,
+		itemCourse(item), item.Hold).Scan(&stationID); err != nil {
+		return uuid.Nil, err
+	}
+	if err := saveOrderItemModifiers(ctx, tx, itemID, modifiers); err != nil {
+		return uuid.Nil, err
+	}
+	if err := moveStock(ctx, tx, item.ProductID, -item.Quantity, MovementSale, &orderID, nil, nil, &changedBy, nil); err != nil {
+		return uuid.Nil, err
+	}
+
+	newQuantity := item.Quantity
+	if err := logOrderItemChange(ctx, tx, orderID, itemID, item.ProductID, "added", nil, &newQuantity, changedBy, nil); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
+	changes, err := rollUpEditedOrder(ctx, tx, orderID, changedBy)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	if !item.Hold {
+		var stations []string
+		if stationID.Valid {
+			stations = []string{stationID.UUID.String()}
+		}
+		events.Publish(events.ItemAdded, orderID, stations, map[string]interface{}{
+			"item_ids": []uuid.UUID{itemID},
+			"status":   ItemStatusPending,
+		})
+	}
+	publishStatusChanges(orderID, changes, changedBy)
+
+	return itemID, nil
+}
+
+// UpdateOrderItem changes the quantity or instructions of an item that has not reached the kitchen line
+func (r *PostgresOrderRepository) UpdateOrderItem(ctx context.Context, orderID, itemID uuid.UUID, req models.UpdateOrderItemRequest, changedBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	productID, quantity, unitPrice, err := lockPendingOrderItem(ctx, tx, orderID, itemID)
+	if err != nil {
+		return err
+	}
+	stations, err := orderItemStations(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+
+	if req.SpecialInstructions != nil {
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE order_items SET special_instructions = $1, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $2
+        `, req.SpecialInstructions, itemID); err != nil {
+			return err
+		}
+	}
+
+	if req.Quantity != nil && *req.Quantity != quantity {
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE order_items SET quantity = $1, total_price = $2, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $3
+        `, *req.Quantity, unitPrice*float64(*req.Quantity), itemID); err != nil {
+			return err
+		}
+
+		if err := logOrderItemChange(ctx, tx, orderID, itemID, productID, "quantity_changed", &quantity, req.Quantity, changedBy, req.Notes); err != nil {
+			return err
+		}
+
+		movementType := MovementSale
+		if *req.Quantity < quantity {
+			movementType = MovementReturn
+		}
+		if err := moveStock(ctx, tx, productID, quantity-*req.Quantity, movementType, &orderID, nil, req.Notes, &changedBy, nil); err != nil {
+			return err
+		}
+
+		if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+			return err
+		}
+	}
+
+	changes, err := rollUpEditedOrder(ctx, tx, orderID, changedBy)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	newQuantity := quantity
+	if req.Quantity != nil {
+		newQuantity = *req.Quantity
+	}
+	events.Publish(events.ItemChanged, orderID, stations, map[string]interface{}{
+		"item_id":  itemID,
+		"action":   "updated",
+		"quantity": newQuantity,
+	})
+	publishStatusChanges(orderID, changes, changedBy)
+
+	return nil
+}
+
+// RemoveOrderItem deletes an item that has not reached the kitchen line and recalculates totals
+func (r *PostgresOrderRepository) RemoveOrderItem(ctx context.Context, orderID, itemID uuid.UUID, changedBy uuid.UUID, notes *string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	productID, quantity, _, err := lockPendingOrderItem(ctx, tx, orderID, itemID)
+	if err != nil {
+		return err
+	}
+	stations, err := orderItemStations(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+
+	var itemCount int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM order_items WHERE order_id = $1", orderID).Scan(&itemCount); err != nil {
+		return err
+	}
+	if itemCount <= 1 {
+		return fmt.Errorf("last_order_item: cancel the order instead")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_items WHERE id = $1", itemID); err != nil {
+		return err
+	}
+
+	if err := logOrderItemChange(ctx, tx, orderID, itemID, productID, "removed", &quantity, nil, changedBy, notes); err != nil {
+		return err
+	}
+
+	if err := moveStock(ctx, tx, productID, quantity, MovementReturn, &orderID, nil, notes, &changedBy, nil); err != nil {
+		return err
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	changes, err := rollUpEditedOrder(ctx, tx, orderID, changedBy)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	events.Publish(events.ItemChanged, orderID, stations, map[string]interface{}{
+		"item_id": itemID,
+		"action":  "removed",
+	})
+	publishStatusChanges(orderID, changes, changedBy)
+
+	return nil
+}
+
+// ListOrderItemChanges returns the item edit log for an order
+func (r *PostgresOrderRepository) ListOrderItemChanges(ctx context.Context, orderID uuid.UUID) ([]models.OrderItemChange, error) {
+	query := `
+        SELECT c.id, c.order_id, c.order_item_id, c.product_id, c.action,
+               c.previous_quantity, c.new_quantity, c.changed_by, c.notes, c.created_at,
+               p.name, u.username, u.first_name, u.last_name
+        FROM order_item_changes c
+        LEFT JOIN products p ON c.product_id = p.id
+        LEFT JOIN users u ON c.changed_by = u.id
+        WHERE c.order_id = $1
+        ORDER BY c.created_at
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.OrderItemChange{}
+	for rows.Next() {
+		var change models.OrderItemChange
+		var productName, username, firstName, lastName sql.NullString
+
+		if err := rows.Scan(
+			&change.ID, &change.OrderID, &change.OrderItemID, &change.ProductID, &change.Action,
+			&change.PreviousQuantity, &change.NewQuantity, &change.ChangedBy, &change.Notes, &change.CreatedAt,
+			&productName, &username, &firstName, &lastName,
+		); err != nil {
+			return nil, err
+		}
+
+		if productName.Valid {
+			change.Product = &models.Product{ID: change.ProductID, Name: productName.String}
+		}
+		if username.Valid {
+			change.ChangedByUser = &models.User{
+				Username:  username.String,
+				FirstName: firstName.String,
+				LastName:  lastName.String,
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// lockEditableOrder locks the order row and checks that its items may still be changed
+func lockEditableOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", orderID).Scan(&status); err != nil {
+		return err
+	}
+
+	if err := checkBusinessDayOpen(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	if status == OrderStatusCompleted || status == OrderStatusCancelled {
+		return fmt.Errorf("order_not_editable: order is %s", status)
+	}
+
+	// A pending payment left behind by an interrupted charge must not block the order forever
+	if _, err := ExpireStalePayments(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	var paymentCount int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM payments
+        WHERE order_id = $1 AND status IN ('pending', 'completed')
+    `, orderID).Scan(&paymentCount); err != nil {
+		return err
+	}
+	if paymentCount > 0 {
+		return fmt.Errorf("order_has_payments: payment has already started")
+	}
+
+	return nil
+}
+
+// lockPendingOrderItem locks an order item that the kitchen has not started yet
+func lockPendingOrderItem(ctx context.Context, tx *sql.Tx, orderID, itemID uuid.UUID) (uuid.UUID, int, float64, error) {
+	var productID uuid.UUID
+	var quantity int
+	var unitPrice float64
+	var status string
+	var adjustment sql.NullString
+
+	err := tx.QueryRowContext(ctx, `
+        SELECT product_id, quantity, unit_price, status, adjustment
+        FROM order_items
+        WHERE id = $1 AND order_id = $2
+        FOR UPDATE
+    `, itemID, orderID).Scan(&productID, &quantity, &unitPrice, &status, &adjustment)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, 0, 0, fmt.Errorf("order_item_not_found: %w", err)
+	}
+	if err != nil {
+		return uuid.Nil, 0, 0, err
+	}
+
+	if adjustment.Valid {
+		return uuid.Nil, 0, 0, fmt.Errorf("order_item_already_adjusted: item is %s", adjustment.String)
+	}
+
+	if status != "pending" {
+		return uuid.Nil, 0, 0, fmt.Errorf("order_item_in_progress: item is %s", status)
+	}
+
+	return productID, quantity, unitPrice, nil
+}
+
+// orderItemStations returns the station an item is routed to, for the events published about it
+func orderItemStations(ctx context.Context, tx *sql.Tx, itemID uuid.UUID) ([]string, error) {
+	var stationID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, "SELECT station_id FROM order_items WHERE id = $1", itemID).Scan(&stationID); err != nil {
+		return nil, err
+	}
+	if !stationID.Valid {
+		return nil, nil
+	}
+	return []string{stationID.UUID.String()}, nil
+}
+
+// rollUpEditedOrder re-derives the status of an order after its items were added, changed or removed
+func rollUpEditedOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, changedBy uuid.UUID) ([]StatusChange, error) {
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1", orderID).Scan(&status); err != nil {
+		return nil, err
+	}
+	return rollUpOrderStatus(ctx, tx, orderID, status, changedBy)
+}
+
+// recalculateOrderTotals recomputes subtotal, discounts, taxes and total from the current order items.
+// Discounts are taken off each line before tax is calculated on it.
+func recalculateOrderTotals(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+	var orderType string
+	if err := tx.QueryRowContext(ctx, "SELECT order_type FROM orders WHERE id = $1", orderID).Scan(&orderType); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT oi.id, oi.product_id, p.category_id, oi.quantity, oi.unit_price, oi.total_price
+        FROM order_items oi
+        JOIN products p ON oi.product_id = p.id
+        WHERE oi.order_id = $1
+    `, orderID)
+	if err != nil {
+		return err
+	}
+	var lines []pricedLine
+	var subtotal float64
+	for rows.Next() {
+		var line pricedLine
+		if err := rows.Scan(&line.ItemID, &line.ProductID, &line.CategoryID, &line.Quantity, &line.UnitPrice, &line.Amount); err != nil {
+			rows.Close()
+			return err
+		}
+		subtotal += line.Amount
+		lines = append(lines, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	lineDiscounts, discountAmount, err := applyOrderDiscounts(ctx, tx, orderID, lines)
+	if err != nil {
+		return err
+	}
+
+	taxable := make([]taxableLine, 0, len(lines))
+	for _, line := range lines {
+		taxable = append(taxable, taxableLine{ProductID: line.ProductID, Amount: line.Amount - lineDiscounts[line.ItemID]})
+	}
+
+	taxes, err := calculateOrderTaxes(ctx, tx, orderType, taxable)
+	if err != nil {
+		return err
+	}
+	if err := saveOrderTaxes(ctx, tx, orderID, taxes); err != nil {
+		return err
+	}
+
+	subtotal = roundMoney(subtotal)
+	serviceCharge, err := orderServiceCharge(ctx, tx, orderID, orderType, subtotal-discountAmount)
+	if err != nil {
+		return err
+	}
+	if err := saveOrderServiceCharge(ctx, tx, orderID, serviceCharge); err != nil {
+		return err
+	}
+
+	totalAmount := roundMoney(subtotal + taxes.ExclusiveTotal - discountAmount)
+	if serviceCharge != nil {
+		totalAmount = roundMoney(totalAmount + serviceCharge.Amount)
+	}
+	if totalAmount < 0 {
+		totalAmount = 0
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE orders
+        SET subtotal = $1, tax_amount = $2, discount_amount = $3, total_amount = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $5
+    `, subtotal, taxes.Total(), discountAmount, totalAmount, orderID)
+	return err
+}
+
+func logOrderItemChange(ctx context.Context, tx *sql.Tx, orderID, itemID, productID uuid.UUID, action string, previousQuantity, newQuantity *int, changedBy uuid.UUID, notes *string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO order_item_changes (order_id, order_item_id, product_id, action, previous_quantity, new_quantity, changed_by, notes)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `, orderID, itemID, productID, action, previousQuantity, newQuantity, changedBy, notes)
+	return err
+}
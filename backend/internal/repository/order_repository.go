@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"pos-backend/internal/events"
 	"pos-backend/internal/models"
 
 	"github.com/google/uuid"
@@ -15,7 +17,18 @@ type OrderRepository interface {
 	ListOrders(ctx context.Context, status, orderType string, limit, offset int) ([]models.Order, int, error)
 	GetOrderByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
 	CreateOrder(ctx context.Context, req models.CreateOrderRequest, userID uuid.UUID, orderNumber string) (uuid.UUID, error)
-	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus string, changedBy uuid.UUID, notes *string) error
+	GetOrderStatus(ctx context.Context, orderID uuid.UUID) (string, error)
+	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus string, changedBy uuid.UUID, role string, notes *string) error
+	AddOrderItem(ctx context.Context, orderID uuid.UUID, item models.CreateOrderItem, changedBy uuid.UUID) (uuid.UUID, error)
+	UpdateOrderItem(ctx context.Context, orderID, itemID uuid.UUID, req models.UpdateOrderItemRequest, changedBy uuid.UUID) error
+	RemoveOrderItem(ctx context.Context, orderID, itemID uuid.UUID, changedBy uuid.UUID, notes *string) error
+	ListOrderItemChanges(ctx context.Context, orderID uuid.UUID) ([]models.OrderItemChange, error)
+	AdjustOrderItem(ctx context.Context, orderID, itemID uuid.UUID, adjustmentType, reasonCode string, notes *string, requestedBy, approvedBy uuid.UUID) error
+	VerifyManagerApproval(ctx context.Context, approval models.ManagerApproval, requestedBy uuid.UUID) (uuid.UUID, error)
+	ApplyDiscount(ctx context.Context, orderID, promotionID, appliedBy uuid.UUID) (uuid.UUID, error)
+	RemoveDiscount(ctx context.Context, orderID, discountID uuid.UUID) error
+	FireCourse(ctx context.Context, orderID uuid.UUID, course int, firedBy uuid.UUID) (int, error)
+	SetGuestCount(ctx context.Context, orderID uuid.UUID, guestCount int) error
 }
 
 // PostgresOrderRepository is an implementation of OrderRepository using *sql.DB
@@ -33,6 +46,7 @@ func (r *PostgresOrderRepository) ListOrders(ctx context.Context, status, orderT
         SELECT DISTINCT o.id, o.order_number, o.table_id, o.user_id, o.customer_name,
                o.order_type, o.status, o.subtotal, o.tax_amount, o.discount_amount,
                o.total_amount, o.notes, o.created_at, o.updated_at, o.served_at, o.completed_at,
+               o.guest_count, o.service_charge_name, o.service_charge_rate, o.service_charge_amount,
                t.table_number, t.location,
                u.username, u.first_name, u.last_name
         FROM orders o
@@ -78,17 +92,22 @@ func (r *PostgresOrderRepository) ListOrders(ctx context.Context, status, orderT
 		var order models.Order
 		var tableNumber, tableLocation sql.NullString
 		var username, firstName, lastName sql.NullString
+		var chargeName sql.NullString
+		var chargeRate sql.NullFloat64
+		var chargeAmount float64
 
 		if err := rows.Scan(
 			&order.ID, &order.OrderNumber, &order.TableID, &order.UserID, &order.CustomerName,
 			&order.OrderType, &order.Status, &order.Subtotal, &order.TaxAmount, &order.DiscountAmount,
 			&order.TotalAmount, &order.Notes, &order.CreatedAt, &order.UpdatedAt, &order.ServedAt, &order.CompletedAt,
+			&order.GuestCount, &chargeName, &chargeRate, &chargeAmount,
 			&tableNumber, &tableLocation,
 			&username, &firstName, &lastName,
 		); err != nil {
 			return nil, 0, err
 		}
 
+		order.ServiceCharge = scannedServiceCharge(chargeName, chargeRate, chargeAmount)
 		if tableNumber.Valid {
 			order.Table = &models.DiningTable{
 				TableNumber: tableNumber.String,
@@ -121,11 +140,15 @@ func (r *PostgresOrderRepository) GetOrderByID(ctx context.Context, id uuid.UUID
 	var order models.Order
 	var tableNumber, tableLocation sql.NullString
 	var username, firstName, lastName sql.NullString
+	var chargeName sql.NullString
+	var chargeRate sql.NullFloat64
+	var chargeAmount float64
 
 	query := `
         SELECT o.id, o.order_number, o.table_id, o.user_id, o.customer_name,
                o.order_type, o.status, o.subtotal, o.tax_amount, o.discount_amount,
                o.total_amount, o.notes, o.created_at, o.updated_at, o.served_at, o.completed_at,
+               o.guest_count, o.service_charge_name, o.service_charge_rate, o.service_charge_amount,
                t.table_number, t.location,
                u.username, u.first_name, u.last_name
         FROM orders o
@@ -138,12 +161,14 @@ func (r *PostgresOrderRepository) GetOrderByID(ctx context.Context, id uuid.UUID
 		&order.ID, &order.OrderNumber, &order.TableID, &order.UserID, &order.CustomerName,
 		&order.OrderType, &order.Status, &order.Subtotal, &order.TaxAmount, &order.DiscountAmount,
 		&order.TotalAmount, &order.Notes, &order.CreatedAt, &order.UpdatedAt, &order.ServedAt, &order.CompletedAt,
+		&order.GuestCount, &chargeName, &chargeRate, &chargeAmount,
 		&tableNumber, &tableLocation,
 		&username, &firstName, &lastName,
 	); err != nil {
 		return nil, err
 	}
 
+	order.ServiceCharge = scannedServiceCharge(chargeName, chargeRate, chargeAmount)
 	if tableNumber.Valid {
 		order.Table = &models.DiningTable{
 			TableNumber: tableNumber.String,
@@ -164,6 +189,12 @@ func (r *PostgresOrderRepository) GetOrderByID(ctx context.Context, id uuid.UUID
 	if err := r.loadOrderPayments(ctx, &order); err != nil {
 		return nil, err
 	}
+	if err := r.loadOrderTaxes(ctx, &order); err != nil {
+		return nil, err
+	}
+	if err := r.loadOrderDiscounts(ctx, &order); err != nil {
+		return nil, err
+	}
 
 	return &order, nil
 }
@@ -177,50 +208,66 @@ func (r *PostgresOrderRepository) CreateOrder(ctx context.Context, req models.Cr
 
 	orderID := uuid.New()
 
-	// calculate subtotal
-	var subtotal float64
-	for _, item := range req.Items {
-		var price float64
-		if err := tx.QueryRowContext(ctx, "SELECT price FROM products WHERE id = $1 AND is_available = true", item.ProductID).Scan(&price); err != nil {
-			if err == sql.ErrNoRows {
-				return uuid.Nil, fmt.Errorf("product_not_found: %w", err)
-			}
+	// look up current prices and modifiers; unavailable products reject the whole order
+	prices := make([]float64, len(req.Items))
+	modifiers := make([][]models.OrderItemModifier, len(req.Items))
+	for i, item := range req.Items {
+		if item.Hold && req.OrderType != "dine_in" {
+			return uuid.Nil, fmt.Errorf("hold_not_allowed: only dine-in items can be held")
+		}
+		if prices[i], modifiers[i], err = priceOrderItem(ctx, tx, item); err != nil {
 			return uuid.Nil, err
 		}
-		subtotal += price * float64(item.Quantity)
 	}
 
-	taxRate := 0.10
-	taxAmount := subtotal * taxRate
-	totalAmount := subtotal + taxAmount
-
 	orderQuery := `
         INSERT INTO orders (id, order_number, table_id, user_id, customer_name, order_type, status,
-                           subtotal, tax_amount, discount_amount, total_amount, notes)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+                           subtotal, tax_amount, discount_amount, total_amount, notes, guest_count)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
     `
 
 	if _, err := tx.ExecContext(ctx, orderQuery, orderID, orderNumber, req.TableID, userID, req.CustomerName,
-		req.OrderType, "pending", subtotal, taxAmount, 0, totalAmount, req.Notes); err != nil {
+		req.OrderType, "pending", 0, 0, 0, 0, req.Notes, req.GuestCount); err != nil {
 		return uuid.Nil, err
 	}
 
-	for _, item := range req.Items {
-		var price float64
-		if err := tx.QueryRowContext(ctx, "SELECT price FROM products WHERE id = $1", item.ProductID).Scan(&price); err != nil {
-			return uuid.Nil, err
-		}
+	// created_at is when this transaction began, so a day closed since then would miss the order
+	if err := checkBusinessDayOpen(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
+	stations := map[string]bool{}
+	for i, item := range req.Items {
+		price := prices[i]
 		totalPrice := price * float64(item.Quantity)
 		itemID := uuid.New()
 		itemQuery := `
-            INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price, special_instructions)
-            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price, special_instructions,
+                                     station_id, course, is_held, fired_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, ` + itemStationQuery + `, $8, $9, ` + itemFiredAt + `)
+            RETURNING station_id
         `
-		if _, err := tx.ExecContext(ctx, itemQuery, itemID, orderID, item.ProductID, item.Quantity, price, totalPrice, item.SpecialInstructions); err != nil {
+		var stationID *uuid.UUID
+		if err := tx.QueryRowContext(ctx, itemQuery, itemID, orderID, item.ProductID, item.Quantity, price, totalPrice, item.SpecialInstructions,
+			itemCourse(item), item.Hold).Scan(&stationID); err != nil {
+			return uuid.Nil, err
+		}
+		if stationID != nil && !item.Hold {
+			stations[stationID.String()] = true
+		}
+		if err := saveOrderItemModifiers(ctx, tx, itemID, modifiers[i]); err != nil {
+			return uuid.Nil, err
+		}
+		if err := moveStock(ctx, tx, item.ProductID, -item.Quantity, MovementSale, &orderID, nil, nil, &userID, nil); err != nil {
 			return uuid.Nil, err
 		}
 	}
 
+	// subtotal, tax breakdown and total come from the tax engine
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
 	if req.OrderType == "dine_in" && req.TableID != nil {
 		if _, err := tx.ExecContext(ctx, "UPDATE dining_tables SET is_occupied = true WHERE id = $1", *req.TableID); err != nil {
 			return uuid.Nil, err
@@ -231,10 +278,28 @@ func (r *PostgresOrderRepository) CreateOrder(ctx context.Context, req models.Cr
 		return uuid.Nil, err
 	}
 
+	events.Publish(events.OrderCreated, orderID, stationList(stations), map[string]interface{}{
+		"order_number": orderNumber,
+		"order_type":   req.OrderType,
+		"table_id":     req.TableID,
+		"status":       "pending",
+		"item_count":   len(req.Items),
+	})
+
 	return orderID, nil
 }
 
-func (r *PostgresOrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus string, changedBy uuid.UUID, notes *string) error {
+// GetOrderStatus returns the current status of an order
+func (r *PostgresOrderRepository) GetOrderStatus(ctx context.Context, orderID uuid.UUID) (string, error) {
+	var status string
+	if err := r.db.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1", orderID).Scan(&status); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// UpdateOrderStatus moves an order to newStatus, enforcing the lifecycle graph for the given role
+func (r *PostgresOrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus string, changedBy uuid.UUID, role string, notes *string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -242,33 +307,44 @@ func (r *PostgresOrderRepository) UpdateOrderStatus(ctx context.Context, orderID
 	defer tx.Rollback()
 
 	var currentStatus string
-	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1", orderID).Scan(&currentStatus); err != nil {
-		if err == sql.ErrNoRows {
-			return err
-		}
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", orderID).Scan(&currentStatus); err != nil {
 		return err
 	}
 
-	updateQuery := "UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP"
-	if newStatus == "served" {
-		updateQuery += ", served_at = CURRENT_TIMESTAMP"
-	} else if newStatus == "completed" {
-		updateQuery += ", completed_at = CURRENT_TIMESTAMP"
+	if err := checkBusinessDayOpen(ctx, tx, orderID); err != nil {
+		return err
 	}
-	updateQuery += " WHERE id = $2"
 
-	if _, err := tx.ExecContext(ctx, updateQuery, newStatus, orderID); err != nil {
-		return err
+	if !CanTransition(currentStatus, newStatus, role) {
+		return &TransitionError{From: currentStatus, To: newStatus, Role: role}
 	}
 
-	historyQuery := `
-        INSERT INTO order_status_history (order_id, previous_status, new_status, changed_by, notes)
-        VALUES ($1, $2, $3, $4, $5)
-    `
-	if _, err := tx.ExecContext(ctx, historyQuery, orderID, currentStatus, newStatus, changedBy, notes); err != nil {
+	if newStatus == OrderStatusReady {
+		pending, err := pendingStations(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("stations_pending: %s", strings.Join(pending, ", "))
+		}
+	}
+
+	if err := setOrderStatus(ctx, tx, orderID, currentStatus, newStatus, changedBy, notes); err != nil {
 		return err
 	}
 
+	if newStatus == "cancelled" {
+		if err := returnOrderStock(ctx, tx, orderID, changedBy); err != nil {
+			return err
+		}
+	}
+
+	if newStatus == "completed" {
+		if err := ConsumeOrderIngredients(ctx, tx, orderID, changedBy); err != nil {
+			return err
+		}
+	}
+
 	if newStatus == "completed" || newStatus == "cancelled" {
 		if _, err := tx.ExecContext(ctx, `
             UPDATE dining_tables
@@ -279,19 +355,35 @@ func (r *PostgresOrderRepository) UpdateOrderStatus(ctx context.Context, orderID
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	events.Publish(events.OrderStatusChanged, orderID, nil, map[string]interface{}{
+		"previous_status": currentStatus,
+		"status":          newStatus,
+		"changed_by":      changedBy,
+	})
+
+	return nil
 }
 
 // loadOrderItems and loadOrderPayments are internal helpers
 func (r *PostgresOrderRepository) loadOrderItems(ctx context.Context, order *models.Order) error {
 	query := `
         SELECT oi.id, oi.product_id, oi.quantity, oi.unit_price, oi.total_price,
-               oi.special_instructions, oi.status, oi.created_at, oi.updated_at,
-               p.name, p.description, p.price, p.preparation_time
+               oi.special_instructions, oi.status, oi.station_id, oi.course, oi.is_held, oi.fired_at,
+               oi.created_at, oi.updated_at,
+               p.name, p.description, p.price, p.preparation_time,
+               a.id, a.adjustment_type, a.reason_code, a.quantity, a.amount,
+               a.requested_by, a.approved_by, a.notes, a.created_at,
+               au.username, au.first_name, au.last_name
         FROM order_items oi
         JOIN products p ON oi.product_id = p.id
+        LEFT JOIN order_item_adjustments a ON a.order_item_id = oi.id
+        LEFT JOIN users au ON a.approved_by = au.id
         WHERE oi.order_id = $1
-        ORDER BY oi.created_at
+        ORDER BY oi.course, oi.created_at
     `
 
 	rows, err := r.db.QueryContext(ctx, query, order.ID)
@@ -306,11 +398,21 @@ func (r *PostgresOrderRepository) loadOrderItems(ctx context.Context, order *mod
 		var productName, productDescription sql.NullString
 		var productPrice sql.NullFloat64
 		var preparationTime sql.NullInt32
+		var adjustmentID, requestedBy, approvedBy uuid.NullUUID
+		var adjustmentType, reasonCode, adjustmentNotes sql.NullString
+		var adjustmentQuantity sql.NullInt32
+		var adjustmentAmount sql.NullFloat64
+		var adjustedAt sql.NullTime
+		var approverUsername, approverFirstName, approverLastName sql.NullString
 
 		if err := rows.Scan(
 			&item.ID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.TotalPrice,
-			&item.SpecialInstructions, &item.Status, &item.CreatedAt, &item.UpdatedAt,
+			&item.SpecialInstructions, &item.Status, &item.StationID, &item.Course, &item.IsHeld, &item.FiredAt,
+			&item.CreatedAt, &item.UpdatedAt,
 			&productName, &productDescription, &productPrice, &preparationTime,
+			&adjustmentID, &adjustmentType, &reasonCode, &adjustmentQuantity, &adjustmentAmount,
+			&requestedBy, &approvedBy, &adjustmentNotes, &adjustedAt,
+			&approverUsername, &approverFirstName, &approverLastName,
 		); err != nil {
 			return err
 		}
@@ -337,16 +439,53 @@ func (r *PostgresOrderRepository) loadOrderItems(ctx context.Context, order *mod
 			PreparationTime: prep,
 		}
 
+		if adjustmentID.Valid {
+			productID := item.ProductID
+			item.Adjustment = &models.OrderItemAdjustment{
+				ID:             adjustmentID.UUID,
+				OrderID:        order.ID,
+				OrderItemID:    item.ID,
+				ProductID:      &productID,
+				AdjustmentType: adjustmentType.String,
+				ReasonCode:     reasonCode.String,
+				Quantity:       int(adjustmentQuantity.Int32),
+				Amount:         adjustmentAmount.Float64,
+				CreatedAt:      adjustedAt.Time,
+			}
+			if requestedBy.Valid {
+				item.Adjustment.RequestedBy = &requestedBy.UUID
+			}
+			if approvedBy.Valid {
+				item.Adjustment.ApprovedBy = &approvedBy.UUID
+			}
+			if adjustmentNotes.Valid {
+				item.Adjustment.Notes = &adjustmentNotes.String
+			}
+			if approverUsername.Valid {
+				item.Adjustment.ApprovedByUser = &models.User{
+					Username:  approverUsername.String,
+					FirstName: approverFirstName.String,
+					LastName:  approverLastName.String,
+				}
+			}
+		}
+
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
 
 	order.Items = items
-	return nil
+	return r.loadOrderItemModifiers(ctx, order)
 }
 
 func (r *PostgresOrderRepository) loadOrderPayments(ctx context.Context, order *models.Order) error {
 	query := `
-        SELECT p.id, p.payment_method, p.amount, p.reference_number, p.status,
+        SELECT p.id, p.payment_method, p.amount, p.tip_amount, p.tendered_amount, p.change_given, p.rounding_amount,
+               p.reference_number, p.status, p.drawer_session_id,
+               COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'completed'), 0),
                p.processed_by, p.processed_at, p.created_at,
                u.username, u.first_name, u.last_name
         FROM payments p
@@ -367,8 +506,9 @@ func (r *PostgresOrderRepository) loadOrderPayments(ctx context.Context, order *
 		var username, firstName, lastName sql.NullString
 
 		if err := rows.Scan(
-			&payment.ID, &payment.PaymentMethod, &payment.Amount, &payment.ReferenceNumber,
-			&payment.Status, &payment.ProcessedBy, &payment.ProcessedAt, &payment.CreatedAt,
+			&payment.ID, &payment.PaymentMethod, &payment.Amount, &payment.TipAmount,
+			&payment.TenderedAmount, &payment.ChangeGiven, &payment.RoundingAmount, &payment.ReferenceNumber,
+			&payment.Status, &payment.DrawerSessionID, &payment.RefundedAmount, &payment.ProcessedBy, &payment.ProcessedAt, &payment.CreatedAt,
 			&username, &firstName, &lastName,
 		); err != nil {
 			return err
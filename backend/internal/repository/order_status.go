@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pos-backend/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// Order lifecycle statuses
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready"
+	OrderStatusServed    = "served"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Order item kitchen statuses
+const (
+	ItemStatusPending   = "pending"
+	ItemStatusPreparing = "preparing"
+	ItemStatusReady     = "ready"
+	ItemStatusServed    = "served"
+)
+
+// orderTransitions maps each status to the statuses it may move to and the
+// roles allowed to perform that move. Admins may perform any listed transition.
+var orderTransitions = map[string]map[string][]string{
+	OrderStatusPending: {
+		OrderStatusConfirmed: {"manager", "server", "counter"},
+		OrderStatusPreparing: {"manager", "kitchen"},
+		OrderStatusCancelled: {"manager", "server", "counter"},
+	},
+	OrderStatusConfirmed: {
+		OrderStatusPreparing: {"manager", "kitchen"},
+		OrderStatusCancelled: {"manager", "server", "counter"},
+	},
+	OrderStatusPreparing: {
+		OrderStatusReady:     {"manager", "kitchen"},
+		OrderStatusCancelled: {"manager"},
+	},
+	OrderStatusReady: {
+		OrderStatusPreparing: {"manager", "kitchen"},
+		OrderStatusServed:    {"manager", "server", "counter", "kitchen"},
+		OrderStatusCompleted: {"manager", "counter"},
+		OrderStatusCancelled: {"manager"},
+	},
+	OrderStatusServed: {
+		OrderStatusPreparing: {"manager", "kitchen"},
+		OrderStatusCompleted: {"manager", "server", "counter"},
+	},
+	OrderStatusCompleted: {},
+	OrderStatusCancelled: {},
+}
+
+// orderStatusOrder keeps AllowedTransitions output stable
+var orderStatusOrder = []string{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+	OrderStatusReady,
+	OrderStatusServed,
+	OrderStatusCompleted,
+	OrderStatusCancelled,
+}
+
+// TransitionError is returned when an order status change is not permitted
+type TransitionError struct {
+	From string
+	To   string
+	Role string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("invalid_transition: %s cannot move order from %s to %s", e.Role, e.From, e.To)
+}
+
+// StatusChange is one order status move made on the caller's behalf
+type StatusChange struct {
+	From string
+	To   string
+}
+
+// IsValidItemStatus reports whether status is a known order item status
+func IsValidItemStatus(status string) bool {
+	switch status {
+	case ItemStatusPending, ItemStatusPreparing, ItemStatusReady, ItemStatusServed:
+		return true
+	}
+	return false
+}
+
+// IsValidOrderStatus reports whether status is a known order status
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether role may move an order from one status to another
+func CanTransition(from, to, role string) bool {
+	roles, ok := orderTransitions[from][to]
+	if !ok {
+		return false
+	}
+	if role == "admin" {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions lists the statuses role may move an order to from the given status
+func AllowedTransitions(from, role string) []string {
+	allowed := []string{}
+	for _, to := range orderStatusOrder {
+		if CanTransition(from, to, role) {
+			allowed = append(allowed, to)
+		}
+	}
+	return allowed
+}
+
+// setOrderStatus writes a status change and its history entry inside tx.
+// Callers are responsible for checking the transition first.
+func setOrderStatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, from, to string, changedBy uuid.UUID, notes *string) error {
+	updateQuery := "UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP"
+	if to == OrderStatusServed {
+		updateQuery += ", served_at = CURRENT_TIMESTAMP"
+	} else if to == OrderStatusCompleted {
+		updateQuery += ", completed_at = CURRENT_TIMESTAMP"
+	}
+	updateQuery += " WHERE id = $2"
+
+	if _, err := tx.ExecContext(ctx, updateQuery, to, orderID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO order_status_history (order_id, previous_status, new_status, changed_by, notes)
+        VALUES ($1, $2, $3, $4, $5)
+    `, orderID, from, to, changedBy, notes)
+	return err
+}
+
+// CompletePaidOrder moves a fully paid order to completed inside tx when the status graph
+// has a move from its current status, logging it in the history. Orders the kitchen has
+// not finished are left as they are. It reports whether the order was completed.
+func CompletePaidOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, from string, changedBy uuid.UUID) (bool, error) {
+	if _, ok := orderTransitions[from][OrderStatusCompleted]; !ok {
+		return false, nil
+	}
+	notes := "Order completed after payment"
+	if err := setOrderStatus(ctx, tx, orderID, from, OrderStatusCompleted, changedBy, &notes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rollUpOrderStatus derives the order status from its item statuses inside tx: the first
+// item the kitchen starts moves the order to preparing, and once every item that was not
+// voided is ready (or served) the order moves to ready. A ready or served order that gets
+// open kitchen work again (an added item, a fired course, an item sent back) moves back to
+// preparing. Each move is logged in the history.
+func rollUpOrderStatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, current string, changedBy uuid.UUID) ([]StatusChange, error) {
+	var total, started, done int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT COUNT(*),
+               COUNT(*) FILTER (WHERE status <> 'pending'),
+               COUNT(*) FILTER (WHERE status IN ('ready', 'served'))
+        FROM order_items
+        WHERE order_id = $1 AND adjustment IS DISTINCT FROM 'void'
+    `, orderID).Scan(&total, &started, &done); err != nil {
+		return nil, err
+	}
+
+	var changes []StatusChange
+	status := current
+	if (status == OrderStatusReady || status == OrderStatusServed) && done < total {
+		changes = append(changes, StatusChange{From: status, To: OrderStatusPreparing})
+		status = OrderStatusPreparing
+	}
+	if (status == OrderStatusPending || status == OrderStatusConfirmed) && started > 0 {
+		changes = append(changes, StatusChange{From: status, To: OrderStatusPreparing})
+		status = OrderStatusPreparing
+	}
+	if status == OrderStatusPreparing && total > 0 && done == total {
+		changes = append(changes, StatusChange{From: status, To: OrderStatusReady})
+	}
+
+	notes := "Derived from item statuses"
+	for _, change := range changes {
+		if err := setOrderStatus(ctx, tx, orderID, change.From, change.To, changedBy, &notes); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// publishStatusChanges announces status moves once their transaction has committed
+func publishStatusChanges(orderID uuid.UUID, changes []StatusChange, changedBy uuid.UUID) {
+	for _, change := range changes {
+		events.Publish(events.OrderStatusChanged, orderID, nil, map[string]interface{}{
+			"previous_status": change.From,
+			"status":          change.To,
+			"changed_by":      changedBy,
+		})
+	}
+}
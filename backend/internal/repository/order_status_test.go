@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to, role string
+		want           bool
+	}{
+		{OrderStatusPending, OrderStatusConfirmed, "server", true},
+		{OrderStatusPending, OrderStatusConfirmed, "kitchen", false},
+		{OrderStatusPending, OrderStatusPreparing, "kitchen", true},
+		{OrderStatusPending, OrderStatusReady, "manager", false},
+		{OrderStatusPreparing, OrderStatusReady, "kitchen", true},
+		{OrderStatusPreparing, OrderStatusCancelled, "server", false},
+		{OrderStatusPreparing, OrderStatusCancelled, "manager", true},
+		{OrderStatusReady, OrderStatusPreparing, "kitchen", true},
+		{OrderStatusReady, OrderStatusCompleted, "counter", true},
+		{OrderStatusReady, OrderStatusCompleted, "server", false},
+		{OrderStatusServed, OrderStatusPreparing, "kitchen", true},
+		{OrderStatusServed, OrderStatusCompleted, "server", true},
+		{OrderStatusServed, OrderStatusCancelled, "manager", false},
+		{OrderStatusCompleted, OrderStatusPending, "manager", false},
+		{OrderStatusCancelled, OrderStatusPending, "admin", false},
+
+		// Admins may take any edge of the graph but cannot invent new ones
+		{OrderStatusPreparing, OrderStatusReady, "admin", true},
+		{OrderStatusPending, OrderStatusCompleted, "admin", false},
+
+		{"unknown", OrderStatusPending, "admin", false},
+		{OrderStatusPending, "unknown", "admin", false},
+		{OrderStatusPending, OrderStatusConfirmed, "", false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to, tt.role); got != tt.want {
+			t.Errorf("CanTransition(%q, %q, %q) = %v, want %v", tt.from, tt.to, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		from, role string
+		want       []string
+	}{
+		{OrderStatusPending, "server", []string{OrderStatusConfirmed, OrderStatusCancelled}},
+		{OrderStatusReady, "kitchen", []string{OrderStatusPreparing, OrderStatusServed}},
+		{OrderStatusReady, "admin", []string{OrderStatusPreparing, OrderStatusServed, OrderStatusCompleted, OrderStatusCancelled}},
+		{OrderStatusCompleted, "admin", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := AllowedTransitions(tt.from, tt.role); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllowedTransitions(%q, %q) = %v, want %v", tt.from, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalStatusesHaveNoTransitions(t *testing.T) {
+	for _, status := range []string{OrderStatusCompleted, OrderStatusCancelled} {
+		for _, to := range orderStatusOrder {
+			if CanTransition(status, to, "admin") {
+				t.Errorf("%s order can move to %s", status, to)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingPaymentTimeout is how long a card payment may stay pending. The gateway exchange
+// is bounded far below this, so an older pending payment was interrupted (e.g. the server
+// stopped mid-charge) and is expired rather than left to block the order.
+const PendingPaymentTimeout = 10 * time.Minute
+
+// ExpireStalePayments marks an order's pending payments older than PendingPaymentTimeout
+// failed inside tx and returns how many it expired. Each one is logged so it can be checked
+// against the processor's records, since an interrupted charge may still have gone through.
+func ExpireStalePayments(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+        UPDATE payments
+        SET status = 'failed', processed_at = CURRENT_TIMESTAMP
+        WHERE order_id = $1 AND status = 'pending' AND created_at < CURRENT_TIMESTAMP - $2::interval
+        RETURNING id
+    `, orderID, fmt.Sprintf("%d seconds", int(PendingPaymentTimeout.Seconds())))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	expired := 0
+	for rows.Next() {
+		var paymentID uuid.UUID
+		if err := rows.Scan(&paymentID); err != nil {
+			return expired, err
+		}
+		log.Printf("payment %s: expired after staying pending for over %s; check it with the processor", paymentID, PendingPaymentTimeout)
+		expired++
+	}
+	return expired, rows.Err()
+}
+
+// LockPayableOrder locks an order for taking a payment and returns its total and status.
+// Payments on the same order queue up behind the lock, so each one sees the balance the
+// previous one left. The lock is FOR NO KEY UPDATE so a pending payment row can still be
+// written outside the transaction while it is held.
+func LockPayableOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (float64, string, error) {
+	var total float64
+	var status string
+	err := tx.QueryRowContext(ctx, "SELECT total_amount, status FROM orders WHERE id = $1 FOR NO KEY UPDATE", orderID).Scan(&total, &status)
+	if err == sql.ErrNoRows {
+		return 0, "", fmt.Errorf("order_not_found: %w", err)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if _, err := ExpireStalePayments(ctx, tx, orderID); err != nil {
+		return 0, "", err
+	}
+	return total, status, nil
+}
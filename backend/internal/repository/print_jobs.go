@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Print job statuses
+const (
+	PrintJobPending  = "pending"
+	PrintJobPrinting = "printing"
+	PrintJobPrinted  = "printed"
+	PrintJobFailed   = "failed"
+)
+
+// PrintJobRepository defines printer lookup and the persistent print spool
+type PrintJobRepository interface {
+	ListPrinters(ctx context.Context, printerType string) ([]models.Printer, error)
+	GetPrinter(ctx context.Context, printerID uuid.UUID) (*models.Printer, error)
+	EnqueueJob(ctx context.Context, job models.PrintJob) (uuid.UUID, error)
+	ClaimDueJobs(ctx context.Context, limit int) ([]models.PrintJob, error)
+	CompleteJob(ctx context.Context, jobID uuid.UUID) error
+	FailJob(ctx context.Context, jobID uuid.UUID, errMsg string, retryAt *time.Time) error
+	ResetInterruptedJobs(ctx context.Context) (int, error)
+	ListJobs(ctx context.Context, status string, orderID *uuid.UUID, limit, offset int) ([]models.PrintJob, int, error)
+	RetryJob(ctx context.Context, jobID uuid.UUID) error
+}
+
+// PostgresPrintJobRepository is an implementation of PrintJobRepository using *sql.DB
+type PostgresPrintJobRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPrintJobRepository(db *sql.DB) *PostgresPrintJobRepository {
+	return &PostgresPrintJobRepository{db: db}
+}
+
+const printerSelect = `
+        SELECT pr.id, pr.name, pr.printer_type, pr.station_id, s.name, pr.connection_type, pr.address,
+               pr.chars_per_line, pr.is_active, pr.created_at, pr.updated_at
+        FROM printers pr
+        LEFT JOIN kitchen_stations s ON pr.station_id = s.id
+`
+
+func scanPrinter(row rowScanner) (models.Printer, error) {
+	var printer models.Printer
+	err := row.Scan(&printer.ID, &printer.Name, &printer.PrinterType, &printer.StationID, &printer.StationName,
+		&printer.ConnectionType, &printer.Address, &printer.CharsPerLine, &printer.IsActive,
+		&printer.CreatedAt, &printer.UpdatedAt)
+	return printer, err
+}
+
+// ListPrinters returns the active printers of a type (kitchen or receipt)
+func (r *PostgresPrintJobRepository) ListPrinters(ctx context.Context, printerType string) ([]models.Printer, error) {
+	rows, err := r.db.QueryContext(ctx, printerSelect+" WHERE pr.is_active = true AND pr.printer_type = $1 ORDER BY pr.name", printerType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	printers := []models.Printer{}
+	for rows.Next() {
+		printer, err := scanPrinter(rows)
+		if err != nil {
+			return nil, err
+		}
+		printers = append(printers, printer)
+	}
+	return printers, rows.Err()
+}
+
+func (r *PostgresPrintJobRepository) GetPrinter(ctx context.Context, printerID uuid.UUID) (*models.Printer, error) {
+	printer, err := scanPrinter(r.db.QueryRowContext(ctx, printerSelect+" WHERE pr.id = $1", printerID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("printer_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &printer, nil
+}
+
+// EnqueueJob stores a rendered job for the spool to deliver
+func (r *PostgresPrintJobRepository) EnqueueJob(ctx context.Context, job models.PrintJob) (uuid.UUID, error) {
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 5
+	}
+	var jobID uuid.UUID
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO print_jobs (printer_id, job_type, order_id, payload, max_attempts, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id
+    `, job.PrinterID, job.JobType, job.OrderID, job.Payload, job.MaxAttempts, job.CreatedBy).Scan(&jobID)
+	return jobID, err
+}
+
+// ClaimDueJobs marks up to limit due jobs as printing and returns them with their printer.
+// SKIP LOCKED lets several spool workers share the queue without sending a job twice.
+func (r *PostgresPrintJobRepository) ClaimDueJobs(ctx context.Context, limit int) ([]models.PrintJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        UPDATE print_jobs
+        SET status = 'printing', attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id IN (
+            SELECT id FROM print_jobs
+            WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+            ORDER BY created_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id, printer_id, job_type, order_id, payload, status, attempts, max_attempts,
+                  last_error, next_attempt_at, printed_at, created_by, created_at, updated_at
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.PrintJob
+	for rows.Next() {
+		var job models.PrintJob
+		if err := rows.Scan(&job.ID, &job.PrinterID, &job.JobType, &job.OrderID, &job.Payload, &job.Status,
+			&job.Attempts, &job.MaxAttempts, &job.LastError, &job.NextAttemptAt, &job.PrintedAt,
+			&job.CreatedBy, &job.CreatedAt, &job.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range jobs {
+		printer, err := r.GetPrinter(ctx, jobs[i].PrinterID)
+		if err != nil {
+			return nil, err
+		}
+		jobs[i].Printer = printer
+	}
+	return jobs, nil
+}
+
+func (r *PostgresPrintJobRepository) CompleteJob(ctx context.Context, jobID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE print_jobs
+        SET status = 'printed', printed_at = CURRENT_TIMESTAMP, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `, jobID)
+	return err
+}
+
+// FailJob records a delivery error. With retryAt the job goes back to the queue,
+// otherwise it is given up on and stays failed until retried by hand.
+func (r *PostgresPrintJobRepository) FailJob(ctx context.Context, jobID uuid.UUID, errMsg string, retryAt *time.Time) error {
+	if retryAt == nil {
+		_, err := r.db.ExecContext(ctx, `
+            UPDATE print_jobs SET status = 'failed', last_error = $1, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $2
+        `, errMsg, jobID)
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE print_jobs SET status = 'pending', last_error = $1, next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $3
+    `, errMsg, *retryAt, jobID)
+	return err
+}
+
+// ResetInterruptedJobs requeues jobs left printing by a worker that stopped mid-delivery
+func (r *PostgresPrintJobRepository) ResetInterruptedJobs(ctx context.Context) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE print_jobs SET status = 'pending', next_attempt_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE status = 'printing'
+    `)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := result.RowsAffected()
+	return int(count), nil
+}
+
+// ListJobs returns print jobs, newest first, with the total count
+func (r *PostgresPrintJobRepository) ListJobs(ctx context.Context, status string, orderID *uuid.UUID, limit, offset int) ([]models.PrintJob, int, error) {
+	where := " WHERE 1=1"
+	var args []interface{}
+	if status != "" {
+		args = append(args, status)
+		where += fmt.Sprintf(" AND j.status = $%d", len(args))
+	}
+	if orderID != nil {
+		args = append(args, *orderID)
+		where += fmt.Sprintf(" AND j.order_id = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM print_jobs j"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+        SELECT j.id, j.printer_id, j.job_type, j.order_id, j.status, j.attempts, j.max_attempts,
+               j.last_error, j.next_attempt_at, j.printed_at, j.created_by, j.created_at, j.updated_at,
+               pr.name, pr.printer_type
+        FROM print_jobs j
+        JOIN printers pr ON j.printer_id = pr.id
+        %s
+        ORDER BY j.created_at DESC
+        LIMIT $%d OFFSET $%d
+    `, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := []models.PrintJob{}
+	for rows.Next() {
+		var job models.PrintJob
+		printer := &models.Printer{}
+		if err := rows.Scan(&job.ID, &job.PrinterID, &job.JobType, &job.OrderID, &job.Status, &job.Attempts, &job.MaxAttempts,
+			&job.LastError, &job.NextAttemptAt, &job.PrintedAt, &job.CreatedBy, &job.CreatedAt, &job.UpdatedAt,
+			&printer.Name, &printer.PrinterType); err != nil {
+			return nil, 0, err
+		}
+		printer.ID = job.PrinterID
+		job.Printer = printer
+		jobs = append(jobs, job)
+	}
+	return jobs, total, rows.Err()
+}
+
+// RetryJob puts a failed job back in the queue with a fresh set of attempts
+func (r *PostgresPrintJobRepository) RetryJob(ctx context.Context, jobID uuid.UUID) error {
+	var status string
+	if err := r.db.QueryRowContext(ctx, "SELECT status FROM print_jobs WHERE id = $1", jobID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("print_job_not_found: %w", err)
+		}
+		return err
+	}
+	if status != PrintJobFailed {
+		return fmt.Errorf("print_job_not_failed: job is %s", status)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE print_jobs
+        SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `, jobID)
+	return err
+}
@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pricedLine is an order line as seen by the promotions engine
+type pricedLine struct {
+	ItemID     uuid.UUID
+	ProductID  uuid.UUID
+	CategoryID *uuid.UUID
+	Quantity   int
+	UnitPrice  float64
+	Amount     float64
+}
+
+const promotionColumns = `
+        p.id, p.name, p.description, p.promotion_type, p.scope, p.value,
+        p.product_id, p.category_id, p.buy_quantity, p.get_quantity, p.min_spend,
+        p.is_stackable, p.starts_at, p.ends_at, p.days_of_week,
+        to_char(p.start_time, 'HH24:MI:SS'), to_char(p.end_time, 'HH24:MI:SS'),
+        p.is_active, p.created_at, p.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row rowScanner, promotion *models.Promotion) error {
+	return row.Scan(
+		&promotion.ID, &promotion.Name, &promotion.Description, &promotion.PromotionType, &promotion.Scope, &promotion.Value,
+		&promotion.ProductID, &promotion.CategoryID, &promotion.BuyQuantity, &promotion.GetQuantity, &promotion.MinSpend,
+		&promotion.IsStackable, &promotion.StartsAt, &promotion.EndsAt, pq.Array(&promotion.DaysOfWeek),
+		&promotion.StartTime, &promotion.EndTime,
+		&promotion.IsActive, &promotion.CreatedAt, &promotion.UpdatedAt,
+	)
+}
+
+// promotionActiveAt reports whether a promotion's date range, weekdays and daily window include t
+func promotionActiveAt(p models.Promotion, t time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && t.After(*p.EndsAt) {
+		return false
+	}
+	if len(p.DaysOfWeek) > 0 {
+		matched := false
+		for _, day := range p.DaysOfWeek {
+			if int(t.Weekday()) == int(day) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if p.StartTime != nil && p.EndTime != nil {
+		now := t.Format("15:04:05")
+		start, end := *p.StartTime, *p.EndTime
+		if start <= end {
+			if now < start || now >= end {
+				return false
+			}
+		} else if now < start && now >= end {
+			// window crosses midnight, e.g. 22:00-02:00
+			return false
+		}
+	}
+	return true
+}
+
+// promotionApplies reports whether a line is eligible for an item or category promotion
+func promotionApplies(p models.Promotion, line pricedLine) bool {
+	switch p.Scope {
+	case "item":
+		return p.ProductID != nil && *p.ProductID == line.ProductID
+	case "category":
+		return p.CategoryID != nil && line.CategoryID != nil && *p.CategoryID == *line.CategoryID
+	default:
+		return true
+	}
+}
+
+// allocatePromotion returns the discount a promotion gives to each order line
+func allocatePromotion(p models.Promotion, lines []pricedLine) map[uuid.UUID]float64 {
+	allocation := map[uuid.UUID]float64{}
+
+	var eligible []pricedLine
+	var eligibleTotal float64
+	for _, line := range lines {
+		if line.Amount <= 0 || !promotionApplies(p, line) {
+			continue
+		}
+		eligible = append(eligible, line)
+		eligibleTotal += line.Amount
+	}
+	if eligibleTotal <= 0 {
+		return allocation
+	}
+
+	switch p.PromotionType {
+	case "percent":
+		for _, line := range eligible {
+			allocation[line.ItemID] = line.Amount * p.Value / 100
+		}
+	case "fixed":
+		if p.Scope == "order" {
+			// spread a flat order discount across lines by their share of the total
+			amount := p.Value
+			if amount > eligibleTotal {
+				amount = eligibleTotal
+			}
+			for _, line := range eligible {
+				allocation[line.ItemID] = amount * line.Amount / eligibleTotal
+			}
+		} else {
+			for _, line := range eligible {
+				amount := p.Value * float64(line.Quantity)
+				if amount > line.Amount {
+					amount = line.Amount
+				}
+				allocation[line.ItemID] = amount
+			}
+		}
+	case "buy_x_get_y":
+		if p.BuyQuantity == nil || p.GetQuantity == nil || *p.BuyQuantity <= 0 || *p.GetQuantity <= 0 {
+			return allocation
+		}
+		type unit struct {
+			itemID uuid.UUID
+			price  float64
+		}
+		var units []unit
+		for _, line := range eligible {
+			for i := 0; i < line.Quantity; i++ {
+				units = append(units, unit{itemID: line.ItemID, price: line.UnitPrice})
+			}
+		}
+		// the cheapest units are the free ones
+		sort.Slice(units, func(i, j int) bool { return units[i].price > units[j].price })
+		groupSize := *p.BuyQuantity + *p.GetQuantity
+		free := (len(units) / groupSize) * *p.GetQuantity
+		for _, u := range units[len(units)-free:] {
+			allocation[u.itemID] += u.price * p.Value / 100
+		}
+	}
+
+	return allocation
+}
+
+// ApplyDiscount applies a promotion to an open order after checking its window, minimum spend and stacking rules
+func (r *PostgresOrderRepository) ApplyDiscount(ctx context.Context, orderID, promotionID, appliedBy uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
+	var promotion models.Promotion
+	if err := scanPromotion(tx.QueryRowContext(ctx, "SELECT "+promotionColumns+" FROM promotions p WHERE p.id = $1", promotionID), &promotion); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("promotion_not_found: %w", err)
+		}
+		return uuid.Nil, err
+	}
+
+	// Happy-hour days and times are the store's, not the server's
+	settings, err := GetStoreSettings(ctx, tx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	now, err := StoreNow(settings)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !promotionActiveAt(promotion, now) {
+		return uuid.Nil, fmt.Errorf("promotion_not_active: %s is not available right now", promotion.Name)
+	}
+
+	var subtotal float64
+	if err := tx.QueryRowContext(ctx, "SELECT subtotal FROM orders WHERE id = $1", orderID).Scan(&subtotal); err != nil {
+		return uuid.Nil, err
+	}
+	if subtotal < promotion.MinSpend {
+		return uuid.Nil, fmt.Errorf("min_spend_not_met: %s requires a subtotal of at least %.2f", promotion.Name, promotion.MinSpend)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT promotion_id, is_stackable
+        FROM order_discounts
+        WHERE order_id = $1
+    `, orderID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	var existing []existingDiscount
+	for rows.Next() {
+		var d existingDiscount
+		if err := rows.Scan(&d.promotionID, &d.isStackable); err != nil {
+			rows.Close()
+			return uuid.Nil, err
+		}
+		existing = append(existing, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return uuid.Nil, err
+	}
+	if err := checkStacking(promotion, existing); err != nil {
+		return uuid.Nil, err
+	}
+
+	discountID := uuid.New()
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO order_discounts (id, order_id, promotion_id, promotion_name, amount, is_stackable, applied_by)
+        VALUES ($1, $2, $3, $4, 0, $5, $6)
+    `, discountID, orderID, promotionID, promotion.Name, promotion.IsStackable, appliedBy); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return uuid.Nil, err
+	}
+
+	var amount float64
+	if err := tx.QueryRowContext(ctx, "SELECT amount FROM order_discounts WHERE id = $1", discountID).Scan(&amount); err != nil {
+		return uuid.Nil, err
+	}
+	if amount <= 0 {
+		return uuid.Nil, fmt.Errorf("promotion_not_applicable: no items on this order qualify for %s", promotion.Name)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return discountID, nil
+}
+
+// existingDiscount is a discount already on an order, with the stacking rule it was applied under
+type existingDiscount struct {
+	promotionID uuid.NullUUID
+	isStackable bool
+}
+
+// checkStacking reports whether a promotion may join the discounts already on an order. The
+// stacking rule recorded on each discount counts, so deleting a promotion does not change it.
+func checkStacking(promotion models.Promotion, existing []existingDiscount) error {
+	for _, d := range existing {
+		if d.promotionID.Valid && d.promotionID.UUID == promotion.ID {
+			return fmt.Errorf("promotion_already_applied: %s is already on this order", promotion.Name)
+		}
+		if !d.isStackable || !promotion.IsStackable {
+			return fmt.Errorf("promotion_not_stackable: %s cannot be combined with other discounts", promotion.Name)
+		}
+	}
+	return nil
+}
+
+// RemoveDiscount removes an applied promotion from an open order
+func (r *PostgresOrderRepository) RemoveDiscount(ctx context.Context, orderID, discountID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM order_discounts WHERE id = $1 AND order_id = $2", discountID, orderID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return fmt.Errorf("discount_not_found: %w", sql.ErrNoRows)
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyOrderDiscounts recomputes every promotion applied to an order against its current lines.
+// It stores the new amounts and returns the discount given to each line.
+func applyOrderDiscounts(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, lines []pricedLine) (map[uuid.UUID]float64, float64, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT d.id, d.amount, `+promotionColumns+`
+        FROM order_discounts d
+        LEFT JOIN promotions p ON d.promotion_id = p.id
+        WHERE d.order_id = $1
+        ORDER BY d.created_at
+    `, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var applied []appliedDiscount
+	for rows.Next() {
+		var d appliedDiscount
+		var p models.Promotion
+		var promotionID uuid.NullUUID
+		var name, promotionType, scope sql.NullString
+		var value, minSpend sql.NullFloat64
+		var isStackable, isActive sql.NullBool
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&d.id, &d.amount,
+			&promotionID, &name, &p.Description, &promotionType, &scope, &value,
+			&p.ProductID, &p.CategoryID, &p.BuyQuantity, &p.GetQuantity, &minSpend,
+			&isStackable, &p.StartsAt, &p.EndsAt, pq.Array(&p.DaysOfWeek),
+			&p.StartTime, &p.EndTime,
+			&isActive, &createdAt, &updatedAt,
+		); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		if promotionID.Valid {
+			p.ID = promotionID.UUID
+			p.Name = name.String
+			p.PromotionType = promotionType.String
+			p.Scope = scope.String
+			p.Value = value.Float64
+			p.MinSpend = minSpend.Float64
+			d.promotion = &p
+		}
+		applied = append(applied, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	lineDiscounts := stackDiscounts(applied, lines)
+	var total float64
+	for _, d := range applied {
+		if _, err := tx.ExecContext(ctx, "UPDATE order_discounts SET amount = $1 WHERE id = $2", d.amount, d.id); err != nil {
+			return nil, 0, err
+		}
+		total += d.amount
+	}
+
+	return lineDiscounts, roundMoney(total), nil
+}
+
+// appliedDiscount is a discount on an order; promotion is nil once the promotion is deleted
+type appliedDiscount struct {
+	id        uuid.UUID
+	amount    float64
+	promotion *models.Promotion
+}
+
+// stackDiscounts re-prices applied discounts in the order they were applied, updating each
+// one's amount, and returns the discount given to each line. A deleted promotion keeps its
+// recorded amount, spread across the lines by what is left of them so it is taken off before
+// tax like any other discount. Later discounts only get what earlier ones left of a line, so
+// stacked discounts never take a line below zero.
+func stackDiscounts(applied []appliedDiscount, lines []pricedLine) map[uuid.UUID]float64 {
+	var subtotal float64
+	remaining := map[uuid.UUID]float64{}
+	for _, line := range lines {
+		subtotal += line.Amount
+		remaining[line.ItemID] = line.Amount
+	}
+
+	lineDiscounts := map[uuid.UUID]float64{}
+	for i := range applied {
+		d := &applied[i]
+		if d.promotion == nil {
+			var left float64
+			for _, line := range lines {
+				left += remaining[line.ItemID]
+			}
+			amount := d.amount
+			if amount > left {
+				amount = left
+				d.amount = roundMoney(left)
+			}
+			if amount <= 0 {
+				continue
+			}
+			for _, line := range lines {
+				discount := amount * remaining[line.ItemID] / left
+				remaining[line.ItemID] -= discount
+				lineDiscounts[line.ItemID] += discount
+			}
+			continue
+		}
+		amount := 0.0
+		if subtotal >= d.promotion.MinSpend {
+			for itemID, discount := range allocatePromotion(*d.promotion, lines) {
+				if discount > remaining[itemID] {
+					discount = remaining[itemID]
+				}
+				remaining[itemID] -= discount
+				lineDiscounts[itemID] += discount
+				amount += discount
+			}
+		}
+		d.amount = roundMoney(amount)
+	}
+	return lineDiscounts
+}
+
+func (r *PostgresOrderRepository) loadOrderDiscounts(ctx context.Context, order *models.Order) error {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, promotion_id, promotion_name, amount, applied_by, created_at
+        FROM order_discounts
+        WHERE order_id = $1
+        ORDER BY created_at
+    `, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var discounts []models.OrderDiscount
+	for rows.Next() {
+		var discount models.OrderDiscount
+		if err := rows.Scan(&discount.ID, &discount.PromotionID, &discount.PromotionName,
+			&discount.Amount, &discount.AppliedBy, &discount.CreatedAt); err != nil {
+			return err
+		}
+		discount.OrderID = order.ID
+		discounts = append(discounts, discount)
+	}
+
+	order.Discounts = discounts
+	return rows.Err()
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func intPtr(i int) *int { return &i }
+
+func stringPtr(s string) *string { return &s }
+
+func newUUIDPtr() *uuid.UUID {
+	id := uuid.New()
+	return &id
+}
+
+// approxEqual compares money amounts that have not been rounded yet
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPromotionActiveAt(t *testing.T) {
+	// 2026-10-16 is a Friday
+	at := func(clock string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", "2026-10-16 "+clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	yesterday := at("12:00").AddDate(0, 0, -1)
+	tomorrow := at("12:00").AddDate(0, 0, 1)
+
+	tests := []struct {
+		name  string
+		promo models.Promotion
+		at    time.Time
+		want  bool
+	}{
+		{"always on", models.Promotion{IsActive: true}, at("12:00"), true},
+		{"inactive", models.Promotion{}, at("12:00"), false},
+		{"not started", models.Promotion{IsActive: true, StartsAt: &tomorrow}, at("12:00"), false},
+		{"ended", models.Promotion{IsActive: true, EndsAt: &yesterday}, at("12:00"), false},
+		{"within dates", models.Promotion{IsActive: true, StartsAt: &yesterday, EndsAt: &tomorrow}, at("12:00"), true},
+		{"on its weekday", models.Promotion{IsActive: true, DaysOfWeek: []int64{5, 6}}, at("12:00"), true},
+		{"off its weekdays", models.Promotion{IsActive: true, DaysOfWeek: []int64{0, 1}}, at("12:00"), false},
+		{"inside happy hour", models.Promotion{IsActive: true, StartTime: stringPtr("16:00:00"), EndTime: stringPtr("18:00:00")}, at("16:00"), true},
+		{"happy hour ends exclusive", models.Promotion{IsActive: true, StartTime: stringPtr("16:00:00"), EndTime: stringPtr("18:00:00")}, at("18:00"), false},
+		{"late window before midnight", models.Promotion{IsActive: true, StartTime: stringPtr("22:00:00"), EndTime: stringPtr("02:00:00")}, at("23:30"), true},
+		{"late window after midnight", models.Promotion{IsActive: true, StartTime: stringPtr("22:00:00"), EndTime: stringPtr("02:00:00")}, at("01:30"), true},
+		{"outside late window", models.Promotion{IsActive: true, StartTime: stringPtr("22:00:00"), EndTime: stringPtr("02:00:00")}, at("12:00"), false},
+	}
+
+	for _, tt := range tests {
+		if got := promotionActiveAt(tt.promo, tt.at); got != tt.want {
+			t.Errorf("%s: promotionActiveAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPromotionActiveAtUsesTheGivenZone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	promo := models.Promotion{IsActive: true, StartTime: stringPtr("17:00:00"), EndTime: stringPtr("19:00:00")}
+
+	// 09:00 UTC is 18:00 in Tokyo
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if promotionActiveAt(promo, now) {
+		t.Error("window matched the UTC clock")
+	}
+	if !promotionActiveAt(promo, now.In(tokyo)) {
+		t.Error("window did not match the store's clock")
+	}
+}
+
+func TestAllocatePromotion(t *testing.T) {
+	drinks := uuid.New()
+	burger, beer, wine := uuid.New(), uuid.New(), uuid.New()
+	lines := []pricedLine{
+		{ItemID: uuid.New(), ProductID: burger, Quantity: 1, UnitPrice: 15, Amount: 15},
+		{ItemID: uuid.New(), ProductID: beer, CategoryID: &drinks, Quantity: 3, UnitPrice: 5, Amount: 15},
+		{ItemID: uuid.New(), ProductID: wine, CategoryID: &drinks, Quantity: 1, UnitPrice: 8, Amount: 8},
+		{ItemID: uuid.New(), ProductID: burger, Quantity: 1, UnitPrice: 15, Amount: 0}, // comped
+	}
+	burgerLine, beerLine, wineLine, compedLine := lines[0].ItemID, lines[1].ItemID, lines[2].ItemID, lines[3].ItemID
+
+	tests := []struct {
+		name  string
+		promo models.Promotion
+		want  map[uuid.UUID]float64
+	}{
+		{
+			name:  "percent off the order",
+			promo: models.Promotion{PromotionType: "percent", Scope: "order", Value: 10},
+			want:  map[uuid.UUID]float64{burgerLine: 1.5, beerLine: 1.5, wineLine: 0.8},
+		},
+		{
+			name:  "percent off a category",
+			promo: models.Promotion{PromotionType: "percent", Scope: "category", CategoryID: &drinks, Value: 50},
+			want:  map[uuid.UUID]float64{beerLine: 7.5, wineLine: 4},
+		},
+		{
+			name:  "fixed order discount is spread by line share",
+			promo: models.Promotion{PromotionType: "fixed", Scope: "order", Value: 3.8},
+			want:  map[uuid.UUID]float64{burgerLine: 1.5, beerLine: 1.5, wineLine: 0.8},
+		},
+		{
+			name:  "fixed order discount is capped at the order",
+			promo: models.Promotion{PromotionType: "fixed", Scope: "order", Value: 100},
+			want:  map[uuid.UUID]float64{burgerLine: 15, beerLine: 15, wineLine: 8},
+		},
+		{
+			name:  "fixed item discount applies per unit",
+			promo: models.Promotion{PromotionType: "fixed", Scope: "item", ProductID: &beer, Value: 2},
+			want:  map[uuid.UUID]float64{beerLine: 6},
+		},
+		{
+			name:  "fixed item discount is capped at the line",
+			promo: models.Promotion{PromotionType: "fixed", Scope: "item", ProductID: &wine, Value: 10},
+			want:  map[uuid.UUID]float64{wineLine: 8},
+		},
+		{
+			// Four drinks make one buy 3 get 1 group; the cheapest unit, a beer, is free
+			name: "buy x get y frees the cheapest units",
+			promo: models.Promotion{PromotionType: "buy_x_get_y", Scope: "category", CategoryID: &drinks,
+				BuyQuantity: intPtr(3), GetQuantity: intPtr(1), Value: 100},
+			want: map[uuid.UUID]float64{beerLine: 5},
+		},
+		{
+			name: "buy x get y needs a full group",
+			promo: models.Promotion{PromotionType: "buy_x_get_y", Scope: "item", ProductID: &beer,
+				BuyQuantity: intPtr(3), GetQuantity: intPtr(1), Value: 100},
+			want: map[uuid.UUID]float64{},
+		},
+		{
+			name:  "nothing eligible",
+			promo: models.Promotion{PromotionType: "percent", Scope: "item", ProductID: newUUIDPtr(), Value: 10},
+			want:  map[uuid.UUID]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		got := allocatePromotion(tt.promo, lines)
+		if _, ok := got[compedLine]; ok {
+			t.Errorf("%s: discounted a comped line", tt.name)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: allocated to %d lines, want %d: %v", tt.name, len(got), len(tt.want), got)
+			continue
+		}
+		for itemID, want := range tt.want {
+			if !approxEqual(got[itemID], want) {
+				t.Errorf("%s: line discount = %v, want %v", tt.name, got[itemID], want)
+			}
+		}
+	}
+}
+
+func TestStackDiscounts(t *testing.T) {
+	line := pricedLine{ItemID: uuid.New(), ProductID: uuid.New(), Quantity: 1, UnitPrice: 20, Amount: 20}
+	lines := []pricedLine{line}
+
+	halfOff := &models.Promotion{PromotionType: "percent", Scope: "order", Value: 50, IsStackable: true}
+	fifteenOff := &models.Promotion{PromotionType: "fixed", Scope: "order", Value: 15, IsStackable: true}
+	bigSpend := &models.Promotion{PromotionType: "percent", Scope: "order", Value: 10, MinSpend: 50}
+
+	t.Run("later discounts get what is left of a line", func(t *testing.T) {
+		applied := []appliedDiscount{{promotion: halfOff}, {promotion: fifteenOff}}
+		got := stackDiscounts(applied, lines)
+		if applied[0].amount != 10 || applied[1].amount != 10 {
+			t.Errorf("amounts = %v, %v, want 10, 10", applied[0].amount, applied[1].amount)
+		}
+		if got[line.ItemID] != 20 {
+			t.Errorf("line discount = %v, want 20", got[line.ItemID])
+		}
+	})
+
+	t.Run("minimum spend not met", func(t *testing.T) {
+		applied := []appliedDiscount{{amount: 2, promotion: bigSpend}}
+		got := stackDiscounts(applied, lines)
+		if applied[0].amount != 0 || got[line.ItemID] != 0 {
+			t.Errorf("amount = %v, line discount = %v, want 0", applied[0].amount, got[line.ItemID])
+		}
+	})
+
+	t.Run("deleted promotion keeps its amount", func(t *testing.T) {
+		applied := []appliedDiscount{{amount: 4}, {promotion: halfOff}}
+		got := stackDiscounts(applied, lines)
+		if applied[0].amount != 4 || applied[1].amount != 10 {
+			t.Errorf("amounts = %v, %v, want 4, 10", applied[0].amount, applied[1].amount)
+		}
+		if !approxEqual(got[line.ItemID], 14) {
+			t.Errorf("line discount = %v, want 14", got[line.ItemID])
+		}
+	})
+
+	t.Run("deleted promotion is spread across the lines before tax", func(t *testing.T) {
+		burger := pricedLine{ItemID: uuid.New(), ProductID: uuid.New(), Quantity: 1, UnitPrice: 30, Amount: 30}
+		salad := pricedLine{ItemID: uuid.New(), ProductID: uuid.New(), Quantity: 1, UnitPrice: 10, Amount: 10}
+		comped := pricedLine{ItemID: uuid.New(), ProductID: uuid.New(), Quantity: 1, UnitPrice: 10, Amount: 0}
+		applied := []appliedDiscount{{amount: 8}}
+		got := stackDiscounts(applied, []pricedLine{burger, salad, comped})
+		if applied[0].amount != 8 {
+			t.Errorf("amount = %v, want 8", applied[0].amount)
+		}
+		if !approxEqual(got[burger.ItemID], 6) || !approxEqual(got[salad.ItemID], 2) || got[comped.ItemID] != 0 {
+			t.Errorf("line discounts = %v, %v, %v, want 6, 2, 0", got[burger.ItemID], got[salad.ItemID], got[comped.ItemID])
+		}
+	})
+
+	t.Run("deleted promotion is capped at what is left", func(t *testing.T) {
+		applied := []appliedDiscount{{promotion: fifteenOff}, {amount: 10}}
+		got := stackDiscounts(applied, lines)
+		if applied[0].amount != 15 || applied[1].amount != 5 {
+			t.Errorf("amounts = %v, %v, want 15, 5", applied[0].amount, applied[1].amount)
+		}
+		if !approxEqual(got[line.ItemID], 20) {
+			t.Errorf("line discount = %v, want 20", got[line.ItemID])
+		}
+	})
+}
+
+func TestCheckStacking(t *testing.T) {
+	stackable := models.Promotion{ID: uuid.New(), Name: "Happy hour", IsStackable: true}
+	exclusive := models.Promotion{ID: uuid.New(), Name: "Staff meal"}
+	deleted := uuid.NullUUID{}
+
+	tests := []struct {
+		name      string
+		promotion models.Promotion
+		existing  []existingDiscount
+		want      string // error code, empty when allowed
+	}{
+		{"first discount", exclusive, nil, ""},
+		{"stackable on stackable", stackable, []existingDiscount{{uuid.NullUUID{UUID: uuid.New(), Valid: true}, true}}, ""},
+		{"already applied", stackable, []existingDiscount{{uuid.NullUUID{UUID: stackable.ID, Valid: true}, true}}, "promotion_already_applied"},
+		{"exclusive on stackable", exclusive, []existingDiscount{{uuid.NullUUID{UUID: uuid.New(), Valid: true}, true}}, "promotion_not_stackable"},
+		{"stackable on exclusive", stackable, []existingDiscount{{uuid.NullUUID{UUID: uuid.New(), Valid: true}, false}}, "promotion_not_stackable"},
+		{"deleted stackable promotion does not block", stackable, []existingDiscount{{deleted, true}}, ""},
+		{"deleted exclusive promotion still blocks", stackable, []existingDiscount{{deleted, false}}, "promotion_not_stackable"},
+	}
+
+	for _, tt := range tests {
+		err := checkStacking(tt.promotion, tt.existing)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want+":")):
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
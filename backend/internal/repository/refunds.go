@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// OrderNetPaid returns what has been paid on an order after refunds. Fully refunded
+// payments keep their amount and are cancelled out by their refunds.
+func OrderNetPaid(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (float64, error) {
+	var paid float64
+	err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(SUM(amount), 0) - COALESCE((SELECT SUM(amount) FROM refunds WHERE order_id = $1 AND status = 'completed'), 0)
+        FROM payments
+        WHERE order_id = $1 AND status IN ('completed', 'refunded')
+    `, orderID).Scan(&paid)
+	return paid, err
+}
+
+// Refund statuses. A refund is pending while the processor is asked to return the money.
+const (
+	RefundPending   = "pending"
+	RefundCompleted = "completed"
+	RefundFailed    = "failed"
+)
+
+// RefundSettler returns refunded money through the processor that took the payment
+// and gives back the processor's refund reference, if any
+type RefundSettler func(ctx context.Context, paymentMethod string, reference *string, amount float64) (*string, error)
+
+// RefundPayment returns amount (or everything not yet refunded when amount is nil) from a
+// completed payment. A payment refunded in full is marked refunded. With reopen, a
+// completed order goes back to served so the balance can be collected again; otherwise
+// it stays completed and the refund only reduces what was taken for it.
+//
+// The refund is recorded as pending and committed before settle runs, so money the
+// processor returns is never left without a record. It is then completed, or marked failed
+// when the processor rejects it. Cash is paid out of drawerSessionID when given, or else
+// the refunding user's own open drawer session.
+// It returns the refund and the order status afterwards.
+func RefundPayment(ctx context.Context, db *sql.DB, orderID, paymentID uuid.UUID, amount *float64, reason string, reopen bool, drawerSessionID *uuid.UUID, refundedBy, approvedBy uuid.UUID, settle RefundSettler) (*models.Refund, string, error) {
+	refund, paymentMethod, paymentReference, err := startRefund(ctx, db, orderID, paymentID, amount, reason, reopen, drawerSessionID, refundedBy, approvedBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if settle != nil {
+		if refund.ReferenceNumber, err = settle(ctx, paymentMethod, paymentReference, refund.Amount); err != nil {
+			if _, dbErr := db.ExecContext(context.Background(), "UPDATE refunds SET status = $1 WHERE id = $2", RefundFailed, refund.ID); dbErr != nil {
+				log.Printf("refund %s: failed to mark refund failed: %v", refund.ID, dbErr)
+			}
+			return nil, "", err
+		}
+	}
+
+	orderStatus, fullyRefunded, netPaid, reopened, err := completeRefund(ctx, db, refund, reopen)
+	if err != nil {
+		// The processor has returned the money; the pending refund is the record of it
+		log.Printf("refund %s: settled with reference %v but could not be completed: %v", refund.ID, refund.ReferenceNumber, err)
+		return nil, "", err
+	}
+	refund.Status = RefundCompleted
+
+	events.Publish(events.PaymentRefunded, orderID, nil, map[string]interface{}{
+		"refund_id":      refund.ID,
+		"payment_id":     paymentID,
+		"amount":         refund.Amount,
+		"fully_refunded": fullyRefunded,
+		"total_paid":     netPaid,
+	})
+	if reopened {
+		publishStatusChanges(orderID, []StatusChange{{From: OrderStatusCompleted, To: OrderStatusServed}}, refundedBy)
+	}
+
+	return refund, orderStatus, nil
+}
+
+// startRefund checks a refund against the order and payment and records it as pending.
+// Pending refunds count against what is left to refund, so two refunds of the same payment
+// cannot both go through. It also returns the payment's method and processor reference.
+func startRefund(ctx context.Context, db *sql.DB, orderID, paymentID uuid.UUID, amount *float64, reason string, reopen bool, drawerSessionID *uuid.UUID, refundedBy, approvedBy uuid.UUID) (*models.Refund, string, *string, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer tx.Rollback()
+
+	var orderStatus string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", orderID).Scan(&orderStatus)
+	if err == sql.ErrNoRows {
+		return nil, "", nil, fmt.Errorf("order_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+	// Orders from a closed business day can still be refunded but not reopened
+	if reopen && orderStatus == OrderStatusCompleted {
+		if err := checkBusinessDayOpen(ctx, tx, orderID); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	var paymentAmount, refunded float64
+	var paymentStatus, paymentMethod string
+	var paymentReference *string
+	err = tx.QueryRowContext(ctx, `
+        SELECT p.amount, p.status, p.payment_method, p.reference_number,
+               COALESCE((SELECT SUM(amount) FROM refunds WHERE payment_id = p.id AND status <> 'failed'), 0)
+        FROM payments p
+        WHERE p.id = $1 AND p.order_id = $2
+        FOR UPDATE OF p
+    `, paymentID, orderID).Scan(&paymentAmount, &paymentStatus, &paymentMethod, &paymentReference, &refunded)
+	if err == sql.ErrNoRows {
+		return nil, "", nil, fmt.Errorf("payment_not_found: %w", err)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if paymentStatus != "completed" {
+		return nil, "", nil, fmt.Errorf("payment_not_refundable: payment is %s", paymentStatus)
+	}
+
+	refundable := paymentAmount - refunded
+	refundAmount := refundable
+	if amount != nil {
+		refundAmount = *amount
+	}
+	if refundAmount > refundable+0.005 {
+		return nil, "", nil, fmt.Errorf("refund_exceeds_payment: at most %.2f can be refunded", refundable)
+	}
+	if refundAmount < 0.005 {
+		return nil, "", nil, fmt.Errorf("refund_exceeds_payment: nothing is left to refund")
+	}
+
+	refund := models.Refund{
+		OrderID:    orderID,
+		PaymentID:  paymentID,
+		Amount:     refundAmount,
+		Reason:     reason,
+		Status:     RefundPending,
+		RefundedBy: &refundedBy,
+		ApprovedBy: &approvedBy,
+	}
+	if paymentMethod == "cash" {
+		if refund.DrawerSessionID, err = refundDrawerSession(ctx, tx, drawerSessionID, refundedBy); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO refunds (order_id, payment_id, amount, reason, status, drawer_session_id, refunded_by, approved_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `, orderID, paymentID, refundAmount, reason, RefundPending, refund.DrawerSessionID, refundedBy, approvedBy).Scan(&refund.ID, &refund.CreatedAt); err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", nil, err
+	}
+	return &refund, paymentMethod, paymentReference, nil
+}
+
+// completeRefund marks a settled refund completed, marks its payment refunded once nothing
+// is left of it and, with reopen, puts a completed order back to served. It returns the
+// order status, whether the payment is fully refunded, what is paid on the order after the
+// refund and whether the order was reopened.
+func completeRefund(ctx context.Context, db *sql.DB, refund *models.Refund, reopen bool) (string, bool, float64, bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", false, 0, false, err
+	}
+	defer tx.Rollback()
+
+	var orderStatus string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", refund.OrderID).Scan(&orderStatus); err != nil {
+		return "", false, 0, false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE refunds SET status = $1, reference_number = $2 WHERE id = $3
+    `, RefundCompleted, refund.ReferenceNumber, refund.ID); err != nil {
+		return "", false, 0, false, err
+	}
+
+	var paymentAmount, refunded float64
+	if err := tx.QueryRowContext(ctx, `
+        SELECT p.amount, COALESCE((SELECT SUM(amount) FROM refunds WHERE payment_id = p.id AND status = 'completed'), 0)
+        FROM payments p
+        WHERE p.id = $1
+        FOR UPDATE
+    `, refund.PaymentID).Scan(&paymentAmount, &refunded); err != nil {
+		return "", false, 0, false, err
+	}
+	fullyRefunded := refunded >= paymentAmount-0.005
+	if fullyRefunded {
+		if _, err := tx.ExecContext(ctx, "UPDATE payments SET status = 'refunded' WHERE id = $1", refund.PaymentID); err != nil {
+			return "", false, 0, false, err
+		}
+	}
+
+	reopened := reopen && orderStatus == OrderStatusCompleted
+	if reopened {
+		notes := "Reopened after refund: " + refund.Reason
+		if err := setOrderStatus(ctx, tx, refund.OrderID, OrderStatusCompleted, OrderStatusServed, *refund.RefundedBy, &notes); err != nil {
+			return "", false, 0, false, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE orders SET completed_at = NULL WHERE id = $1", refund.OrderID); err != nil {
+			return "", false, 0, false, err
+		}
+		orderStatus = OrderStatusServed
+	}
+
+	netPaid, err := OrderNetPaid(ctx, tx, refund.OrderID)
+	if err != nil {
+		return "", false, 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", false, 0, false, err
+	}
+	return orderStatus, fullyRefunded, netPaid, reopened, nil
+}
+
+// refundDrawerSession picks the drawer session a cash refund is paid out of: the one asked
+// for, which has to be open, or else the refunding user's own open session
+func refundDrawerSession(ctx context.Context, tx *sql.Tx, requested *uuid.UUID, refundedBy uuid.UUID) (*uuid.UUID, error) {
+	if requested != nil {
+		var status string
+		err := tx.QueryRowContext(ctx, "SELECT status FROM drawer_sessions WHERE id = $1", *requested).Scan(&status)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("drawer_session_not_found: %w", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status != DrawerOpen {
+			return nil, fmt.Errorf("drawer_not_open: drawer session is %s", status)
+		}
+		return requested, nil
+	}
+
+	var sessionID uuid.UUID
+	err := tx.QueryRowContext(ctx, `
+        SELECT id FROM drawer_sessions
+        WHERE status = 'open' AND opened_by = $1
+        ORDER BY opened_at DESC
+        LIMIT 1
+    `, refundedBy).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("drawer_not_open: open a drawer session or choose the one to pay the refund out of")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sessionID, nil
+}
+
+// ListRefunds returns the refunds made on an order, newest first
+func ListRefunds(ctx context.Context, db *sql.DB, orderID uuid.UUID) ([]models.Refund, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT r.id, r.order_id, r.payment_id, r.amount, r.reason, r.status, r.reference_number, r.drawer_session_id,
+               r.refunded_by, r.approved_by, r.created_at,
+               u.username, u.first_name, u.last_name
+        FROM refunds r
+        LEFT JOIN users u ON r.refunded_by = u.id
+        WHERE r.order_id = $1
+        ORDER BY r.created_at DESC
+    `, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []models.Refund{}
+	for rows.Next() {
+		var refund models.Refund
+		var username, firstName, lastName sql.NullString
+		if err := rows.Scan(&refund.ID, &refund.OrderID, &refund.PaymentID, &refund.Amount, &refund.Reason, &refund.Status, &refund.ReferenceNumber,
+			&refund.DrawerSessionID, &refund.RefundedBy, &refund.ApprovedBy, &refund.CreatedAt, &username, &firstName, &lastName); err != nil {
+			return nil, err
+		}
+		if username.Valid {
+			refund.RefundedByUser = &models.User{
+				Username:  username.String,
+				FirstName: firstName.String,
+				LastName:  lastName.String,
+			}
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// orderServiceCharge finds the service charge for an order inside tx and prices it on base,
+// the subtotal after discounts. Party size is the order's guest count, falling back to the
+// seating capacity of its table. When several rules match, the one for the largest parties
+// wins, so tiers like 18% from 8 guests and 20% from 12 do not stack.
+func orderServiceCharge(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, orderType string, base float64) (*models.ServiceCharge, error) {
+	var partySize int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(o.guest_count, t.seating_capacity, 1)
+        FROM orders o
+        LEFT JOIN dining_tables t ON o.table_id = t.id
+        WHERE o.id = $1
+    `, orderID).Scan(&partySize); err != nil {
+		return nil, err
+	}
+
+	var charge models.ServiceCharge
+	err := tx.QueryRowContext(ctx, `
+        SELECT name, rate
+        FROM service_charge_rules
+        WHERE is_active = true AND min_party_size <= $1 AND (order_type IS NULL OR order_type = $2)
+        ORDER BY min_party_size DESC, rate DESC
+        LIMIT 1
+    `, partySize, orderType).Scan(&charge.Name, &charge.Rate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if base < 0 {
+		base = 0
+	}
+	charge.Amount = roundMoney(base * charge.Rate)
+	return &charge, nil
+}
+
+// saveOrderServiceCharge stores the applied service charge on the order inside tx
+func saveOrderServiceCharge(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, charge *models.ServiceCharge) error {
+	var name *string
+	var rate *float64
+	var amount float64
+	if charge != nil {
+		name, rate, amount = &charge.Name, &charge.Rate, charge.Amount
+	}
+	_, err := tx.ExecContext(ctx, `
+        UPDATE orders SET service_charge_name = $1, service_charge_rate = $2, service_charge_amount = $3
+        WHERE id = $4
+    `, name, rate, amount, orderID)
+	return err
+}
+
+// SetGuestCount records how many guests an order is for and reprices its service charge
+func (r *PostgresOrderRepository) SetGuestCount(ctx context.Context, orderID uuid.UUID, guestCount int) error {
+	if guestCount < 1 {
+		return fmt.Errorf("invalid_guest_count: guest count must be at least 1")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockEditableOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE orders SET guest_count = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+    `, guestCount, orderID); err != nil {
+		return err
+	}
+
+	if err := recalculateOrderTotals(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// scannedServiceCharge builds an order's service charge from its nullable columns
+func scannedServiceCharge(name sql.NullString, rate sql.NullFloat64, amount float64) *models.ServiceCharge {
+	if !name.Valid || amount <= 0 {
+		return nil
+	}
+	return &models.ServiceCharge{Name: name.String, Rate: rate.Float64, Amount: amount}
+}
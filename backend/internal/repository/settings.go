@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+	_ "time/tzdata" // the store's time zone must load even where the host has no zoneinfo
+
+	"pos-backend/internal/models"
+)
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// GetStoreSettings returns the store-wide settings, falling back to defaults when none are saved
+func GetStoreSettings(ctx context.Context, q rowQuerier) (models.StoreSettings, error) {
+	settings := models.StoreSettings{CashRounding: 0.01, TimeZone: "UTC", DayCutoff: "00:00"}
+	err := q.QueryRowContext(ctx, `
+        SELECT cash_rounding, time_zone, to_char(day_cutoff, 'HH24:MI'), updated_at
+        FROM store_settings WHERE id = true
+    `).Scan(&settings.CashRounding, &settings.TimeZone, &settings.DayCutoff, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	return settings, err
+}
+
+// CurrentBusinessDate returns today's business date (YYYY-MM-DD) in the store's time zone.
+// Until the day cutoff it is still the previous day.
+func CurrentBusinessDate(ctx context.Context, q rowQuerier, settings models.StoreSettings) (string, error) {
+	var date string
+	err := q.QueryRowContext(ctx, `
+        SELECT ((CURRENT_TIMESTAMP AT TIME ZONE $1) - $2::interval)::date::text
+    `, settings.TimeZone, settings.DayCutoff).Scan(&date)
+	return date, err
+}
+
+// BusinessDayBounds returns when the business days from through to (YYYY-MM-DD, inclusive)
+// open and close in the store's time zone. Each day opens at the day cutoff on its own
+// date, so a period crossing a daylight saving change is an hour shorter or longer.
+func BusinessDayBounds(settings models.StoreSettings, from, to string) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid_time_zone: %w", err)
+	}
+	cutoff, err := time.Parse("15:04", settings.DayCutoff)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid_day_cutoff: %w", err)
+	}
+	first, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	last, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	opens := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), cutoff.Hour(), cutoff.Minute(), 0, 0, loc)
+	}
+	return opens(first), opens(last.AddDate(0, 0, 1)), nil
+}
+
+// StoreNow returns the current time in the store's time zone, so weekdays and clock times
+// read from it are the store's rather than the server's
+func StoreNow(settings models.StoreSettings) (time.Time, error) {
+	loc, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid_time_zone: %w", err)
+	}
+	return time.Now().In(loc), nil
+}
+
+// IsValidTimeZone reports whether name is a time zone the database knows
+func IsValidTimeZone(ctx context.Context, q rowQuerier, name string) (bool, error) {
+	var valid bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_timezone_names WHERE name = $1)", name).Scan(&valid)
+	return valid, err
+}
+
+// RoundCash rounds amount to the nearest multiple of increment, the smallest coin in use
+func RoundCash(amount, increment float64) float64 {
+	if increment <= 0.01 {
+		return roundMoney(amount)
+	}
+	return roundMoney(math.Round(amount/increment) * increment)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+
+	"pos-backend/internal/models"
+)
+
+func TestRoundCash(t *testing.T) {
+	tests := []struct {
+		amount, increment, want float64
+	}{
+		{12.47, 0.01, 12.47},
+		{12.474, 0, 12.47},
+		{12.47, 0.05, 12.45},
+		{12.48, 0.05, 12.5},
+		{12.43, 0.10, 12.4},
+		{12.46, 0.10, 12.5},
+		{12.4, 0.25, 12.5},
+		{-3.33, 0.05, -3.35},
+		{0.02, 0.05, 0},
+	}
+	for _, tt := range tests {
+		if got := RoundCash(tt.amount, tt.increment); got != tt.want {
+			t.Errorf("RoundCash(%v, %v) = %v, want %v", tt.amount, tt.increment, got, tt.want)
+		}
+	}
+}
+
+func TestBusinessDayBoundsRejectsBadSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.StoreSettings
+		from     string
+	}{
+		{"unknown time zone", models.StoreSettings{TimeZone: "Mars/Olympus", DayCutoff: "00:00"}, "2026-10-16"},
+		{"bad cutoff", models.StoreSettings{TimeZone: "UTC", DayCutoff: "4am"}, "2026-10-16"},
+		{"bad date", models.StoreSettings{TimeZone: "UTC", DayCutoff: "00:00"}, "2026-13-01"},
+	}
+	for _, tt := range tests {
+		if start, end, err := BusinessDayBounds(tt.settings, tt.from, tt.from); err == nil {
+			t.Errorf("%s: bounds %v to %v, want an error", tt.name, start, end)
+		}
+	}
+}
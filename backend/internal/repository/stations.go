@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"pos-backend/internal/events"
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// itemStationQuery resolves the station for the product bound to $3 of an order item
+// insert: the product's own station wins over its category's station
+const itemStationQuery = `(
+            SELECT COALESCE(p.station_id, c.station_id)
+            FROM products p
+            LEFT JOIN categories c ON p.category_id = c.id
+            WHERE p.id = $3)`
+
+// openKitchenItem matches order items a station still has to prepare
+const openKitchenItem = `oi.status IN ('pending', 'preparing') AND oi.adjustment IS DISTINCT FROM 'void'`
+
+// firedKitchenItem narrows openKitchenItem to items whose course has been fired
+const firedKitchenItem = openKitchenItem + ` AND oi.is_held = false`
+
+// KitchenRepository defines the station ticket view and bump operations for the kitchen
+type KitchenRepository interface {
+	GetStation(ctx context.Context, stationID uuid.UUID) (*models.KitchenStation, error)
+	ListStationTickets(ctx context.Context, stationID uuid.UUID) ([]models.StationTicket, error)
+	BumpStationTicket(ctx context.Context, stationID, orderID, changedBy uuid.UUID) (string, error)
+	UpdateItemStatus(ctx context.Context, orderID, itemID uuid.UUID, status string, changedBy uuid.UUID) (string, error)
+}
+
+// PostgresKitchenRepository is an implementation of KitchenRepository using *sql.DB
+type PostgresKitchenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresKitchenRepository(db *sql.DB) *PostgresKitchenRepository {
+	return &PostgresKitchenRepository{db: db}
+}
+
+func (r *PostgresKitchenRepository) GetStation(ctx context.Context, stationID uuid.UUID) (*models.KitchenStation, error) {
+	var station models.KitchenStation
+	if err := r.db.QueryRowContext(ctx, `
+        SELECT id, name, description, sort_order, is_active, created_at, updated_at
+        FROM kitchen_stations
+        WHERE id = $1
+    `, stationID).Scan(&station.ID, &station.Name, &station.Description, &station.SortOrder,
+		&station.IsActive, &station.CreatedAt, &station.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("station_not_found: %w", err)
+		}
+		return nil, err
+	}
+	return &station, nil
+}
+
+// ListStationTickets returns the open items routed to a station, grouped per order, oldest first
+func (r *PostgresKitchenRepository) ListStationTickets(ctx context.Context, stationID uuid.UUID) ([]models.StationTicket, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT o.id, o.order_number, o.order_type, o.status, t.table_number, o.customer_name, o.notes, o.created_at,
+               oi.id, oi.product_id, oi.quantity, oi.special_instructions, oi.status, oi.course, oi.fired_at,
+               oi.created_at, oi.updated_at,
+               p.name, p.preparation_time
+        FROM order_items oi
+        JOIN orders o ON oi.order_id = o.id
+        JOIN products p ON oi.product_id = p.id
+        LEFT JOIN dining_tables t ON o.table_id = t.id
+        WHERE oi.station_id = $1
+          AND o.status IN ('pending', 'confirmed', 'preparing')
+          AND `+firedKitchenItem+`
+        ORDER BY o.created_at, oi.course, oi.created_at
+    `, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []models.StationTicket{}
+	ticketIndex := map[uuid.UUID]int{}
+	for rows.Next() {
+		var ticket models.StationTicket
+		var item models.OrderItem
+		var product models.Product
+		if err := rows.Scan(
+			&ticket.OrderID, &ticket.OrderNumber, &ticket.OrderType, &ticket.OrderStatus, &ticket.TableNumber,
+			&ticket.CustomerName, &ticket.Notes, &ticket.CreatedAt,
+			&item.ID, &item.ProductID, &item.Quantity, &item.SpecialInstructions, &item.Status, &item.Course, &item.FiredAt,
+			&item.CreatedAt, &item.UpdatedAt,
+			&product.Name, &product.PreparationTime,
+		); err != nil {
+			return nil, err
+		}
+
+		item.OrderID = ticket.OrderID
+		item.StationID = &stationID
+		product.ID = item.ProductID
+		item.Product = &product
+
+		i, ok := ticketIndex[ticket.OrderID]
+		if !ok {
+			i = len(tickets)
+			ticketIndex[ticket.OrderID] = i
+			tickets = append(tickets, ticket)
+		}
+		tickets[i].Items = append(tickets[i].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	now := time.Now()
+	for i := range tickets {
+		tickets[i].Courses = CourseTimings(tickets[i].Items, now)
+	}
+
+	// attach modifiers so the station sees how each item should be made
+	var items []*models.OrderItem
+	for t := range tickets {
+		for i := range tickets[t].Items {
+			items = append(items, &tickets[t].Items[i])
+		}
+	}
+	if err := LoadItemModifiers(ctx, r.db, items); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// BumpStationTicket marks a station's open items on an order as ready and rolls the
+// order status up from its items. It returns the resulting order status.
+func (r *PostgresKitchenRepository) BumpStationTicket(ctx context.Context, stationID, orderID, changedBy uuid.UUID) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	status, err := lockKitchenOrder(ctx, tx, orderID)
+	if err != nil {
+		return "", err
+	}
+	if status == OrderStatusReady || status == OrderStatusServed {
+		return "", fmt.Errorf("order_not_in_kitchen: order is %s", status)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        UPDATE order_items oi
+        SET status = $3, updated_at = CURRENT_TIMESTAMP`+itemStatusStamps(ItemStatusReady)+`
+        WHERE oi.order_id = $1 AND oi.station_id = $2 AND `+firedKitchenItem+`
+        RETURNING oi.id
+    `, orderID, stationID, ItemStatusReady)
+	if err != nil {
+		return "", err
+	}
+	var bumped []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return "", err
+		}
+		bumped = append(bumped, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(bumped) == 0 {
+		return "", fmt.Errorf("station_ticket_not_found: no open items for this station on the order")
+	}
+
+	changes, err := rollUpOrderStatus(ctx, tx, orderID, status, changedBy)
+	if err != nil {
+		return "", err
+	}
+	if len(changes) > 0 {
+		status = changes[len(changes)-1].To
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	events.Publish(events.ItemStatusChanged, orderID, []string{stationID.String()}, map[string]interface{}{
+		"item_ids":   bumped,
+		"status":     ItemStatusReady,
+		"station_id": stationID,
+	})
+	publishStatusChanges(orderID, changes, changedBy)
+
+	return status, nil
+}
+
+// UpdateItemStatus sets the kitchen status of a single item and rolls the order status
+// up from its items in the same transaction. It returns the resulting order status.
+func (r *PostgresKitchenRepository) UpdateItemStatus(ctx context.Context, orderID, itemID uuid.UUID, status string, changedBy uuid.UUID) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	orderStatus, err := lockKitchenOrder(ctx, tx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	var stationID uuid.NullUUID
+	var adjustment sql.NullString
+	var held bool
+	var course int
+	err = tx.QueryRowContext(ctx, `
+        SELECT station_id, adjustment, is_held, course
+        FROM order_items
+        WHERE id = $1 AND order_id = $2
+        FOR UPDATE
+    `, itemID, orderID).Scan(&stationID, &adjustment, &held, &course)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("order_item_not_found: %w", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if adjustment.Valid && adjustment.String == AdjustmentVoid {
+		return "", fmt.Errorf("order_item_voided: item was voided")
+	}
+	if held && status != ItemStatusPending {
+		return "", fmt.Errorf("order_item_held: course %d has not been fired", course)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE order_items SET status = $1, updated_at = CURRENT_TIMESTAMP`+itemStatusStamps(status)+`
+        WHERE id = $2
+    `, status, itemID); err != nil {
+		return "", err
+	}
+
+	changes, err := rollUpOrderStatus(ctx, tx, orderID, orderStatus, changedBy)
+	if err != nil {
+		return "", err
+	}
+	if len(changes) > 0 {
+		orderStatus = changes[len(changes)-1].To
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	var stations []string
+	if stationID.Valid {
+		stations = []string{stationID.UUID.String()}
+	}
+	events.Publish(events.ItemStatusChanged, orderID, stations, map[string]interface{}{
+		"item_id": itemID,
+		"status":  status,
+	})
+	publishStatusChanges(orderID, changes, changedBy)
+
+	return orderStatus, nil
+}
+
+// itemStatusStamps is the SET clause that records when an item reached a kitchen status,
+// for measuring ticket times. preparing_at keeps the first start, so a remade item counts
+// from when the kitchen first picked it up; ready_at and served_at keep the latest move.
+func itemStatusStamps(status string) string {
+	switch status {
+	case ItemStatusPreparing:
+		return ", preparing_at = COALESCE(preparing_at, CURRENT_TIMESTAMP)"
+	case ItemStatusReady:
+		return ", ready_at = CURRENT_TIMESTAMP"
+	case ItemStatusServed:
+		return ", ready_at = COALESCE(ready_at, CURRENT_TIMESTAMP), served_at = CURRENT_TIMESTAMP"
+	}
+	return ""
+}
+
+// lockKitchenOrder locks an order the kitchen is still working on and returns its status.
+// Served orders are included so an item can be sent back to the kitchen after serving.
+func lockKitchenOrder(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) (string, error) {
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", orderID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("order_not_found: %w", err)
+		}
+		return "", err
+	}
+	switch status {
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPreparing, OrderStatusReady, OrderStatusServed:
+		return status, nil
+	}
+	return "", fmt.Errorf("order_not_in_kitchen: order is %s", status)
+}
+
+// pendingStations lists the stations that still have open items on an order. Items without
+// a station count as "No station", and items whose course is still held are listed apart,
+// so neither can let an order be marked ready unnoticed.
+func pendingStations(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT DISTINCT COALESCE(s.name, 'No station') || CASE WHEN oi.is_held THEN ' (held)' ELSE '' END
+        FROM order_items oi
+        LEFT JOIN kitchen_stations s ON oi.station_id = s.id
+        WHERE oi.order_id = $1 AND `+openKitchenItem+`
+        ORDER BY 1
+    `, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// stationList turns a set of station IDs into a stable slice for event routing
+func stationList(stations map[string]bool) []string {
+	if len(stations) == 0 {
+		return nil
+	}
+	list := make([]string, 0, len(stations))
+	for id := range stations {
+		list = append(list, id)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+
+	"pos-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// taxableLine is an order line as seen by the tax engine
+type taxableLine struct {
+	ProductID uuid.UUID
+	Amount    float64
+}
+
+// orderTaxes is the result of running the tax engine over an order
+type orderTaxes struct {
+	Lines          []models.OrderTax
+	ExclusiveTotal float64 // added on top of the subtotal
+	InclusiveTotal float64 // already contained in the subtotal
+}
+
+// Total returns all tax collected on the order
+func (t orderTaxes) Total() float64 {
+	return roundMoney(t.ExclusiveTotal + t.InclusiveTotal)
+}
+
+// taxRule is a tax rule that applies to a line
+type taxRule struct {
+	ID          uuid.UUID
+	Name        string
+	Rate        float64
+	IsInclusive bool
+}
+
+// calculateOrderTaxes resolves the tax class of every line (product, then category,
+// then the default class) and applies the active rules that match the order type.
+// Inclusive rules are backed out of the line price first; exclusive rules apply
+// to the resulting net amount.
+func calculateOrderTaxes(ctx context.Context, tx *sql.Tx, orderType string, lines []taxableLine) (orderTaxes, error) {
+	var result orderTaxes
+	if len(lines) == 0 {
+		return result, nil
+	}
+
+	productIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID.String())
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT p.id, r.id, r.name, r.rate, r.is_inclusive
+        FROM products p
+        LEFT JOIN categories c ON p.category_id = c.id
+        JOIN tax_rules r ON r.tax_class_id = COALESCE(
+            p.tax_class_id,
+            c.tax_class_id,
+            (SELECT id FROM tax_classes WHERE is_default = true ORDER BY created_at LIMIT 1)
+        )
+        WHERE p.id = ANY($1::uuid[])
+          AND r.is_active = true
+          AND (r.order_type IS NULL OR r.order_type = $2)
+    `, pq.Array(productIDs), orderType)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	rulesByProduct := map[uuid.UUID][]taxRule{}
+	for rows.Next() {
+		var productID uuid.UUID
+		var r taxRule
+		if err := rows.Scan(&productID, &r.ID, &r.Name, &r.Rate, &r.IsInclusive); err != nil {
+			return result, err
+		}
+		rulesByProduct[productID] = append(rulesByProduct[productID], r)
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	return applyTaxRules(lines, rulesByProduct), nil
+}
+
+// applyTaxRules taxes each line with the rules of its product. Amounts are summed per
+// rule before rounding, so the breakdown does not drift by a cent per line.
+func applyTaxRules(lines []taxableLine, rulesByProduct map[uuid.UUID][]taxRule) orderTaxes {
+	var result orderTaxes
+	byRule := map[uuid.UUID]*models.OrderTax{}
+	for _, line := range lines {
+		rules := rulesByProduct[line.ProductID]
+
+		var inclusiveRate float64
+		for _, r := range rules {
+			if r.IsInclusive {
+				inclusiveRate += r.Rate
+			}
+		}
+		net := line.Amount / (1 + inclusiveRate)
+
+		for _, r := range rules {
+			tax, ok := byRule[r.ID]
+			if !ok {
+				ruleID := r.ID
+				tax = &models.OrderTax{
+					TaxRuleID:   &ruleID,
+					TaxName:     r.Name,
+					Rate:        r.Rate,
+					IsInclusive: r.IsInclusive,
+				}
+				byRule[r.ID] = tax
+			}
+			tax.TaxableAmount += net
+			tax.TaxAmount += net * r.Rate
+		}
+	}
+
+	for _, tax := range byRule {
+		tax.TaxableAmount = roundMoney(tax.TaxableAmount)
+		tax.TaxAmount = roundMoney(tax.TaxAmount)
+		if tax.IsInclusive {
+			result.InclusiveTotal += tax.TaxAmount
+		} else {
+			result.ExclusiveTotal += tax.TaxAmount
+		}
+		result.Lines = append(result.Lines, *tax)
+	}
+	sort.Slice(result.Lines, func(i, j int) bool {
+		return result.Lines[i].TaxName < result.Lines[j].TaxName
+	})
+
+	return result
+}
+
+// saveOrderTaxes replaces the stored tax breakdown of an order
+func saveOrderTaxes(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, taxes orderTaxes) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_taxes WHERE order_id = $1", orderID); err != nil {
+		return err
+	}
+
+	for _, tax := range taxes.Lines {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO order_taxes (order_id, tax_rule_id, tax_name, rate, is_inclusive, taxable_amount, tax_amount)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+        `, orderID, tax.TaxRuleID, tax.TaxName, tax.Rate, tax.IsInclusive, tax.TaxableAmount, tax.TaxAmount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresOrderRepository) loadOrderTaxes(ctx context.Context, order *models.Order) error {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, tax_rule_id, tax_name, rate, is_inclusive, taxable_amount, tax_amount
+        FROM order_taxes
+        WHERE order_id = $1
+        ORDER BY tax_name
+    `, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var taxes []models.OrderTax
+	for rows.Next() {
+		var tax models.OrderTax
+		if err := rows.Scan(&tax.ID, &tax.TaxRuleID, &tax.TaxName, &tax.Rate, &tax.IsInclusive,
+			&tax.TaxableAmount, &tax.TaxAmount); err != nil {
+			return err
+		}
+		tax.OrderID = order.ID
+		taxes = append(taxes, tax)
+	}
+
+	order.Taxes = taxes
+	return rows.Err()
+}
+
+// roundMoney rounds an amount to whole cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestApplyTaxRules(t *testing.T) {
+	food, drink, untaxed := uuid.New(), uuid.New(), uuid.New()
+	salesTax := taxRule{ID: uuid.New(), Name: "Sales tax", Rate: 0.10}
+	vat := taxRule{ID: uuid.New(), Name: "VAT", Rate: 0.20, IsInclusive: true}
+	levy := taxRule{ID: uuid.New(), Name: "Levy", Rate: 0.05}
+
+	type wantLine struct {
+		name            string
+		taxable, amount float64
+	}
+	tests := []struct {
+		name                 string
+		lines                []taxableLine
+		rules                map[uuid.UUID][]taxRule
+		exclusive, inclusive float64
+		want                 []wantLine
+	}{
+		{
+			name:      "exclusive",
+			lines:     []taxableLine{{food, 10}, {food, 5.50}},
+			rules:     map[uuid.UUID][]taxRule{food: {salesTax}},
+			exclusive: 1.55,
+			want:      []wantLine{{"Sales tax", 15.50, 1.55}},
+		},
+		{
+			// Three lines of 0.333 tax would round to 0.99 one by one
+			name:      "rounds the rule total rather than each line",
+			lines:     []taxableLine{{food, 3.33}, {food, 3.33}, {food, 3.33}},
+			rules:     map[uuid.UUID][]taxRule{food: {salesTax}},
+			exclusive: 1.00,
+			want:      []wantLine{{"Sales tax", 9.99, 1.00}},
+		},
+		{
+			name:      "inclusive is backed out of the price",
+			lines:     []taxableLine{{drink, 12}},
+			rules:     map[uuid.UUID][]taxRule{drink: {vat}},
+			inclusive: 2.00,
+			want:      []wantLine{{"VAT", 10, 2}},
+		},
+		{
+			name:      "exclusive applies to the net of inclusive",
+			lines:     []taxableLine{{drink, 12}},
+			rules:     map[uuid.UUID][]taxRule{drink: {vat, levy}},
+			exclusive: 0.50,
+			inclusive: 2.00,
+			want:      []wantLine{{"Levy", 10, 0.50}, {"VAT", 10, 2}},
+		},
+		{
+			name:      "lines without rules are not taxed",
+			lines:     []taxableLine{{untaxed, 8}, {food, 2}},
+			rules:     map[uuid.UUID][]taxRule{food: {salesTax}},
+			exclusive: 0.20,
+			want:      []wantLine{{"Sales tax", 2, 0.20}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyTaxRules(tt.lines, tt.rules)
+			if got.ExclusiveTotal != tt.exclusive || got.InclusiveTotal != tt.inclusive {
+				t.Errorf("totals = %v exclusive, %v inclusive, want %v, %v",
+					got.ExclusiveTotal, got.InclusiveTotal, tt.exclusive, tt.inclusive)
+			}
+			if got.Total() != roundMoney(tt.exclusive+tt.inclusive) {
+				t.Errorf("Total() = %v, want %v", got.Total(), tt.exclusive+tt.inclusive)
+			}
+			if len(got.Lines) != len(tt.want) {
+				t.Fatalf("got %d tax lines, want %d", len(got.Lines), len(tt.want))
+			}
+			for i, want := range tt.want {
+				line := got.Lines[i]
+				if line.TaxName != want.name || line.TaxableAmount != want.taxable || line.TaxAmount != want.amount {
+					t.Errorf("line %d = %s %v on %v, want %s %v on %v", i,
+						line.TaxName, line.TaxAmount, line.TaxableAmount, want.name, want.amount, want.taxable)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{1.004, 1},
+		{1.006, 1.01},
+		{-2.349, -2.35},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := roundMoney(tt.in); got != tt.want {
+			t.Errorf("roundMoney(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
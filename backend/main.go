@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"pos-backend/internal/api"
 	"pos-backend/internal/database"
+	"pos-backend/internal/gateway"
 	"pos-backend/internal/middleware"
+	"pos-backend/internal/printing"
+	"pos-backend/internal/repository"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -53,6 +57,26 @@ func main() {
 
 	log.Println("Successfully connected to database")
 
+	// Payment gateway for card payments. Only development falls back to the mock, which
+	// approves every ordinary card; anywhere else PAYMENT_GATEWAY must be set.
+	gatewayName := getEnv("PAYMENT_GATEWAY", "")
+	if gatewayName == "" && getEnv("GIN_MODE", "release") == gin.DebugMode {
+		log.Println("WARNING: PAYMENT_GATEWAY is not set; using the mock gateway for development")
+		gatewayName = "mock"
+	}
+	paymentGateway, err := gateway.New(gatewayName)
+	if err != nil {
+		log.Fatalf("Failed to set up payment gateway: %v", err)
+	}
+	gateway.Default = paymentGateway
+
+	// Start the print spool and queue tickets/receipts as orders change
+	printing.SpoolDir = getEnv("PRINTER_SPOOL_DIR", printing.SpoolDir)
+	printJobRepo := repository.NewPostgresPrintJobRepository(db)
+	printQueue := printing.NewQueue(printJobRepo, repository.NewPostgresOrderRepository(db))
+	go printing.NewSpool(printJobRepo).Run(context.Background())
+	go printing.Listen(context.Background(), printQueue)
+
 	// Initialize Gin router
 	gin.SetMode(getEnv("GIN_MODE", "release"))
 	router := gin.New()
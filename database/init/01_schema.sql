@@ -12,6 +12,63 @@ CREATE TABLE users (
     first_name VARCHAR(50) NOT NULL,
     last_name VARCHAR(50) NOT NULL,
     role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'manager', 'server', 'counter', 'kitchen')),
+    pin_hash VARCHAR(255), -- Optional manager PIN for approvals
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Store settings (a single row of store-wide configuration)
+CREATE TABLE store_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    cash_rounding DECIMAL(6,2) NOT NULL DEFAULT 0.01 CHECK (cash_rounding > 0), -- Smallest coin in circulation; cash balances are settled to a multiple of it
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- IANA zone reports and business days are computed in
+    day_cutoff TIME NOT NULL DEFAULT '00:00', -- Local time the business day starts; earlier sales count toward the previous day
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tax Classes (groups of tax rules assigned to categories or products)
+CREATE TABLE tax_classes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_default BOOLEAN DEFAULT false, -- Used when neither product nor category has a class
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tax Rules (individual rates within a tax class, optionally limited to one order type)
+CREATE TABLE tax_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tax_class_id UUID REFERENCES tax_classes(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    rate DECIMAL(6,4) NOT NULL CHECK (rate >= 0), -- e.g. 0.0825 for 8.25%
+    order_type VARCHAR(20) CHECK (order_type IN ('dine_in', 'takeout', 'delivery')), -- NULL applies to all order types
+    is_inclusive BOOLEAN DEFAULT false, -- Tax already included in the item price
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Service Charges (automatic charges added to orders, e.g. a gratuity for large parties)
+CREATE TABLE service_charge_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    rate DECIMAL(6,4) NOT NULL CHECK (rate >= 0), -- e.g. 0.18 for 18%
+    min_party_size INTEGER NOT NULL DEFAULT 1 CHECK (min_party_size >= 1),
+    order_type VARCHAR(20) CHECK (order_type IN ('dine_in', 'takeout', 'delivery')), -- NULL applies to all order types
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Kitchen Stations (prep areas that receive their own tickets, e.g. grill, bar)
+CREATE TABLE kitchen_stations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT,
+    sort_order INTEGER DEFAULT 0,
     is_active BOOLEAN DEFAULT true,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
@@ -24,6 +81,8 @@ CREATE TABLE categories (
     description TEXT,
     color VARCHAR(7), -- Hex color code
     sort_order INTEGER DEFAULT 0,
+    tax_class_id UUID REFERENCES tax_classes(id) ON DELETE SET NULL,
+    station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL,
     is_active BOOLEAN DEFAULT true,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
@@ -42,10 +101,53 @@ CREATE TABLE products (
     is_available BOOLEAN DEFAULT true,
     preparation_time INTEGER DEFAULT 0, -- in minutes
     sort_order INTEGER DEFAULT 0,
+    tax_class_id UUID REFERENCES tax_classes(id) ON DELETE SET NULL, -- Overrides the category tax class
+    station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL, -- Overrides the category station
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Modifier Groups (e.g. size, extras, cooking temperature)
+CREATE TABLE modifier_groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0), -- 1 or more makes the group required
+    max_select INTEGER CHECK (max_select IS NULL OR max_select >= min_select), -- NULL means no limit
+    sort_order INTEGER DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Modifiers (options within a group, priced as a delta on the product price)
+CREATE TABLE modifiers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    modifier_group_id UUID REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_available BOOLEAN DEFAULT true,
+    sort_order INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Modifier groups offered on a product
+CREATE TABLE product_modifier_groups (
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    modifier_group_id UUID REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    sort_order INTEGER DEFAULT 0,
+    PRIMARY KEY (product_id, modifier_group_id)
+);
+
+-- Modifier groups offered on every product in a category
+CREATE TABLE category_modifier_groups (
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    modifier_group_id UUID REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    sort_order INTEGER DEFAULT 0,
+    PRIMARY KEY (category_id, modifier_group_id)
+);
+
 -- Tables/Dining Areas
 CREATE TABLE dining_tables (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -70,6 +172,10 @@ CREATE TABLE orders (
     tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
     discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
     total_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    guest_count INTEGER CHECK (guest_count > 0), -- NULL falls back to the table's seating capacity
+    service_charge_name VARCHAR(100), -- Snapshot of the service charge rule applied, if any
+    service_charge_rate DECIMAL(6,4),
+    service_charge_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
@@ -87,36 +193,165 @@ CREATE TABLE order_items (
     total_price DECIMAL(10,2) NOT NULL,
     special_instructions TEXT,
     status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'preparing', 'ready', 'served')) DEFAULT 'pending',
+    adjustment VARCHAR(10) CHECK (adjustment IN ('void', 'comp')), -- Set when the item is voided or comped
+    station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL, -- Station the item was routed to when ordered
+    course INTEGER NOT NULL DEFAULT 1 CHECK (course > 0),
+    is_held BOOLEAN NOT NULL DEFAULT false, -- Held items stay off kitchen views until their course is fired
+    fired_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    preparing_at TIMESTAMP WITH TIME ZONE, -- When the kitchen first started the item
+    ready_at TIMESTAMP WITH TIME ZONE, -- When the item was last marked ready
+    served_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Item Modifiers (snapshot of the modifiers chosen for an order item)
+CREATE TABLE order_item_modifiers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE,
+    modifier_id UUID REFERENCES modifiers(id) ON DELETE SET NULL,
+    modifier_group_id UUID REFERENCES modifier_groups(id) ON DELETE SET NULL,
+    group_name VARCHAR(100) NOT NULL,
+    modifier_name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cash drawer sessions (a till shift from opening float to counted close; one open session per drawer)
+CREATE TABLE drawer_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    drawer_name VARCHAR(50) NOT NULL DEFAULT 'Main',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('open', 'closed', 'reviewed')) DEFAULT 'open',
+    opening_float DECIMAL(10,2) NOT NULL CHECK (opening_float >= 0),
+    opened_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    counted_amount DECIMAL(10,2), -- Blind count entered at close
+    expected_amount DECIMAL(10,2), -- Float plus cash movements, fixed at close
+    over_short DECIMAL(10,2), -- counted_amount - expected_amount
+    close_notes TEXT,
+    closed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    review_notes TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Cash put into or taken out of a drawer outside of sales, and no-sale opens
+CREATE TABLE drawer_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES drawer_sessions(id) ON DELETE CASCADE,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('pay_in', 'pay_out', 'no_sale')),
+    amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (amount >= 0), -- Always 0 for no_sale
+    reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Payments table
 CREATE TABLE payments (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
     payment_method VARCHAR(20) NOT NULL CHECK (payment_method IN ('cash', 'credit_card', 'debit_card', 'digital_wallet')),
     amount DECIMAL(10,2) NOT NULL,
+    tip_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (tip_amount >= 0), -- Charged on top of amount; not part of the order total
+    tendered_amount DECIMAL(10,2), -- Cash handed over by the customer
+    change_given DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (change_given >= 0),
+    rounding_amount DECIMAL(10,2) NOT NULL DEFAULT 0, -- Cash rounding of the balance; drawer takes amount + tip + rounding
     reference_number VARCHAR(100), -- For card transactions
     status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'completed', 'failed', 'refunded')) DEFAULT 'pending',
+    drawer_session_id UUID REFERENCES drawer_sessions(id) ON DELETE SET NULL,
     processed_by UUID REFERENCES users(id) ON DELETE SET NULL,
     processed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Refunds (money returned against a completed payment; several partial refunds may share a payment)
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    payment_id UUID REFERENCES payments(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'completed', 'failed')) DEFAULT 'completed', -- Pending while the processor returns the money
+    reference_number VARCHAR(100), -- Processor refund reference for card payments
+    drawer_session_id UUID REFERENCES drawer_sessions(id) ON DELETE SET NULL, -- Drawer the cash was paid out of
+    refunded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Inventory table (optional but useful for stock management)
 CREATE TABLE inventory (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    product_id UUID UNIQUE REFERENCES products(id) ON DELETE CASCADE,
     current_stock INTEGER NOT NULL DEFAULT 0,
     minimum_stock INTEGER DEFAULT 0,
     maximum_stock INTEGER DEFAULT 0,
     unit_cost DECIMAL(10,2),
     last_restocked_at TIMESTAMP WITH TIME ZONE,
+    auto_disabled BOOLEAN DEFAULT false, -- Product was marked unavailable because stock ran out
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Inventory Movements (ledger of every stock change)
+CREATE TABLE inventory_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('initial', 'restock', 'adjustment', 'sale', 'return')),
+    quantity_change INTEGER NOT NULL,
+    stock_after INTEGER NOT NULL,
+    unit_cost DECIMAL(10,2),
+    reason VARCHAR(50),
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ingredients (stock kept in the ingredient's own unit, e.g. g, ml, each)
+CREATE TABLE ingredients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    current_stock DECIMAL(12,3) NOT NULL DEFAULT 0, -- Book stock; may go negative until the next count
+    minimum_stock DECIMAL(12,3) DEFAULT 0,
+    unit_cost DECIMAL(10,4), -- Cost per unit
+    is_active BOOLEAN DEFAULT true,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Recipe Items (bill of materials for a product or a modifier)
+CREATE TABLE recipe_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    modifier_id UUID REFERENCES modifiers(id) ON DELETE CASCADE,
+    ingredient_id UUID REFERENCES ingredients(id) ON DELETE CASCADE,
+    quantity DECIMAL(12,3) NOT NULL CHECK (quantity > 0), -- Per unit sold, in the ingredient's unit
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((product_id IS NULL) <> (modifier_id IS NULL)),
+    UNIQUE (product_id, ingredient_id),
+    UNIQUE (modifier_id, ingredient_id)
+);
+
+-- Ingredient Movements (ledger of every ingredient stock change)
+CREATE TABLE ingredient_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ingredient_id UUID REFERENCES ingredients(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('initial', 'purchase', 'consumption', 'waste', 'count', 'adjustment')),
+    quantity_change DECIMAL(12,3) NOT NULL, -- For counts, the variance between book and counted stock
+    stock_after DECIMAL(12,3) NOT NULL,
+    unit_cost DECIMAL(10,4),
+    reason VARCHAR(50),
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Order Status History (for tracking order status changes)
 CREATE TABLE order_status_history (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -128,16 +363,213 @@ CREATE TABLE order_status_history (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Promotions (admin-defined discounts)
+CREATE TABLE promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    promotion_type VARCHAR(20) NOT NULL CHECK (promotion_type IN ('percent', 'fixed', 'buy_x_get_y')),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('order', 'item', 'category')) DEFAULT 'order',
+    value DECIMAL(10,2) NOT NULL DEFAULT 0, -- Percent (0-100) or fixed amount; for buy_x_get_y the percent off the free items
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE, -- Required for item scope
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE, -- Required for category scope
+    buy_quantity INTEGER, -- buy_x_get_y only
+    get_quantity INTEGER, -- buy_x_get_y only
+    min_spend DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_stackable BOOLEAN DEFAULT false, -- Can be combined with other promotions on the same order
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    days_of_week INTEGER[], -- 0 = Sunday; NULL means every day
+    start_time TIME, -- Daily window such as happy hour; NULL means all day
+    end_time TIME,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Discounts (promotions applied to an order)
+CREATE TABLE order_discounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id UUID REFERENCES promotions(id) ON DELETE SET NULL,
+    promotion_name VARCHAR(100) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_stackable BOOLEAN NOT NULL DEFAULT false, -- copied from the promotion so stacking rules outlive it
+    applied_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Taxes (per-rule tax breakdown stored on each order)
+CREATE TABLE order_taxes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    tax_rule_id UUID REFERENCES tax_rules(id) ON DELETE SET NULL,
+    tax_name VARCHAR(100) NOT NULL,
+    rate DECIMAL(6,4) NOT NULL,
+    is_inclusive BOOLEAN NOT NULL DEFAULT false,
+    taxable_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Item Changes (audit log for items edited after order creation)
+CREATE TABLE order_item_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id UUID, -- no FK: removed items are deleted from order_items
+    product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('added', 'quantity_changed', 'removed')),
+    previous_quantity INTEGER,
+    new_quantity INTEGER,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Item Adjustments (voids and comps with approval trail)
+CREATE TABLE order_item_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE,
+    product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    adjustment_type VARCHAR(10) NOT NULL CHECK (adjustment_type IN ('void', 'comp')),
+    reason_code VARCHAR(30) NOT NULL,
+    quantity INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL, -- Line value removed from the order
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Printers (ESC/POS printers for kitchen tickets and receipts)
+CREATE TABLE printers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    printer_type VARCHAR(20) NOT NULL CHECK (printer_type IN ('kitchen', 'receipt')),
+    station_id UUID REFERENCES kitchen_stations(id) ON DELETE SET NULL, -- Kitchen printers without a station print every station
+    connection_type VARCHAR(20) NOT NULL CHECK (connection_type IN ('network', 'file', 'loopback')) DEFAULT 'network',
+    address VARCHAR(255) NOT NULL DEFAULT '', -- host[:port] for network printers (port 9100 by default), path under the spool directory for file printers
+    chars_per_line INTEGER NOT NULL DEFAULT 42 CHECK (chars_per_line BETWEEN 24 AND 80),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Print Jobs (persistent spool of rendered ESC/POS documents)
+CREATE TABLE print_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    printer_id UUID REFERENCES printers(id) ON DELETE CASCADE,
+    job_type VARCHAR(20) NOT NULL CHECK (job_type IN ('kitchen_ticket', 'receipt', 'test')),
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    payload BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'printing', 'printed', 'failed')) DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    printed_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Z reports (end-of-day close). Figures are frozen when the day is closed, and orders
+-- created before period_end can no longer be changed.
+CREATE TABLE z_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_date DATE UNIQUE NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    order_count INTEGER NOT NULL DEFAULT 0, -- Completed orders
+    cancelled_count INTEGER NOT NULL DEFAULT 0,
+    gross_sales DECIMAL(12,2) NOT NULL DEFAULT 0, -- Completed order totals before refunds
+    discount_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    tax_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    service_charge_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    net_sales DECIMAL(12,2) NOT NULL DEFAULT 0, -- gross_sales - tax_total
+    refund_count INTEGER NOT NULL DEFAULT 0,
+    refund_total DECIMAL(12,2) NOT NULL DEFAULT 0, -- Refunds made during the period, whichever day the order was from
+    tip_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    void_count INTEGER NOT NULL DEFAULT 0,
+    void_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    comp_count INTEGER NOT NULL DEFAULT 0,
+    comp_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+    closed_by UUID, -- No FK: the row is immutable, so deleting the user must not touch it
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Z report takings per payment method
+CREATE TABLE z_report_payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    z_report_id UUID NOT NULL REFERENCES z_reports(id),
+    payment_method VARCHAR(20) NOT NULL,
+    payment_count INTEGER NOT NULL DEFAULT 0,
+    amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    tip_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    refund_amount DECIMAL(12,2) NOT NULL DEFAULT 0
+);
+
+-- Z report tax collected per tax rule snapshot
+CREATE TABLE z_report_taxes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    z_report_id UUID NOT NULL REFERENCES z_reports(id),
+    tax_name VARCHAR(100) NOT NULL,
+    rate DECIMAL(6,4) NOT NULL,
+    is_inclusive BOOLEAN NOT NULL DEFAULT false,
+    taxable_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(12,2) NOT NULL DEFAULT 0
+);
+
+-- Z report discounts per promotion
+CREATE TABLE z_report_discounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    z_report_id UUID NOT NULL REFERENCES z_reports(id),
+    promotion_name VARCHAR(100) NOT NULL,
+    order_count INTEGER NOT NULL DEFAULT 0,
+    amount DECIMAL(12,2) NOT NULL DEFAULT 0
+);
+
 -- Create indexes for better performance
 CREATE INDEX idx_orders_status ON orders(status);
 CREATE INDEX idx_orders_created_at ON orders(created_at);
 CREATE INDEX idx_orders_table_id ON orders(table_id);
 CREATE INDEX idx_order_items_order_id ON order_items(order_id);
 CREATE INDEX idx_order_items_product_id ON order_items(product_id);
+CREATE INDEX idx_order_items_station_id ON order_items(station_id);
+CREATE INDEX idx_order_items_fired_at ON order_items(fired_at);
 CREATE INDEX idx_products_category_id ON products(category_id);
 CREATE INDEX idx_products_is_available ON products(is_available);
 CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE INDEX idx_payments_drawer_session_id ON payments(drawer_session_id);
+CREATE UNIQUE INDEX idx_drawer_sessions_open_drawer ON drawer_sessions(drawer_name) WHERE status = 'open';
+CREATE INDEX idx_drawer_sessions_opened_at ON drawer_sessions(opened_at);
+CREATE INDEX idx_drawer_movements_session_id ON drawer_movements(session_id);
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+CREATE INDEX idx_refunds_created_at ON refunds(created_at);
 CREATE INDEX idx_inventory_product_id ON inventory(product_id);
+CREATE INDEX idx_inventory_movements_product_id ON inventory_movements(product_id);
+CREATE INDEX idx_inventory_movements_created_at ON inventory_movements(created_at);
+CREATE INDEX idx_recipe_items_product_id ON recipe_items(product_id);
+CREATE INDEX idx_recipe_items_modifier_id ON recipe_items(modifier_id);
+CREATE INDEX idx_ingredient_movements_ingredient_id ON ingredient_movements(ingredient_id);
+CREATE INDEX idx_ingredient_movements_created_at ON ingredient_movements(created_at);
+CREATE INDEX idx_modifiers_modifier_group_id ON modifiers(modifier_group_id);
+CREATE INDEX idx_order_item_modifiers_order_item_id ON order_item_modifiers(order_item_id);
+CREATE INDEX idx_tax_rules_tax_class_id ON tax_rules(tax_class_id);
+CREATE INDEX idx_order_taxes_order_id ON order_taxes(order_id);
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
+CREATE INDEX idx_order_discounts_promotion_id ON order_discounts(promotion_id);
+CREATE INDEX idx_order_item_changes_order_id ON order_item_changes(order_id);
+CREATE INDEX idx_order_item_adjustments_order_id ON order_item_adjustments(order_id);
+CREATE INDEX idx_order_item_adjustments_created_at ON order_item_adjustments(created_at);
+CREATE INDEX idx_print_jobs_status_next_attempt ON print_jobs(status, next_attempt_at);
+CREATE INDEX idx_print_jobs_order_id ON print_jobs(order_id);
+CREATE INDEX idx_z_reports_period_end ON z_reports(period_end);
+CREATE INDEX idx_z_report_payments_z_report_id ON z_report_payments(z_report_id);
+CREATE INDEX idx_z_report_taxes_z_report_id ON z_report_taxes(z_report_id);
+CREATE INDEX idx_z_report_discounts_z_report_id ON z_report_discounts(z_report_id);
 
 -- Create triggers for updated_at timestamps
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -148,11 +580,40 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
+CREATE TRIGGER update_store_settings_updated_at BEFORE UPDATE ON store_settings FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_tax_classes_updated_at BEFORE UPDATE ON tax_classes FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_tax_rules_updated_at BEFORE UPDATE ON tax_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_service_charge_rules_updated_at BEFORE UPDATE ON service_charge_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_modifier_groups_updated_at BEFORE UPDATE ON modifier_groups FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_modifiers_updated_at BEFORE UPDATE ON modifiers FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_promotions_updated_at BEFORE UPDATE ON promotions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_kitchen_stations_updated_at BEFORE UPDATE ON kitchen_stations FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_printers_updated_at BEFORE UPDATE ON printers FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_print_jobs_updated_at BEFORE UPDATE ON print_jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_categories_updated_at BEFORE UPDATE ON categories FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_products_updated_at BEFORE UPDATE ON products FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_dining_tables_updated_at BEFORE UPDATE ON dining_tables FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_drawer_sessions_updated_at BEFORE UPDATE ON drawer_sessions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_orders_updated_at BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_order_items_updated_at BEFORE UPDATE ON order_items FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_inventory_updated_at BEFORE UPDATE ON inventory FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_ingredients_updated_at BEFORE UPDATE ON ingredients FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+
+-- Closed business days are immutable
+CREATE OR REPLACE FUNCTION prevent_z_report_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'z_report_immutable: closed business days cannot be changed';
+END;
+$$ language 'plpgsql';
 
+CREATE TRIGGER z_reports_immutable BEFORE UPDATE OR DELETE ON z_reports FOR EACH ROW EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_payments_immutable BEFORE UPDATE OR DELETE ON z_report_payments FOR EACH ROW EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_taxes_immutable BEFORE UPDATE OR DELETE ON z_report_taxes FOR EACH ROW EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_discounts_immutable BEFORE UPDATE OR DELETE ON z_report_discounts FOR EACH ROW EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_reports_no_truncate BEFORE TRUNCATE ON z_reports FOR EACH STATEMENT EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_payments_no_truncate BEFORE TRUNCATE ON z_report_payments FOR EACH STATEMENT EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_taxes_no_truncate BEFORE TRUNCATE ON z_report_taxes FOR EACH STATEMENT EXECUTE FUNCTION prevent_z_report_change();
+CREATE TRIGGER z_report_discounts_no_truncate BEFORE TRUNCATE ON z_report_discounts FOR EACH STATEMENT EXECUTE FUNCTION prevent_z_report_change();
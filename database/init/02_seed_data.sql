@@ -10,14 +10,50 @@ INSERT INTO users (username, email, password_hash, first_name, last_name, role)
 ('counter2', 'counter2@pos.com', '$2a$10$FPH.ONfAgquWmXjM3LE61OIgOPgXX8i.jOISCHZ2DpK2gg4krEWfO', 'Tom', 'Wilson', 'counter'),
 ('kitchen1', 'kitchen@pos.com', '$2a$10$FPH.ONfAgquWmXjM3LE61OIgOPgXX8i.jOISCHZ2DpK2gg4krEWfO', 'Chef', 'Williams', 'kitchen');
 
+-- Insert store settings
+INSERT INTO store_settings (cash_rounding) VALUES (0.01);
+
+-- Insert tax classes and rules (Standard keeps the previous flat 10% rate)
+INSERT INTO tax_classes (name, description, is_default) VALUES
+('Standard', 'Default sales tax', true),
+('Prepared Food', 'Food prepared on site; reduced rate for takeout and delivery', false),
+('Alcohol', 'Alcoholic beverages', false);
+
+INSERT INTO tax_rules (tax_class_id, name, rate, order_type, is_inclusive) VALUES
+((SELECT id FROM tax_classes WHERE name = 'Standard'), 'Sales Tax', 0.1000, NULL, false),
+((SELECT id FROM tax_classes WHERE name = 'Prepared Food'), 'Sales Tax (Dine-in)', 0.1000, 'dine_in', false),
+((SELECT id FROM tax_classes WHERE name = 'Prepared Food'), 'Sales Tax (Takeout)', 0.0500, 'takeout', false),
+((SELECT id FROM tax_classes WHERE name = 'Prepared Food'), 'Sales Tax (Delivery)', 0.0500, 'delivery', false),
+((SELECT id FROM tax_classes WHERE name = 'Alcohol'), 'Sales Tax', 0.1000, NULL, false),
+((SELECT id FROM tax_classes WHERE name = 'Alcohol'), 'Alcohol Excise', 0.0500, NULL, false);
+
+-- Insert service charges
+INSERT INTO service_charge_rules (name, rate, min_party_size, order_type) VALUES
+('Large Party Gratuity', 0.1800, 8, 'dine_in');
+
+-- Insert kitchen stations
+INSERT INTO kitchen_stations (name, description, sort_order) VALUES
+('Grill', 'Hot line: grill, fryer and pizza oven', 1),
+('Cold', 'Salads and cold starters', 2),
+('Bar', 'Soft drinks, juices and coffee', 3),
+('Dessert', 'Desserts and shakes', 4);
+
+-- Insert printers (loopback printers accept jobs without hardware; point them at real devices on site)
+INSERT INTO printers (name, printer_type, station_id, connection_type, address) VALUES
+('Grill Printer', 'kitchen', (SELECT id FROM kitchen_stations WHERE name = 'Grill'), 'loopback', ''),
+('Cold Printer', 'kitchen', (SELECT id FROM kitchen_stations WHERE name = 'Cold'), 'loopback', ''),
+('Bar Printer', 'kitchen', (SELECT id FROM kitchen_stations WHERE name = 'Bar'), 'loopback', ''),
+('Dessert Printer', 'kitchen', (SELECT id FROM kitchen_stations WHERE name = 'Dessert'), 'loopback', ''),
+('Counter Receipt Printer', 'receipt', NULL, 'loopback', '');
+
 -- Insert categories
-INSERT INTO categories (name, description, color, sort_order) VALUES
-('Appetizers', 'Starter dishes and small plates', '#FF6B6B', 1),
-('Main Courses', 'Primary dishes and entrees', '#4ECDC4', 2),
-('Beverages', 'Drinks, sodas, and refreshments', '#45B7D1', 3),
-('Desserts', 'Sweet treats and desserts', '#96CEB4', 4),
-('Salads', 'Fresh salads and healthy options', '#FECA57', 5),
-('Pizza', 'Various pizza options', '#FF9FF3', 6);
+INSERT INTO categories (name, description, color, sort_order, station_id) VALUES
+('Appetizers', 'Starter dishes and small plates', '#FF6B6B', 1, (SELECT id FROM kitchen_stations WHERE name = 'Grill')),
+('Main Courses', 'Primary dishes and entrees', '#4ECDC4', 2, (SELECT id FROM kitchen_stations WHERE name = 'Grill')),
+('Beverages', 'Drinks, sodas, and refreshments', '#45B7D1', 3, (SELECT id FROM kitchen_stations WHERE name = 'Bar')),
+('Desserts', 'Sweet treats and desserts', '#96CEB4', 4, (SELECT id FROM kitchen_stations WHERE name = 'Dessert')),
+('Salads', 'Fresh salads and healthy options', '#FECA57', 5, (SELECT id FROM kitchen_stations WHERE name = 'Cold')),
+('Pizza', 'Various pizza options', '#FF9FF3', 6, (SELECT id FROM kitchen_stations WHERE name = 'Grill'));
 
 -- Insert products
 INSERT INTO products (category_id, name, description, price, sku, preparation_time, sort_order) VALUES
@@ -58,6 +94,49 @@ INSERT INTO products (category_id, name, description, price, sku, preparation_ti
 ((SELECT id FROM categories WHERE name = 'Pizza'), 'Supreme Pizza', 'Pizza loaded with multiple toppings', 19.99, 'PIZ003', 20, 3),
 ((SELECT id FROM categories WHERE name = 'Pizza'), 'Hawaiian Pizza', 'Pizza with ham and pineapple', 17.99, 'PIZ004', 16, 4);
 
+-- Shakes are made at the dessert station rather than the bar
+UPDATE products SET station_id = (SELECT id FROM kitchen_stations WHERE name = 'Dessert') WHERE sku = 'BEV005';
+
+-- Insert modifier groups and modifiers
+INSERT INTO modifier_groups (name, description, min_select, max_select, sort_order) VALUES
+('Steak Temperature', 'How the steak is cooked', 1, 1, 1),
+('Steak Extras', 'Add-ons for steak', 0, 3, 2),
+('Pizza Size', 'Pizza size', 1, 1, 1),
+('Extra Toppings', 'Additional pizza toppings', 0, NULL, 2),
+('Drink Size', 'Beverage size', 0, 1, 1);
+
+INSERT INTO modifiers (modifier_group_id, name, price_delta, sort_order) VALUES
+((SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 'Rare', 0.00, 1),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 'Medium-Rare', 0.00, 2),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 'Medium', 0.00, 3),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 'Medium-Well', 0.00, 4),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 'Well Done', 0.00, 5),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Extras'), 'Add Mushrooms', 2.00, 1),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Extras'), 'Peppercorn Sauce', 1.50, 2),
+((SELECT id FROM modifier_groups WHERE name = 'Steak Extras'), 'Garlic Butter', 1.00, 3),
+((SELECT id FROM modifier_groups WHERE name = 'Pizza Size'), 'Medium (12")', 0.00, 1),
+((SELECT id FROM modifier_groups WHERE name = 'Pizza Size'), 'Large (16")', 4.00, 2),
+((SELECT id FROM modifier_groups WHERE name = 'Extra Toppings'), 'Extra Cheese', 1.50, 1),
+((SELECT id FROM modifier_groups WHERE name = 'Extra Toppings'), 'Mushrooms', 1.00, 2),
+((SELECT id FROM modifier_groups WHERE name = 'Extra Toppings'), 'Olives', 1.00, 3),
+((SELECT id FROM modifier_groups WHERE name = 'Drink Size'), 'Regular', 0.00, 1),
+((SELECT id FROM modifier_groups WHERE name = 'Drink Size'), 'Large', 0.75, 2);
+
+INSERT INTO product_modifier_groups (product_id, modifier_group_id, sort_order) VALUES
+((SELECT id FROM products WHERE sku = 'MAIN002'), (SELECT id FROM modifier_groups WHERE name = 'Steak Temperature'), 1),
+((SELECT id FROM products WHERE sku = 'MAIN002'), (SELECT id FROM modifier_groups WHERE name = 'Steak Extras'), 2);
+
+INSERT INTO category_modifier_groups (category_id, modifier_group_id, sort_order) VALUES
+((SELECT id FROM categories WHERE name = 'Pizza'), (SELECT id FROM modifier_groups WHERE name = 'Pizza Size'), 1),
+((SELECT id FROM categories WHERE name = 'Pizza'), (SELECT id FROM modifier_groups WHERE name = 'Extra Toppings'), 2),
+((SELECT id FROM categories WHERE name = 'Beverages'), (SELECT id FROM modifier_groups WHERE name = 'Drink Size'), 1);
+
+-- Insert sample promotions
+INSERT INTO promotions (name, description, promotion_type, scope, value, category_id, buy_quantity, get_quantity, min_spend, is_stackable, days_of_week, start_time, end_time) VALUES
+('Happy Hour Drinks', '50% off beverages on weekdays 4-6pm', 'percent', 'category', 50, (SELECT id FROM categories WHERE name = 'Beverages'), NULL, NULL, 0, true, '{1,2,3,4,5}', '16:00', '18:00'),
+('10% Off Orders Over 50', 'Order-wide discount with minimum spend', 'percent', 'order', 10, NULL, NULL, NULL, 50, false, NULL, NULL, NULL),
+('Pizza Buy 2 Get 1', 'Buy two pizzas, get the cheapest third free', 'buy_x_get_y', 'category', 100, (SELECT id FROM categories WHERE name = 'Pizza'), 2, 1, 0, true, NULL, NULL, NULL);
+
 -- Insert dining tables
 INSERT INTO dining_tables (table_number, seating_capacity, location) VALUES
 ('T01', 2, 'Main Floor'),
@@ -85,6 +164,46 @@ SELECT
     price * 0.4 as unit_cost
 FROM products;
 
+INSERT INTO inventory_movements (product_id, movement_type, quantity_change, stock_after, unit_cost, notes)
+SELECT product_id, 'initial', current_stock, current_stock, unit_cost, 'Opening stock'
+FROM inventory;
+
+-- Insert ingredients
+INSERT INTO ingredients (name, unit, current_stock, minimum_stock, unit_cost) VALUES
+('Beef Steak Cut', 'g', 20000, 5000, 0.0280),
+('Chicken Breast', 'g', 15000, 4000, 0.0110),
+('Pizza Dough', 'each', 60, 15, 0.4500),
+('Tomato Sauce', 'ml', 8000, 2000, 0.0040),
+('Mozzarella', 'g', 10000, 2500, 0.0120),
+('Pepperoni', 'g', 4000, 1000, 0.0180),
+('Mushrooms', 'g', 3000, 800, 0.0090),
+('Butter', 'g', 2000, 500, 0.0100),
+('Mixed Vegetables', 'g', 8000, 2000, 0.0050);
+
+INSERT INTO ingredient_movements (ingredient_id, movement_type, quantity_change, stock_after, unit_cost, notes)
+SELECT id, 'initial', current_stock, current_stock, unit_cost, 'Opening stock'
+FROM ingredients;
+
+-- Insert recipes
+INSERT INTO recipe_items (product_id, ingredient_id, quantity) VALUES
+((SELECT id FROM products WHERE sku = 'MAIN001'), (SELECT id FROM ingredients WHERE name = 'Chicken Breast'), 220),
+((SELECT id FROM products WHERE sku = 'MAIN001'), (SELECT id FROM ingredients WHERE name = 'Mixed Vegetables'), 150),
+((SELECT id FROM products WHERE sku = 'MAIN002'), (SELECT id FROM ingredients WHERE name = 'Beef Steak Cut'), 300),
+((SELECT id FROM products WHERE sku = 'MAIN002'), (SELECT id FROM ingredients WHERE name = 'Mixed Vegetables'), 100),
+((SELECT id FROM products WHERE sku = 'PIZ001'), (SELECT id FROM ingredients WHERE name = 'Pizza Dough'), 1),
+((SELECT id FROM products WHERE sku = 'PIZ001'), (SELECT id FROM ingredients WHERE name = 'Tomato Sauce'), 90),
+((SELECT id FROM products WHERE sku = 'PIZ001'), (SELECT id FROM ingredients WHERE name = 'Mozzarella'), 120),
+((SELECT id FROM products WHERE sku = 'PIZ002'), (SELECT id FROM ingredients WHERE name = 'Pizza Dough'), 1),
+((SELECT id FROM products WHERE sku = 'PIZ002'), (SELECT id FROM ingredients WHERE name = 'Tomato Sauce'), 90),
+((SELECT id FROM products WHERE sku = 'PIZ002'), (SELECT id FROM ingredients WHERE name = 'Mozzarella'), 100),
+((SELECT id FROM products WHERE sku = 'PIZ002'), (SELECT id FROM ingredients WHERE name = 'Pepperoni'), 60);
+
+INSERT INTO recipe_items (modifier_id, ingredient_id, quantity) VALUES
+((SELECT id FROM modifiers WHERE name = 'Add Mushrooms'), (SELECT id FROM ingredients WHERE name = 'Mushrooms'), 60),
+((SELECT id FROM modifiers WHERE name = 'Garlic Butter'), (SELECT id FROM ingredients WHERE name = 'Butter'), 20),
+((SELECT id FROM modifiers WHERE name = 'Extra Cheese'), (SELECT id FROM ingredients WHERE name = 'Mozzarella'), 50),
+((SELECT id FROM modifiers WHERE name = 'Mushrooms' AND modifier_group_id = (SELECT id FROM modifier_groups WHERE name = 'Extra Toppings')), (SELECT id FROM ingredients WHERE name = 'Mushrooms'), 40);
+
 -- Create some sample orders for testing
 INSERT INTO orders (order_number, table_id, user_id, order_type, status, subtotal, tax_amount, total_amount) VALUES
 ('ORD001', (SELECT id FROM dining_tables WHERE table_number = 'T02'), (SELECT id FROM users WHERE username = 'server1'), 'dine_in', 'pending', 25.98, 2.60, 28.58),
@@ -104,6 +223,13 @@ INSERT INTO order_items (order_id, product_id, quantity, unit_price, total_price
 -- Order 3 items
 ((SELECT id FROM orders WHERE order_number = 'ORD003'), (SELECT id FROM products WHERE sku = 'PIZ001'), 1, 14.99, 14.99);
 
+-- Route sample order items to their stations
+UPDATE order_items oi
+SET station_id = COALESCE(p.station_id, c.station_id)
+FROM products p
+LEFT JOIN categories c ON p.category_id = c.id
+WHERE oi.product_id = p.id;
+
 -- Insert sample payment
 INSERT INTO payments (order_id, payment_method, amount, status, processed_by, processed_at) VALUES
 ((SELECT id FROM orders WHERE order_number = 'ORD003'), 'cash', 16.49, 'completed', (SELECT id FROM users WHERE username = 'counter1'), CURRENT_TIMESTAMP);
@@ -111,3 +237,38 @@ INSERT INTO payments (order_id, payment_method, amount, status, processed_by, pr
 -- Update order 3 status to completed since payment is done
 UPDATE orders SET status = 'completed', completed_at = CURRENT_TIMESTAMP WHERE order_number = 'ORD003';
 
+
+-- Record the tax breakdown for the sample orders
+INSERT INTO order_taxes (order_id, tax_rule_id, tax_name, rate, is_inclusive, taxable_amount, tax_amount)
+SELECT o.id, r.id, r.name, r.rate, r.is_inclusive, o.subtotal, o.tax_amount
+FROM orders o
+CROSS JOIN tax_rules r
+JOIN tax_classes tc ON r.tax_class_id = tc.id
+WHERE tc.name = 'Standard' AND o.order_number IN ('ORD001', 'ORD002', 'ORD003');
+
+-- Take the sample order items out of stock
+INSERT INTO inventory_movements (product_id, order_id, movement_type, quantity_change, stock_after, created_by)
+SELECT oi.product_id, o.id, 'sale', -oi.quantity, i.current_stock - SUM(oi.quantity) OVER (PARTITION BY oi.product_id ORDER BY o.order_number, oi.id), o.user_id
+FROM order_items oi
+JOIN orders o ON oi.order_id = o.id
+JOIN inventory i ON i.product_id = oi.product_id;
+
+UPDATE inventory i
+SET current_stock = i.current_stock - sold.quantity
+FROM (SELECT product_id, SUM(quantity) AS quantity FROM order_items GROUP BY product_id) sold
+WHERE i.product_id = sold.product_id;
+
+-- Consume ingredients for the completed sample orders
+INSERT INTO ingredient_movements (ingredient_id, order_id, movement_type, quantity_change, stock_after, unit_cost, created_by)
+SELECT r.ingredient_id, o.id, 'consumption', -SUM(r.quantity * oi.quantity), g.current_stock - SUM(r.quantity * oi.quantity), g.unit_cost, o.user_id
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+JOIN recipe_items r ON r.product_id = oi.product_id
+JOIN ingredients g ON r.ingredient_id = g.id
+WHERE o.status = 'completed'
+GROUP BY r.ingredient_id, o.id, g.current_stock, g.unit_cost, o.user_id;
+
+UPDATE ingredients g
+SET current_stock = g.current_stock + used.quantity_change
+FROM (SELECT ingredient_id, SUM(quantity_change) AS quantity_change FROM ingredient_movements WHERE movement_type = 'consumption' GROUP BY ingredient_id) used
+WHERE g.id = used.ingredient_id;